@@ -0,0 +1,82 @@
+package logConfig
+
+import (
+	"context"
+	_ "embed"
+	"strings"
+
+	beta "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/config"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/output"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/sdk"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/va"
+	"github.com/spf13/cobra"
+)
+
+//go:embed diff.md
+var diffHelp string
+
+func newDiffCommand() *cobra.Command {
+	help := util.ParseHelp(diffHelp)
+	var profile string
+	cmd := &cobra.Command{
+		Use:     "diff",
+		Short:   "Compare a VA cluster's log configuration with a saved profile",
+		Long:    help.Long,
+		Example: help.Example,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			wanted := beta.ClientLogConfiguration{RootLevel: va.DefaultRootLogLevel}
+			label := "Default"
+			if profile != "" {
+				var err error
+				wanted, err = va.ProfileLogConfiguration(profile)
+				if err != nil {
+					return err
+				}
+				label = "Profile " + strings.ToLower(profile)
+			}
+
+			apiClient, err := config.InitAPIClient(false)
+			if err != nil {
+				return err
+			}
+
+			for _, clusterId := range args {
+
+				current, resp, err := apiClient.Beta.ManagedClustersAPI.GetClientLogConfiguration(context.TODO(), clusterId).Execute()
+				if err != nil {
+					return sdk.HandleSDKError(resp, err)
+				}
+
+				cmd.Printf("Cluster %s", clusterId)
+				if expiration, ok := current.GetExpirationOk(); ok {
+					cmd.Printf(", current configuration expires %s", expiration.Local().Format("2006-01-02 15:04:05"))
+				}
+				cmd.Println()
+
+				diffs := diffLogLevels(current, wanted)
+				if len(diffs) == 0 {
+					cmd.Println("No differences")
+					cmd.Println()
+					continue
+				}
+
+				var entries [][]string
+				for _, diff := range diffs {
+					entries = append(entries, []string{diff.Logger, diff.Current, diff.Wanted})
+				}
+				output.WriteTable(cmd.OutOrStdout(), []string{"Logger", "Current", label}, entries, "")
+				cmd.Println()
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&profile, "profile", "p", "", "Name of the saved log profile to compare with (default the default VA log configuration)")
+
+	return cmd
+}
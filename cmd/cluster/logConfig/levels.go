@@ -0,0 +1,89 @@
+package logConfig
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	beta "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/va"
+)
+
+// formatLogLevels returns log levels as sorted class=LEVEL entries
+func formatLogLevels(logLevels map[string]beta.StandardLevel) []string {
+	entries := make([]string, 0, len(logLevels))
+	for class, level := range logLevels {
+		entries = append(entries, class+"="+string(level))
+	}
+	sort.Strings(entries)
+	return entries
+}
+
+func validateDuration(durationInMinutes int32) error {
+	if durationInMinutes < va.MinLogDurationMinutes || durationInMinutes > va.MaxLogDurationMinutes {
+		return fmt.Errorf("invalid durationInMinutes: %d, must be between %d and %d", durationInMinutes, va.MinLogDurationMinutes, va.MaxLogDurationMinutes)
+	}
+	return nil
+}
+
+// durationUntil returns the duration in whole minutes from now until an
+// RFC3339 expiration, along with the parsed expiration
+func durationUntil(expiration string, now time.Time) (int32, time.Time, error) {
+	expiresAt, err := time.Parse(time.RFC3339, expiration)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("invalid expiration %q, use RFC3339, for example 2020-12-15T19:13:36.079Z", expiration)
+	}
+
+	minutes := math.Ceil(expiresAt.Sub(now).Minutes())
+	if minutes < va.MinLogDurationMinutes || minutes > va.MaxLogDurationMinutes {
+		return 0, time.Time{}, fmt.Errorf("expiration %s must be between %d minutes and %d hours from now", expiration, va.MinLogDurationMinutes, va.MaxLogDurationMinutes/60)
+	}
+
+	return int32(minutes), expiresAt, nil
+}
+
+type logLevelDiff struct {
+	Logger  string
+	Current string
+	Wanted  string
+}
+
+// diffLogLevels compares the current log configuration of a cluster with a
+// wanted one, returning only the root level and loggers that differ
+func diffLogLevels(current *beta.ClientLogConfiguration, wanted beta.ClientLogConfiguration) []logLevelDiff {
+	currentRoot := va.DefaultRootLogLevel
+	currentLevels := map[string]beta.StandardLevel{}
+	if current != nil && current.RootLevel != "" {
+		currentRoot = current.RootLevel
+		currentLevels = current.GetLogLevels()
+	}
+	wantedLevels := wanted.GetLogLevels()
+
+	var diffs []logLevelDiff
+	if currentRoot != wanted.RootLevel {
+		diffs = append(diffs, logLevelDiff{"root", string(currentRoot), string(wanted.RootLevel)})
+	}
+
+	classes := map[string]bool{}
+	for class := range currentLevels {
+		classes[class] = true
+	}
+	for class := range wantedLevels {
+		classes[class] = true
+	}
+
+	var sorted []string
+	for class := range classes {
+		sorted = append(sorted, class)
+	}
+	sort.Strings(sorted)
+
+	for _, class := range sorted {
+		if currentLevels[class] != wantedLevels[class] {
+			diffs = append(diffs, logLevelDiff{class, string(currentLevels[class]), string(wantedLevels[class])})
+		}
+	}
+
+	return diffs
+}
@@ -0,0 +1,49 @@
+package logConfig
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	beta "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/va"
+)
+
+func TestDurationUntil(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	minutes, _, err := durationUntil("2024-01-01T12:30:30Z", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if minutes != 31 {
+		t.Errorf("expected 31 minutes, got %d", minutes)
+	}
+
+	for _, expiration := range []string{"2024-01-01T12:01:00Z", "2024-01-03T12:00:00Z", "tomorrow"} {
+		if _, _, err := durationUntil(expiration, now); err == nil {
+			t.Errorf("expected an error for expiration %s", expiration)
+		}
+	}
+}
+
+func TestDiffLogLevels(t *testing.T) {
+	currentLevels := map[string]beta.StandardLevel{"a.Connector": beta.STANDARDLEVEL_DEBUG, "b.Connector": beta.STANDARDLEVEL_INFO}
+	current := &beta.ClientLogConfiguration{RootLevel: beta.STANDARDLEVEL_INFO, LogLevels: &currentLevels}
+
+	wantedLevels := map[string]beta.StandardLevel{"a.Connector": beta.STANDARDLEVEL_TRACE, "b.Connector": beta.STANDARDLEVEL_INFO, "c.Connector": beta.STANDARDLEVEL_DEBUG}
+	wanted := beta.ClientLogConfiguration{RootLevel: beta.STANDARDLEVEL_WARN, LogLevels: &wantedLevels}
+
+	want := []logLevelDiff{
+		{"root", "INFO", "WARN"},
+		{"a.Connector", "DEBUG", "TRACE"},
+		{"c.Connector", "", "DEBUG"},
+	}
+	if got := diffLogLevels(current, wanted); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if got := diffLogLevels(nil, beta.ClientLogConfiguration{RootLevel: va.DefaultRootLogLevel}); len(got) != 0 {
+		t.Errorf("expected no differences from the defaults, got %v", got)
+	}
+}
@@ -27,6 +27,9 @@ func NewLogCommand() *cobra.Command {
 	cmd.AddCommand(
 		newGetCommand(),
 		newSetCommand(),
+		newDiffCommand(),
+		newResetCommand(),
+		newProfileCommand(),
 	)
 
 	return cmd
@@ -0,0 +1,156 @@
+package logConfig
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+
+	beta "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/config"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/output"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/va"
+	"github.com/spf13/cobra"
+)
+
+//go:embed profile.md
+var profileHelp string
+
+func newProfileCommand() *cobra.Command {
+	help := util.ParseHelp(profileHelp)
+	cmd := &cobra.Command{
+		Use:     "profile",
+		Short:   "Manage saved VA log profiles",
+		Long:    help.Long,
+		Example: help.Example,
+		Aliases: []string{"p"},
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		newProfileListCommand(),
+		newProfileSaveCommand(),
+		newProfileDeleteCommand(),
+	)
+
+	return cmd
+}
+
+func newProfileListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the saved VA log profiles",
+		Example: "sail cluster log profile list",
+		Aliases: []string{"ls"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profiles, err := config.GetClusterLogProfiles()
+			if err != nil {
+				return err
+			}
+
+			var entries [][]string
+			for name, profile := range profiles {
+				duration := ""
+				if profile.DurationMinutes > 0 {
+					duration = fmt.Sprintf("%d", profile.DurationMinutes)
+				}
+				entries = append(entries, []string{name, profile.RootLevel, duration, strings.Join(profile.Loggers, "\n"), profile.Description})
+			}
+
+			output.WriteTable(cmd.OutOrStdout(), []string{"Name", "Root Level", "Duration Minutes", "Loggers", "Description"}, entries, "Name")
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newProfileSaveCommand() *cobra.Command {
+	var level string
+	var durationInMinutes int32
+	var connectors []string
+	var description string
+	cmd := &cobra.Command{
+		Use:     "save [name]",
+		Short:   "Save a VA log profile, replacing any profile with the same name",
+		Example: "sail cluster log profile save ad-debug -r INFO -d 60 -c sailpoint.connector.ADLDAPConnector=TRACE",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootLevel := beta.StandardLevel(strings.ToUpper(level))
+			if !rootLevel.IsValid() {
+				return errors.New("invalid logLevel: " + level)
+			}
+
+			if cmd.Flags().Changed("durationInMinutes") {
+				if err := validateDuration(durationInMinutes); err != nil {
+					return err
+				}
+			}
+
+			logLevels, err := va.ParseLogLevels(connectors)
+			if err != nil {
+				return err
+			}
+
+			profiles, err := config.GetClusterLogProfiles()
+			if err != nil {
+				return err
+			}
+
+			name := strings.ToLower(args[0])
+			profiles[name] = config.ClusterLogProfile{
+				Description:     description,
+				RootLevel:       string(rootLevel),
+				DurationMinutes: durationInMinutes,
+				Loggers:         formatLogLevels(logLevels),
+			}
+			config.SetClusterLogProfiles(profiles)
+
+			cmd.Printf("Saved log profile %s\n", name)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&level, "rootLogLevel", "r", string(va.DefaultRootLogLevel), "Root log level of the profile")
+	cmd.Flags().Int32VarP(&durationInMinutes, "durationInMinutes", "d", 0, "Duration in minutes of the profile, between 5 and 1440 (default the duration given to sail cluster log set)")
+	cmd.Flags().StringArrayVarP(&connectors, "connector", "c", []string{}, "Logger class and level of the profile, can be repeated. Example:\n-c sailpoint.connector.ADLDAPConnector=TRACE")
+	cmd.Flags().StringVar(&description, "description", "", "Description of the profile")
+
+	return cmd
+}
+
+func newProfileDeleteCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "delete [name...]",
+		Short:   "Delete saved VA log profiles",
+		Example: "sail cluster log profile delete ad-debug",
+		Aliases: []string{"d"},
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profiles, err := config.GetClusterLogProfiles()
+			if err != nil {
+				return err
+			}
+
+			for _, arg := range args {
+				name := strings.ToLower(arg)
+				if _, ok := profiles[name]; !ok {
+					return fmt.Errorf("log profile %q not found", arg)
+				}
+				delete(profiles, name)
+				cmd.Printf("Deleted log profile %s\n", name)
+			}
+			config.SetClusterLogProfiles(profiles)
+
+			return nil
+		},
+	}
+
+	return cmd
+}
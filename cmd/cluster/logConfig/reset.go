@@ -0,0 +1,53 @@
+package logConfig
+
+import (
+	"context"
+	_ "embed"
+
+	beta "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/config"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/sdk"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/va"
+	"github.com/spf13/cobra"
+)
+
+//go:embed reset.md
+var resetHelp string
+
+func newResetCommand() *cobra.Command {
+	help := util.ParseHelp(resetHelp)
+	cmd := &cobra.Command{
+		Use:     "reset",
+		Short:   "Reset a VA cluster's log configuration to the default levels",
+		Long:    help.Long,
+		Example: help.Example,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			apiClient, err := config.InitAPIClient(false)
+			if err != nil {
+				return err
+			}
+
+			// The API has no delete, a short lived configuration with the
+			// default levels expires back to the cluster defaults
+			logLevels := make(map[string]beta.StandardLevel)
+			configuration := beta.ClientLogConfiguration{DurationMinutes: va.MinLogDurationMinutes, RootLevel: va.DefaultRootLogLevel, LogLevels: &logLevels}
+
+			for _, clusterId := range args {
+
+				_, resp, err := apiClient.Beta.ManagedClustersAPI.PutClientLogConfiguration(context.TODO(), clusterId).ClientLogConfiguration(configuration).Execute()
+				if err != nil {
+					return sdk.HandleSDKError(resp, err)
+				}
+
+				cmd.Printf("Reset the log configuration of cluster %s\n", clusterId)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
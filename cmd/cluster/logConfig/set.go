@@ -5,12 +5,13 @@ import (
 	_ "embed"
 	"errors"
 	"strings"
+	"time"
 
-	"github.com/charmbracelet/log"
 	beta "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/config"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/sdk"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/va"
 	"github.com/spf13/cobra"
 )
 
@@ -23,6 +24,7 @@ func newSetCommand() *cobra.Command {
 	var durationInMinutes int32
 	var connectors []string
 	var expiration string
+	var profile string
 	cmd := &cobra.Command{
 		Use:     "set",
 		Short:   "Set a VA cluster's log configuration",
@@ -31,42 +33,64 @@ func newSetCommand() *cobra.Command {
 		Args:    cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 
-			rootLevel := beta.StandardLevel(level)
-
-			if !rootLevel.IsValid() {
-				return errors.New("invalid logLevel: " + level)
+			configuration := beta.ClientLogConfiguration{DurationMinutes: durationInMinutes}
+			logLevels := make(map[string]beta.StandardLevel)
 
+			if profile != "" {
+				var err error
+				configuration, err = va.ProfileLogConfiguration(profile)
+				if err != nil {
+					return err
+				}
+				logLevels = configuration.GetLogLevels()
+				if configuration.DurationMinutes == 0 || cmd.Flags().Changed("durationInMinutes") {
+					configuration.DurationMinutes = durationInMinutes
+				}
 			}
 
-			if durationInMinutes < 5 || durationInMinutes > 1440 {
-				return errors.New("invalid durationInMinutes: " + string(durationInMinutes))
+			if level != "" || profile == "" {
+				configuration.RootLevel = beta.StandardLevel(strings.ToUpper(level))
+			}
+			if !configuration.RootLevel.IsValid() {
+				return errors.New("invalid logLevel: " + level)
 			}
 
-			apiClient, err := config.InitAPIClient(false)
+			// Connector flags add to, or override, the loggers of the profile
+			flagLevels, err := va.ParseLogLevels(connectors)
 			if err != nil {
 				return err
 			}
+			for class, level := range flagLevels {
+				logLevels[class] = level
+			}
+			configuration.LogLevels = &logLevels
 
-			logLevels := make(map[string]beta.StandardLevel)
-
-			for _, connector := range connectors {
-				parts := strings.Split(connector, "=")
-				conLevel := beta.StandardLevel(parts[1])
-				if conLevel.IsValid() {
-					logLevels[parts[0]] = conLevel
-				} else {
-					log.Warn("Log Level Invalid", "Connector", parts[0], "LogLevel", parts[1])
+			if expiration != "" {
+				minutes, expiresAt, err := durationUntil(expiration, time.Now())
+				if err != nil {
+					return err
 				}
+				configuration.DurationMinutes = minutes
+				configuration.SetExpiration(beta.SailPointTime{Time: expiresAt})
+			}
+
+			if err := validateDuration(configuration.DurationMinutes); err != nil {
+				return err
+			}
+
+			apiClient, err := config.InitAPIClient(false)
+			if err != nil {
+				return err
 			}
 
 			for _, clusterId := range args {
 
-				configuration, resp, err := apiClient.Beta.ManagedClustersAPI.PutClientLogConfiguration(context.TODO(), clusterId).ClientLogConfiguration(beta.ClientLogConfiguration{DurationMinutes: durationInMinutes, RootLevel: rootLevel, LogLevels: &logLevels}).Execute()
+				result, resp, err := apiClient.Beta.ManagedClustersAPI.PutClientLogConfiguration(context.TODO(), clusterId).ClientLogConfiguration(configuration).Execute()
 				if err != nil {
 					return sdk.HandleSDKError(resp, err)
 				}
 
-				cmd.Println(util.PrettyPrint(configuration))
+				cmd.Println(util.PrettyPrint(result))
 			}
 
 			return nil
@@ -77,6 +101,7 @@ func newSetCommand() *cobra.Command {
 	cmd.Flags().Int32VarP(&durationInMinutes, "durationInMinutes", "d", 30, "Duration in minutes for the log configuration.\nProvided value must be above 5 and below 1440")
 	cmd.Flags().StringVarP(&expiration, "expiration", "e", "", "Expiration string value for the log configuration. Example: 2020-12-15T19:13:36.079Z")
 	cmd.Flags().StringArrayVarP(&connectors, "connector", "c", []string{}, "Connectors and Log Level to configure. Example:\n-c sailpoint.connector.ADLDAPConnector=TRACE\n--connector sailpoint.connector.ADLDAPConnector=TRACE")
+	cmd.Flags().StringVarP(&profile, "profile", "p", "", "Name of a saved log profile to apply, other flags override the profile")
 	cmd.MarkFlagsMutuallyExclusive("expiration", "durationInMinutes")
 	return cmd
 }
@@ -10,11 +10,14 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"time"
 
 	"github.com/sailpoint-oss/sailpoint-cli/internal/client"
 )
 
-const maskedPassword = "******"
+// MaskedPassword replaces secret values in logged invoke input and printed
+// configs
+const MaskedPassword = "******"
 
 // ConnClient is an sail connect client for a specific connector
 type ConnClient struct {
@@ -23,6 +26,7 @@ type ConnClient struct {
 	config       json.RawMessage
 	connectorRef string
 	endpoint     string
+	runtime      bool
 }
 
 // NewConnClient returns a client for the provided (connectorID, version, config)
@@ -36,6 +40,38 @@ func NewConnClient(client client.Client, version *int, config json.RawMessage, c
 	}
 }
 
+// NewConnRuntimeClient returns a client that sends every command straight to
+// the connector runtime at endpoint instead of the standard invoke path
+func NewConnRuntimeClient(client client.Client, version *int, config json.RawMessage, connectorRef string, endpoint string) *ConnClient {
+	cc := NewConnClient(client, version, config, connectorRef, endpoint)
+	cc.runtime = true
+	return cc
+}
+
+// invokeUrl returns the url commands are posted to
+func (cc *ConnClient) invokeUrl() string {
+	if cc.runtime {
+		return connResourceUrl(cc.endpoint)
+	}
+	return connResourceUrl(cc.endpoint, cc.connectorRef, "invoke")
+}
+
+// post sends a command to the connector. For runtime invocations the round
+// trip time is logged.
+func (cc *ConnClient) post(ctx context.Context, url string, cmdRaw json.RawMessage) (*http.Response, error) {
+	start := time.Now()
+	resp, err := cc.client.Post(ctx, url, "application/json", bytes.NewReader(cmdRaw), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if cc.runtime {
+		log.Printf("Completed in %s", time.Since(start))
+	}
+
+	return resp, nil
+}
+
 // TestConnectionWithConfig provides a way to run std:test-connection with an
 // arbitrary config
 func (cc *ConnClient) TestConnectionWithConfig(ctx context.Context, cfg json.RawMessage) error {
@@ -44,7 +80,7 @@ func (cc *ConnClient) TestConnectionWithConfig(ctx context.Context, cfg json.Raw
 		return err
 	}
 
-	resp, err := cc.client.Post(ctx, connResourceUrl(cc.endpoint, cc.connectorRef, "invoke"), "application/json", bytes.NewReader(cmdRaw), nil)
+	resp, err := cc.post(ctx, cc.invokeUrl(), cmdRaw)
 	if err != nil {
 		return err
 	}
@@ -65,7 +101,7 @@ func (cc *ConnClient) TestConnection(ctx context.Context) (rawResponse []byte, e
 		return nil, err
 	}
 
-	resp, err := cc.client.Post(ctx, connResourceUrl(cc.endpoint, cc.connectorRef, "invoke"), "application/json", bytes.NewReader(cmdRaw), nil)
+	resp, err := cc.post(ctx, cc.invokeUrl(), cmdRaw)
 	if err != nil {
 		return nil, err
 	}
@@ -112,7 +148,7 @@ func (cc *ConnClient) ChangePassword(ctx context.Context, identity string, uniqu
 	maskedInput, err := json.Marshal(map[string]interface{}{
 		"identity": identity,
 		"key":      key,
-		"password": maskedPassword,
+		"password": MaskedPassword,
 	})
 	if err != nil {
 		return nil, err
@@ -123,7 +159,7 @@ func (cc *ConnClient) ChangePassword(ctx context.Context, identity string, uniqu
 		return nil, err
 	}
 
-	resp, err := cc.client.Post(ctx, connResourceUrl(cc.endpoint, cc.connectorRef, "invoke"), "application/json", bytes.NewReader(cmdRaw), nil)
+	resp, err := cc.post(ctx, cc.invokeUrl(), cmdRaw)
 	if err != nil {
 		return nil, err
 	}
@@ -230,7 +266,7 @@ func (cc *ConnClient) AccountList(ctx context.Context, stateful *bool, stateId *
 		return nil, nil, nil, err
 	}
 
-	resp, err := cc.client.Post(ctx, connResourceUrl(cc.endpoint, cc.connectorRef, "invoke"), "application/json", bytes.NewReader(cmdRaw), nil)
+	resp, err := cc.post(ctx, cc.invokeUrl(), cmdRaw)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -302,7 +338,7 @@ func (cc *ConnClient) AccountRead(ctx context.Context, id string, uniqueID strin
 		return nil, nil, err
 	}
 
-	resp, err := cc.client.Post(ctx, connResourceUrl(cc.endpoint, cc.connectorRef, "invoke"), "application/json", bytes.NewReader(cmdRaw), nil)
+	resp, err := cc.post(ctx, cc.invokeUrl(), cmdRaw)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -355,7 +391,7 @@ func (cc *ConnClient) AccountCreate(ctx context.Context, identity *string, attri
 		return nil, nil, err
 	}
 
-	resp, err := cc.client.Post(ctx, connResourceUrl(cc.endpoint, cc.connectorRef, "invoke"), "application/json", bytes.NewReader(cmdRaw), nil)
+	resp, err := cc.post(ctx, cc.invokeUrl(), cmdRaw)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -408,7 +444,7 @@ func (cc *ConnClient) AccountDelete(ctx context.Context, id string, uniqueID str
 		return nil, err
 	}
 
-	resp, err := cc.client.Post(ctx, connResourceUrl(cc.endpoint, cc.connectorRef, "invoke"), "application/json", bytes.NewReader(cmdRaw), nil)
+	resp, err := cc.post(ctx, cc.invokeUrl(), cmdRaw)
 	if err != nil {
 		return nil, err
 	}
@@ -460,7 +496,7 @@ func (cc *ConnClient) AccountUpdate(ctx context.Context, id string, uniqueID str
 		return nil, nil, err
 	}
 
-	resp, err := cc.client.Post(ctx, connResourceUrl(cc.endpoint, cc.connectorRef, "invoke"), "application/json", bytes.NewReader(cmdRaw), nil)
+	resp, err := cc.post(ctx, cc.invokeUrl(), cmdRaw)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -498,7 +534,7 @@ func (cc *ConnClient) AccountDiscoverSchema(ctx context.Context) (accountSchema
 		return nil, nil, err
 	}
 
-	resp, err := cc.client.Post(ctx, connResourceUrl(cc.endpoint, cc.connectorRef, "invoke"), "application/json", bytes.NewReader(cmdRaw), nil)
+	resp, err := cc.post(ctx, cc.invokeUrl(), cmdRaw)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -582,7 +618,7 @@ func (cc *ConnClient) EntitlementList(ctx context.Context, t string, stateful *b
 		return nil, nil, nil, err
 	}
 
-	resp, err := cc.client.Post(ctx, connResourceUrl(cc.endpoint, cc.connectorRef, "invoke"), "application/json", bytes.NewReader(cmdRaw), nil)
+	resp, err := cc.post(ctx, cc.invokeUrl(), cmdRaw)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -648,7 +684,7 @@ func (cc *ConnClient) EntitlementRead(ctx context.Context, id string, uniqueID s
 		return nil, nil, err
 	}
 
-	resp, err := cc.client.Post(ctx, connResourceUrl(cc.endpoint, cc.connectorRef, "invoke"), "application/json", bytes.NewReader(cmdRaw), nil)
+	resp, err := cc.post(ctx, cc.invokeUrl(), cmdRaw)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -692,7 +728,7 @@ func (cc *ConnClient) SpecRead(ctx context.Context) (connSpec *ConnSpec, err err
 		return nil, err
 	}
 
-	resp, err := cc.client.Post(ctx, connResourceUrl(cc.endpoint, cc.connectorRef, "invoke"), "application/json", bytes.NewReader(cmdRaw), nil)
+	resp, err := cc.post(ctx, cc.invokeUrl(), cmdRaw)
 	if err != nil {
 		return nil, err
 	}
@@ -761,7 +797,7 @@ func (cc *ConnClient) Invoke(ctx context.Context, cmdType string, input json.Raw
 		return nil, err
 	}
 
-	resp, err := cc.client.Post(ctx, connResourceUrl(cc.endpoint, cc.connectorRef, "invoke"), "application/json", bytes.NewReader(cmdRaw), nil)
+	resp, err := cc.post(ctx, cc.invokeUrl(), cmdRaw)
 	if err != nil {
 		return nil, err
 	}
@@ -943,7 +979,7 @@ func (cc *ConnClient) SourceDataDiscover(ctx context.Context, queryInput map[str
 		return nil, nil, err
 	}
 
-	resp, err := cc.client.Post(ctx, connResourceUrl(cc.endpoint), "application/json", bytes.NewReader(cmdRaw), nil)
+	resp, err := cc.post(ctx, connResourceUrl(cc.endpoint), cmdRaw)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -988,7 +1024,7 @@ func (cc *ConnClient) SourceDataRead(ctx context.Context, sourceDataKey string,
 		return nil, nil, err
 	}
 
-	resp, err := cc.client.Post(ctx, connResourceUrl(cc.endpoint, cc.connectorRef, "invoke-direct"), "application/json", bytes.NewReader(cmdRaw), nil)
+	resp, err := cc.post(ctx, connResourceUrl(cc.endpoint, cc.connectorRef, "invoke-direct"), cmdRaw)
 	if err != nil {
 		return nil, nil, err
 	}
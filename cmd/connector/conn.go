@@ -60,6 +60,9 @@ func NewConnCmd(term terminal.Terminal) *cobra.Command {
 		newConnDeleteCmd(Client),
 		newConnCustomizersCmd(Client),
 		newConnInstancesCmd(Client),
+		newConnSecretsCmd(term),
+		newConnInventoryCmd(Client),
+		newConnSpecCmd(Client),
 	)
 
 	return conn
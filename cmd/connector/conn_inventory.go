@@ -0,0 +1,328 @@
+// Copyright (c) 2025, SailPoint Technologies, Inc. All rights reserved.
+package connector
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sailpoint-oss/sailpoint-cli/internal/client"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	driftMissingTagVersion      = "missing-tag-version"
+	driftMissingCustomizer      = "missing-customizer"
+	driftStaleCustomizerVersion = "stale-customizer-version"
+)
+
+type inventoryReport struct {
+	Connectors  []inventoryConnector  `json:"connectors"`
+	Customizers []inventoryCustomizer `json:"customizers"`
+	Instances   []inventoryInstance   `json:"instances"`
+	Drift       []inventoryDrift      `json:"drift"`
+}
+
+type inventoryConnector struct {
+	ID       string         `json:"id"`
+	Alias    string         `json:"alias"`
+	Versions []int          `json:"versions"`
+	Tags     []inventoryTag `json:"tags"`
+}
+
+type inventoryTag struct {
+	Name          string `json:"name"`
+	ActiveVersion uint32 `json:"activeVersion"`
+	Missing       bool   `json:"missing"`
+}
+
+type inventoryCustomizer struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Latest    *int     `json:"latestVersion,omitempty"`
+	Versions  []int    `json:"versions"`
+	Instances []string `json:"instances"`
+}
+
+type inventoryInstance struct {
+	ID                string `json:"id"`
+	Name              string `json:"name"`
+	ConnectorID       string `json:"connectorId,omitempty"`
+	CustomizerID      string `json:"customizerId,omitempty"`
+	CustomizerName    string `json:"customizerName,omitempty"`
+	CustomizerVersion *int   `json:"customizerVersion,omitempty"`
+	Stale             bool   `json:"stale"`
+}
+
+// inventoryDrift describes a deployed resource that no longer lines up with
+// what it references
+type inventoryDrift struct {
+	Kind     string `json:"kind"`
+	Resource string `json:"resource"`
+	Message  string `json:"message"`
+}
+
+func newConnInventoryCmd(client client.Client) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "inventory",
+		Short:   "Report deployed connectors, customizers and instances",
+		Long:    "Report connectors with their tags and versions, customizers with their versions and instances with their linked customizer, flagging drift between them",
+		Example: "sail conn inventory\nsail conn inventory --format json > inventory.json",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format := cmd.Flags().Lookup("format").Value.String()
+			if format != "markdown" && format != "json" {
+				return fmt.Errorf("unsupported format %q, use markdown or json", format)
+			}
+
+			report, err := buildInventory(cmd, client)
+			if err != nil {
+				return err
+			}
+
+			if format == "json" {
+				raw, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), string(raw))
+			} else {
+				_, _ = fmt.Fprint(cmd.OutOrStdout(), report.markdown())
+			}
+
+			if len(report.Drift) > 0 {
+				if failOnDrift, _ := cmd.Flags().GetBool("fail-on-drift"); failOnDrift {
+					return fmt.Errorf("%d drift issue(s) found", len(report.Drift))
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("format", "f", "markdown", "Report format, markdown or json")
+	cmd.Flags().Bool("fail-on-drift", false, "Exit with an error when drift is found")
+
+	return cmd
+}
+
+// buildInventory collects connectors, customizers and instances and joins
+// them into a single report
+func buildInventory(cmd *cobra.Command, client client.Client) (*inventoryReport, error) {
+	endpoint := cmd.Flags().Lookup("conn-endpoint").Value.String()
+
+	report := &inventoryReport{
+		Connectors:  []inventoryConnector{},
+		Customizers: []inventoryCustomizer{},
+		Instances:   []inventoryInstance{},
+		Drift:       []inventoryDrift{},
+	}
+
+	var conns []connectorList
+	if err := getInventoryResource(cmd, client, endpoint, "list connectors", &conns); err != nil {
+		return nil, err
+	}
+	sort.Slice(conns, func(i, j int) bool {
+		return conns[i].Alias < conns[j].Alias
+	})
+
+	for _, conn := range conns {
+		if conn.ID == "" {
+			continue
+		}
+
+		var versions []connectorVersion
+		if err := getInventoryResource(cmd, client, util.ResourceUrl(endpoint, conn.ID, "versions"), "list versions", &versions); err != nil {
+			return nil, err
+		}
+
+		var tags []tag
+		if err := getInventoryResource(cmd, client, util.ResourceUrl(endpoint, conn.ID, "tags"), "list tags", &tags); err != nil {
+			return nil, err
+		}
+
+		ic := inventoryConnector{ID: conn.ID, Alias: conn.Alias, Versions: []int{}, Tags: []inventoryTag{}}
+		known := map[int]bool{}
+		for _, v := range versions {
+			ic.Versions = append(ic.Versions, v.Version)
+			known[v.Version] = true
+		}
+		sort.Ints(ic.Versions)
+
+		for _, t := range tags {
+			it := inventoryTag{Name: t.TagName, ActiveVersion: t.ActiveVersion, Missing: !known[int(t.ActiveVersion)]}
+			if it.Missing {
+				report.Drift = append(report.Drift, inventoryDrift{
+					Kind:     driftMissingTagVersion,
+					Resource: fmt.Sprintf("connector %s tag %s", conn.Alias, t.TagName),
+					Message:  fmt.Sprintf("tag points at version %d which does not exist", t.ActiveVersion),
+				})
+			}
+			ic.Tags = append(ic.Tags, it)
+		}
+
+		report.Connectors = append(report.Connectors, ic)
+	}
+
+	var customizers []customizer
+	if err := getInventoryResource(cmd, client, util.ResourceUrl(connectorCustomizersEndpoint), "list customizers", &customizers); err != nil {
+		return nil, err
+	}
+	sort.Slice(customizers, func(i, j int) bool {
+		return customizers[i].Name < customizers[j].Name
+	})
+
+	customizerIndex := map[string]int{}
+	for _, cus := range customizers {
+		var versions []customizerVersion
+		if err := getInventoryResource(cmd, client, util.ResourceUrl(connectorCustomizersEndpoint, cus.ID, "versions"), "list customizer versions", &versions); err != nil {
+			return nil, err
+		}
+
+		icus := inventoryCustomizer{ID: cus.ID, Name: cus.Name, Latest: cus.ImageVersion, Versions: []int{}, Instances: []string{}}
+		for _, v := range versions {
+			icus.Versions = append(icus.Versions, v.Version)
+		}
+		sort.Ints(icus.Versions)
+		if n := len(icus.Versions); n > 0 && (icus.Latest == nil || *icus.Latest < icus.Versions[n-1]) {
+			latest := icus.Versions[n-1]
+			icus.Latest = &latest
+		}
+
+		customizerIndex[cus.ID] = len(report.Customizers)
+		report.Customizers = append(report.Customizers, icus)
+	}
+
+	var instances []instance
+	if err := getInventoryResource(cmd, client, util.ResourceUrl(connectorInstancesEndpoint), "list connector instances", &instances); err != nil {
+		return nil, err
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].Name < instances[j].Name
+	})
+
+	for _, inst := range instances {
+		ii := inventoryInstance{
+			ID:                inst.ID,
+			Name:              inst.Name,
+			ConnectorID:       inst.ConnectorID,
+			CustomizerID:      inst.CustomizerId,
+			CustomizerVersion: inst.CustomizerVersion,
+		}
+
+		if inst.CustomizerId != "" {
+			idx, ok := customizerIndex[inst.CustomizerId]
+			if !ok {
+				report.Drift = append(report.Drift, inventoryDrift{
+					Kind:     driftMissingCustomizer,
+					Resource: fmt.Sprintf("instance %s", inst.Name),
+					Message:  fmt.Sprintf("linked customizer %s does not exist", inst.CustomizerId),
+				})
+			} else {
+				cus := &report.Customizers[idx]
+				cus.Instances = append(cus.Instances, inst.ID)
+				ii.CustomizerName = cus.Name
+
+				if inst.CustomizerVersion != nil && cus.Latest != nil && *inst.CustomizerVersion < *cus.Latest {
+					ii.Stale = true
+					report.Drift = append(report.Drift, inventoryDrift{
+						Kind:     driftStaleCustomizerVersion,
+						Resource: fmt.Sprintf("instance %s", inst.Name),
+						Message:  fmt.Sprintf("runs customizer %s version %d, latest is %d", cus.Name, *inst.CustomizerVersion, *cus.Latest),
+					})
+				}
+			}
+		}
+
+		report.Instances = append(report.Instances, ii)
+	}
+
+	return report, nil
+}
+
+func getInventoryResource(cmd *cobra.Command, client client.Client, url string, action string, v interface{}) error {
+	resp, err := client.Get(cmd.Context(), url, nil)
+	if err != nil {
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s failed. status: %s\nbody: %s", action, resp.Status, string(body))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (r *inventoryReport) markdown() string {
+	var sb strings.Builder
+
+	sb.WriteString("# Connector Inventory\n\n## Connectors\n\n")
+	sb.WriteString("| ID | Alias | Versions | Tags |\n| --- | --- | --- | --- |\n")
+	for _, c := range r.Connectors {
+		var tags []string
+		for _, t := range c.Tags {
+			entry := fmt.Sprintf("%s → %d", t.Name, t.ActiveVersion)
+			if t.Missing {
+				entry += " (missing)"
+			}
+			tags = append(tags, entry)
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n", c.ID, c.Alias, joinInts(c.Versions), strings.Join(tags, ", "))
+	}
+
+	sb.WriteString("\n## Customizers\n\n")
+	sb.WriteString("| ID | Name | Latest Version | Versions | Instances |\n| --- | --- | --- | --- | --- |\n")
+	for _, c := range r.Customizers {
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s | %d |\n", c.ID, c.Name, optionalInt(c.Latest), joinInts(c.Versions), len(c.Instances))
+	}
+
+	sb.WriteString("\n## Instances\n\n")
+	sb.WriteString("| ID | Name | Connector ID | Customizer | Customizer Version | Stale |\n| --- | --- | --- | --- | --- | --- |\n")
+	for _, i := range r.Instances {
+		customizer := i.CustomizerName
+		if customizer == "" {
+			customizer = i.CustomizerID
+		}
+		stale := ""
+		if i.Stale {
+			stale = "yes"
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s | %s |\n", i.ID, i.Name, i.ConnectorID, customizer, optionalInt(i.CustomizerVersion), stale)
+	}
+
+	sb.WriteString("\n## Drift\n\n")
+	if len(r.Drift) == 0 {
+		sb.WriteString("No drift found.\n")
+	} else {
+		sb.WriteString("| Kind | Resource | Details |\n| --- | --- | --- |\n")
+		for _, d := range r.Drift {
+			fmt.Fprintf(&sb, "| %s | %s | %s |\n", d.Kind, d.Resource, d.Message)
+		}
+	}
+
+	return sb.String()
+}
+
+func joinInts(values []int) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, ", ")
+}
+
+func optionalInt(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
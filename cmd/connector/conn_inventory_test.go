@@ -0,0 +1,78 @@
+package connector
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/mocks"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+)
+
+func jsonResponse(body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     http.StatusText(http.StatusOK),
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestNewConnInventoryCmd_drift(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClient := mocks.NewMockClient(ctrl)
+
+	responses := map[string]string{
+		connectorsEndpoint: `[{"id":"conn-1","alias":"my-connector"}]`,
+		util.ResourceUrl(connectorsEndpoint, "conn-1", "versions"):           `[{"connectorId":"conn-1","version":1},{"connectorId":"conn-1","version":2}]`,
+		util.ResourceUrl(connectorsEndpoint, "conn-1", "tags"):               `[{"id":"t1","tagName":"latest","activeVersion":2},{"id":"t2","tagName":"rc","activeVersion":3}]`,
+		util.ResourceUrl(connectorCustomizersEndpoint):                       `[{"id":"cust-1","name":"My Customizer","imageVersion":2}]`,
+		util.ResourceUrl(connectorCustomizersEndpoint, "cust-1", "versions"): `[{"connectorCustomizerId":"cust-1","version":1},{"connectorCustomizerId":"cust-1","version":2}]`,
+		util.ResourceUrl(connectorInstancesEndpoint):                         `[{"id":"inst-1","name":"Current","connectorCustomizerId":"cust-1","connectorCustomizerVersion":2},{"id":"inst-2","name":"Old","connectorCustomizerId":"cust-1","connectorCustomizerVersion":1},{"id":"inst-3","name":"Orphan","connectorCustomizerId":"cust-9"}]`,
+	}
+	for url, body := range responses {
+		mockClient.EXPECT().Get(gomock.Any(), url, gomock.Nil()).Return(jsonResponse(body), nil).Times(1)
+	}
+
+	cmd := newConnInventoryCmd(mockClient)
+	cmd.Flags().String("conn-endpoint", connectorsEndpoint, "")
+	var outBuf bytes.Buffer
+	cmd.SetOut(&outBuf)
+	cmd.SetArgs([]string{"--format", "json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected Execute error: %v", err)
+	}
+
+	var report inventoryReport
+	if err := json.Unmarshal(outBuf.Bytes(), &report); err != nil {
+		t.Fatalf("failed to parse report: %v", err)
+	}
+
+	kinds := map[string]string{}
+	for _, d := range report.Drift {
+		kinds[d.Kind] = d.Resource
+	}
+
+	expected := map[string]string{
+		driftMissingTagVersion:      "connector my-connector tag rc",
+		driftStaleCustomizerVersion: "instance Old",
+		driftMissingCustomizer:      "instance Orphan",
+	}
+	if len(report.Drift) != len(expected) {
+		t.Fatalf("expected %d drift entries, got %d: %+v", len(expected), len(report.Drift), report.Drift)
+	}
+	for kind, resource := range expected {
+		if kinds[kind] != resource {
+			t.Errorf("expected %s drift for %q, got %q", kind, resource, kinds[kind])
+		}
+	}
+
+	if len(report.Customizers) != 1 || len(report.Customizers[0].Instances) != 2 {
+		t.Errorf("expected customizer to list two linked instances, got %+v", report.Customizers)
+	}
+}
@@ -2,15 +2,21 @@
 package connector
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
 
 	connclient "github.com/sailpoint-oss/sailpoint-cli/cmd/connector/client"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/client"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/config"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/terminal"
 	"github.com/spf13/cobra"
+	keyring "github.com/zalando/go-keyring"
 )
 
 const (
@@ -37,12 +43,14 @@ func newConnInvokeCmd(client client.Client, term terminal.Terminal) *cobra.Comma
 
 	cmd.PersistentFlags().StringP("version", "v", "", "Optional. Run against a specific version if provided. Otherwise run against the latest tag.")
 
-	cmd.PersistentFlags().StringP("config-path", "p", "", "Path to config to use for commands")
-	cmd.PersistentFlags().StringP("config-json", "", "", "Config JSON to use for commands")
+	cmd.PersistentFlags().StringP("config-path", "p", "", "Path to config to use for commands. String values may reference secrets as ${env:NAME}, ${keyring:name} or ${file:path}")
+	cmd.PersistentFlags().StringP("config-json", "", "", "Config JSON to use for commands. String values may reference secrets as ${env:NAME}, ${keyring:name} or ${file:path}")
 
 	cmd.PersistentFlags().StringP("id", "c", "", "Connector ID or Alias")
 	_ = cmd.MarkPersistentFlagRequired("id")
 
+	cmd.PersistentFlags().Bool("runtime", false, "Optional. Invoke the connector through the direct runtime endpoint and log per-invocation timing")
+
 	cmd.AddCommand(
 		newConnInvokeTestConnectionCmd(client),
 		newConnInvokeChangePasswordCmd(client, term),
@@ -72,14 +80,123 @@ func invokeConfig(cmd *cobra.Command) (json.RawMessage, error) {
 		return nil, fmt.Errorf("Either config-path or config-json must be set")
 	}
 
+	var cfg json.RawMessage
 	if cmd.Flags().Lookup("config-json") != nil && cmd.Flags().Lookup("config-json").Value.String() != "" {
-		return json.RawMessage(cmd.Flags().Lookup("config-json").Value.String()), nil
+		cfg = json.RawMessage(cmd.Flags().Lookup("config-json").Value.String())
+	} else {
+		raw, err := os.ReadFile(cmd.Flags().Lookup("config-path").Value.String())
+		if err != nil {
+			return nil, err
+		}
+		cfg = raw
+	}
+
+	return resolveConfigSecrets(cfg)
+}
+
+// secretRefPattern matches secret references embedded in source config string
+// values, e.g. ${env:API_TOKEN}, ${keyring:my-source} or ${file:./token.txt}
+var secretRefPattern = regexp.MustCompile(`\$\{(env|keyring|file):([^}]+)\}`)
+
+// resolveConfigSecrets replaces every secret reference found in the string
+// values of cfg with the referenced secret. Configs without references are
+// returned untouched.
+func resolveConfigSecrets(cfg json.RawMessage) (json.RawMessage, error) {
+	if !secretRefPattern.Match(cfg) {
+		return cfg, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(cfg))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %v", err)
+	}
+
+	resolved, err := resolveSecretValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(resolved)
+}
+
+func resolveSecretValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			resolved, err := resolveSecretValue(item)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = resolved
+		}
+		return v, nil
+	case []interface{}:
+		for i, item := range v {
+			resolved, err := resolveSecretValue(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+		return v, nil
+	case string:
+		var resolveErr error
+		resolved := secretRefPattern.ReplaceAllStringFunc(v, func(ref string) string {
+			match := secretRefPattern.FindStringSubmatch(ref)
+			secret, err := lookupSecret(match[1], match[2])
+			if err != nil && resolveErr == nil {
+				resolveErr = err
+			}
+			return secret
+		})
+		if resolveErr != nil {
+			return nil, resolveErr
+		}
+		return resolved, nil
+	default:
+		return v, nil
 	}
+}
 
-	return os.ReadFile(cmd.Flags().Lookup("config-path").Value.String())
+func lookupSecret(source string, name string) (string, error) {
+	switch source {
+	case "env":
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %q referenced in config is not set", name)
+		}
+		return value, nil
+	case "keyring":
+		value, err := config.GetConnectorSecret(name)
+		if err != nil {
+			if errors.Is(err, keyring.ErrNotFound) {
+				return "", fmt.Errorf("secret %q referenced in config not found in keyring, set it with 'sail conn secrets set %s'", name, name)
+			}
+			return "", err
+		}
+		return value, nil
+	case "file":
+		raw, err := os.ReadFile(name)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file referenced in config: %v", err)
+		}
+		return strings.TrimRight(string(raw), "\r\n"), nil
+	default:
+		return "", fmt.Errorf("unsupported secret reference type %q", source)
+	}
 }
 
+// connClient returns a client for the connector selected on the command line.
+// When --runtime is set, commands are routed through the direct runtime
+// endpoint.
 func connClient(cmd *cobra.Command, spClient client.Client) (*connclient.ConnClient, error) {
+	if runtime := cmd.Flags().Lookup("runtime"); runtime != nil && runtime.Value.String() == "true" {
+		return connRuntimeClient(cmd, spClient)
+	}
+
 	connectorRef := cmd.Flags().Lookup("id").Value.String()
 	version := cmd.Flags().Lookup("version").Value.String()
 	endpoint := cmd.Flags().Lookup("conn-endpoint").Value.String()
@@ -119,7 +236,7 @@ func connRuntimeClient(cmd *cobra.Command, spClient client.Client) (*connclient.
 	if err != nil {
 		return nil, err
 	}
-	cc := connclient.NewConnClient(spClient, v, cfg, connectorRef, connectorRuntimeDirectExecuteEndpoint)
+	cc := connclient.NewConnRuntimeClient(spClient, v, cfg, connectorRef, connectorRuntimeDirectExecuteEndpoint)
 
 	return cc, nil
 }
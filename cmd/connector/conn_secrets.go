@@ -0,0 +1,95 @@
+// Copyright (c) 2025, SailPoint Technologies, Inc. All rights reserved.
+package connector
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/sailpoint-oss/sailpoint-cli/internal/config"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/terminal"
+	"github.com/spf13/cobra"
+)
+
+func newConnSecretsCmd(term terminal.Terminal) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "Manage secrets referenced by connector invoke configs",
+		Long:  "Manage secrets stored in the system keyring. Reference them from a source config passed to 'sail conn invoke' as ${keyring:name}.",
+		Run: func(cmd *cobra.Command, args []string) {
+			_, _ = fmt.Fprint(cmd.OutOrStdout(), cmd.UsageString())
+		},
+	}
+
+	cmd.AddCommand(
+		newConnSecretsSetCmd(term),
+		newConnSecretsDeleteCmd(),
+	)
+
+	return cmd
+}
+
+func newConnSecretsSetCmd(term terminal.Terminal) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "set [name]",
+		Short:   "Store a secret in the system keyring",
+		Example: "sail conn secrets set my-source-password\necho -n $TOKEN | sail conn secrets set my-source-token --stdin",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fromStdin, err := cmd.Flags().GetBool("stdin")
+			if err != nil {
+				return err
+			}
+
+			var value string
+			if fromStdin {
+				value, err = bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+				if err != nil && value == "" {
+					return fmt.Errorf("failed to read secret from stdin: %v", err)
+				}
+				value = strings.TrimRight(value, "\r\n")
+			} else {
+				value, err = term.PromptPassword("Enter Secret:")
+				if err != nil {
+					return err
+				}
+			}
+
+			if value == "" {
+				return fmt.Errorf("secret value must not be empty")
+			}
+
+			err = config.SetConnectorSecret(args[0], value)
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "secret %s stored, reference it as ${keyring:%s}\n", args[0], args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("stdin", false, "Read the secret value from stdin instead of prompting")
+
+	return cmd
+}
+
+func newConnSecretsDeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "delete [name]",
+		Short:   "Delete a secret from the system keyring",
+		Example: "sail conn secrets delete my-source-password",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := config.DeleteConnectorSecret(args[0])
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "secret %s deleted.\n", args[0])
+			return nil
+		},
+	}
+
+	return cmd
+}
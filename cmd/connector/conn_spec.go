@@ -0,0 +1,25 @@
+// Copyright (c) 2025, SailPoint Technologies, Inc. All rights reserved.
+package connector
+
+import (
+	"fmt"
+
+	"github.com/sailpoint-oss/sailpoint-cli/internal/client"
+	"github.com/spf13/cobra"
+)
+
+func newConnSpecCmd(client client.Client) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "spec",
+		Short: "Manage the local connector specification",
+		Run: func(cmd *cobra.Command, args []string) {
+			_, _ = fmt.Fprint(cmd.OutOrStdout(), cmd.UsageString())
+		},
+	}
+
+	cmd.AddCommand(
+		newConnSpecSyncSchemaCmd(client),
+	)
+
+	return cmd
+}
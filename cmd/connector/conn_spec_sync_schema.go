@@ -0,0 +1,493 @@
+// Copyright (c) 2025, SailPoint Technologies, Inc. All rights reserved.
+package connector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	connclient "github.com/sailpoint-oss/sailpoint-cli/cmd/connector/client"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/client"
+	"github.com/spf13/cobra"
+)
+
+type syncSchemaOptions struct {
+	// Prune removes local attributes that are no longer discovered
+	Prune bool
+	// CreateTemplate regenerates accountCreateTemplate fields from the schema
+	CreateTemplate bool
+}
+
+// schemaChange is a single line of the sync diff. Notes are informational and
+// do not modify the spec.
+type schemaChange struct {
+	Op      string
+	Message string
+	Note    bool
+}
+
+func (c schemaChange) String() string {
+	line := fmt.Sprintf("%s %s", c.Op, c.Message)
+	switch c.Op {
+	case "+":
+		return color.GreenString(line)
+	case "-":
+		return color.RedString(line)
+	case "~":
+		return color.YellowString(line)
+	default:
+		return line
+	}
+}
+
+func newConnSpecSyncSchemaCmd(client client.Client) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync-schema",
+		Short: "Merge the discovered account schema into connector-spec.json",
+		Long: "Run std:account:discover-schema against a connector and merge the discovered attributes into the accountSchema of the local connector spec. " +
+			"Descriptions and flags edited by hand are preserved.",
+		Example: "sail conn spec sync-schema -c my-connector -p config.json --dry-run\n" +
+			"sail conn spec sync-schema --schema-file discovered.json --create-template",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			specPath := cmd.Flags().Lookup("spec").Value.String()
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			prune, _ := cmd.Flags().GetBool("prune")
+			createTemplate, _ := cmd.Flags().GetBool("create-template")
+
+			discovered, err := discoveredAccountSchema(cmd, client)
+			if err != nil {
+				return err
+			}
+
+			specRaw, err := os.ReadFile(specPath)
+			if err != nil {
+				return err
+			}
+
+			updated, changes, err := syncAccountSchema(specRaw, discovered, syncSchemaOptions{
+				Prune:          prune,
+				CreateTemplate: createTemplate,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to sync %s: %v", specPath, err)
+			}
+
+			modified := false
+			for _, c := range changes {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), c.String())
+				if !c.Note {
+					modified = true
+				}
+			}
+
+			if !modified {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s is up to date\n", specPath)
+				return nil
+			}
+
+			if dryRun {
+				return nil
+			}
+
+			err = os.WriteFile(specPath, updated, 0644)
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s updated\n", specPath)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("spec", connectorSpecName, "Path to the connector spec to update")
+	cmd.Flags().String("schema-file", "", "Use a saved account-discover-schema result instead of invoking the connector")
+	cmd.Flags().Bool("dry-run", false, "Show the changes without writing the spec")
+	cmd.Flags().Bool("prune", false, "Remove attributes that are no longer discovered")
+	cmd.Flags().Bool("create-template", false, "Add accountCreateTemplate fields for new attributes")
+
+	cmd.Flags().StringP("id", "c", "", "Connector ID or Alias")
+	cmd.Flags().StringP("version", "v", "", "Optional. Run against a specific version if provided. Otherwise run against the latest tag.")
+	cmd.Flags().StringP("config-path", "p", "", "Path to config to use for discovery")
+	cmd.Flags().String("config-json", "", "Config JSON to use for discovery")
+	cmd.Flags().Bool("runtime", false, "Invoke the connector through the direct runtime endpoint")
+
+	bindDevConfig(cmd.Flags())
+
+	return cmd
+}
+
+// discoveredAccountSchema returns the account schema from --schema-file, or by
+// invoking std:account:discover-schema on the connector
+func discoveredAccountSchema(cmd *cobra.Command, spClient client.Client) (*connclient.AccountSchema, error) {
+	schemaFile := cmd.Flags().Lookup("schema-file").Value.String()
+	if schemaFile != "" {
+		raw, err := os.ReadFile(schemaFile)
+		if err != nil {
+			return nil, err
+		}
+
+		// accept the output of 'sail conn invoke account-discover-schema',
+		// which wraps the schema in a typed response
+		var wrapped connclient.RawResponse
+		if err := json.Unmarshal(raw, &wrapped); err == nil && wrapped.Type != "" && wrapped.Data != nil {
+			raw = wrapped.Data
+		}
+
+		schema := &connclient.AccountSchema{}
+		err = json.Unmarshal(raw, schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", schemaFile, err)
+		}
+		return schema, nil
+	}
+
+	if cmd.Flags().Lookup("id").Value.String() == "" {
+		return nil, fmt.Errorf("either id or schema-file must be set")
+	}
+
+	cc, err := connClient(cmd, spClient)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, _, err := cc.AccountDiscoverSchema(cmd.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// syncAccountSchema merges discovered into the accountSchema of the spec and
+// returns the updated spec along with the list of changes. Key order of the
+// spec is kept so the result diffs cleanly against the original file.
+func syncAccountSchema(specRaw []byte, discovered *connclient.AccountSchema, opts syncSchemaOptions) ([]byte, []schemaChange, error) {
+	var changes []schemaChange
+
+	spec, err := parseOrderedObject(specRaw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	schema := newOrderedObject()
+	if raw, ok := spec.get("accountSchema"); ok {
+		schema, err = parseOrderedObject(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("accountSchema: %v", err)
+		}
+	}
+
+	for _, field := range []struct {
+		key   string
+		value string
+	}{
+		{"displayAttribute", discovered.DisplayAttribute},
+		{"identityAttribute", discovered.IdentityAttribute},
+		{"groupAttribute", discovered.GroupAttribute},
+	} {
+		if field.value == "" {
+			continue
+		}
+		local := schema.getString(field.key)
+		if local == "" {
+			schema.setValue(field.key, field.value)
+			changes = append(changes, schemaChange{Op: "~", Message: fmt.Sprintf("%s set to %s", field.key, field.value)})
+		} else if local != field.value {
+			changes = append(changes, schemaChange{Op: "!", Message: fmt.Sprintf("%s kept as %s (discovered %s)", field.key, local, field.value), Note: true})
+		}
+	}
+
+	var localAttributes []*orderedObject
+	if raw, ok := schema.get("attributes"); ok {
+		var rawAttributes []json.RawMessage
+		if err := json.Unmarshal(raw, &rawAttributes); err != nil {
+			return nil, nil, fmt.Errorf("accountSchema.attributes: %v", err)
+		}
+		for _, rawAttribute := range rawAttributes {
+			attribute, err := parseOrderedObject(rawAttribute)
+			if err != nil {
+				return nil, nil, fmt.Errorf("accountSchema.attributes: %v", err)
+			}
+			localAttributes = append(localAttributes, attribute)
+		}
+	}
+
+	discoveredByName := map[string]connclient.AccountSchemaAttribute{}
+	for _, attribute := range discovered.Attributes {
+		discoveredByName[attribute.Name] = attribute
+	}
+
+	merged := []*orderedObject{}
+	var removed []string
+	localNames := map[string]bool{}
+	for _, local := range localAttributes {
+		name := local.getString("name")
+		localNames[name] = true
+
+		found, ok := discoveredByName[name]
+		if !ok {
+			if opts.Prune {
+				removed = append(removed, name)
+				changes = append(changes, schemaChange{Op: "-", Message: fmt.Sprintf("attribute %s", name)})
+				continue
+			}
+			changes = append(changes, schemaChange{Op: "!", Message: fmt.Sprintf("attribute %s was not discovered, kept (use --prune to remove)", name), Note: true})
+			merged = append(merged, local)
+			continue
+		}
+
+		if localType := local.getString("type"); found.Type != "" && localType != found.Type {
+			local.setValue("type", found.Type)
+			changes = append(changes, schemaChange{Op: "~", Message: fmt.Sprintf("attribute %s type %s -> %s", name, localType, found.Type)})
+		}
+		if local.getString("description") == "" && found.Description != "" {
+			local.setValue("description", found.Description)
+			changes = append(changes, schemaChange{Op: "~", Message: fmt.Sprintf("attribute %s description added", name)})
+		}
+
+		merged = append(merged, local)
+	}
+
+	for _, attribute := range discovered.Attributes {
+		if localNames[attribute.Name] {
+			continue
+		}
+		merged = append(merged, newSchemaAttribute(attribute))
+		changes = append(changes, schemaChange{Op: "+", Message: fmt.Sprintf("attribute %s (%s)", attribute.Name, attribute.Type)})
+	}
+
+	schema.setValue("attributes", merged)
+	spec.setValue("accountSchema", schema)
+
+	if opts.CreateTemplate {
+		templateChanges, err := syncAccountCreateTemplate(spec, merged, removed)
+		if err != nil {
+			return nil, nil, err
+		}
+		changes = append(changes, templateChanges...)
+	}
+
+	compact, err := marshalJSON(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var out bytes.Buffer
+	err = json.Indent(&out, compact, "", "\t")
+	if err != nil {
+		return nil, nil, err
+	}
+	if bytes.HasSuffix(specRaw, []byte("\n")) {
+		out.WriteByte('\n')
+	}
+
+	return out.Bytes(), changes, nil
+}
+
+// syncAccountCreateTemplate adds a field for every non-entitlement attribute
+// missing from accountCreateTemplate and drops the fields of removed
+// attributes. Existing fields are left as they are.
+func syncAccountCreateTemplate(spec *orderedObject, attributes []*orderedObject, removed []string) ([]schemaChange, error) {
+	var changes []schemaChange
+
+	template := newOrderedObject()
+	if raw, ok := spec.get("accountCreateTemplate"); ok {
+		var err error
+		template, err = parseOrderedObject(raw)
+		if err != nil {
+			return nil, fmt.Errorf("accountCreateTemplate: %v", err)
+		}
+	}
+
+	var fields []*orderedObject
+	if raw, ok := template.get("fields"); ok {
+		var rawFields []json.RawMessage
+		if err := json.Unmarshal(raw, &rawFields); err != nil {
+			return nil, fmt.Errorf("accountCreateTemplate.fields: %v", err)
+		}
+		for _, rawField := range rawFields {
+			field, err := parseOrderedObject(rawField)
+			if err != nil {
+				return nil, fmt.Errorf("accountCreateTemplate.fields: %v", err)
+			}
+			fields = append(fields, field)
+		}
+	}
+
+	isRemoved := map[string]bool{}
+	for _, name := range removed {
+		isRemoved[name] = true
+	}
+
+	existing := map[string]bool{}
+	kept := []*orderedObject{}
+	for _, field := range fields {
+		key := field.getString("key")
+		if key == "" {
+			key = field.getString("name")
+		}
+		if isRemoved[key] {
+			changes = append(changes, schemaChange{Op: "-", Message: fmt.Sprintf("accountCreateTemplate field %s", key)})
+			continue
+		}
+		existing[key] = true
+		kept = append(kept, field)
+	}
+
+	for _, attribute := range attributes {
+		name := attribute.getString("name")
+		if existing[name] || attribute.getBool("entitlement") {
+			continue
+		}
+
+		field := newOrderedObject()
+		field.setValue("key", name)
+		field.setValue("type", attribute.getString("type"))
+		field.setValue("required", false)
+		field.setValue("initialValue", map[string]interface{}{
+			"type": "identityAttribute",
+			"attributes": map[string]interface{}{
+				"name": name,
+			},
+		})
+		kept = append(kept, field)
+		changes = append(changes, schemaChange{Op: "+", Message: fmt.Sprintf("accountCreateTemplate field %s", name)})
+	}
+
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	template.setValue("fields", kept)
+	spec.setValue("accountCreateTemplate", template)
+
+	return changes, nil
+}
+
+func newSchemaAttribute(attribute connclient.AccountSchemaAttribute) *orderedObject {
+	obj := newOrderedObject()
+	obj.setValue("name", attribute.Name)
+	obj.setValue("type", attribute.Type)
+	obj.setValue("description", attribute.Description)
+	if attribute.Entitlement {
+		obj.setValue("entitlement", true)
+	}
+	if attribute.Managed {
+		obj.setValue("managed", true)
+	}
+	if attribute.Multi {
+		obj.setValue("multi", true)
+	}
+	return obj
+}
+
+// orderedObject is a JSON object that remembers the order of its keys
+type orderedObject struct {
+	keys   []string
+	values map[string]json.RawMessage
+}
+
+// marshalJSON is json.Marshal without HTML escaping, so "<", ">" and "&" in
+// the spec are written back as they were.
+func marshalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func newOrderedObject() *orderedObject {
+	return &orderedObject{values: map[string]json.RawMessage{}}
+}
+
+func parseOrderedObject(raw []byte) (*orderedObject, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+
+	tok, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+
+	obj := newOrderedObject()
+	for decoder.More() {
+		tok, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected an object key, got %v", tok)
+		}
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		obj.set(key, value)
+	}
+
+	return obj, nil
+}
+
+func (o *orderedObject) get(key string) (json.RawMessage, bool) {
+	value, ok := o.values[key]
+	return value, ok
+}
+
+func (o *orderedObject) set(key string, value json.RawMessage) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// setValue marshals value and stores it under key. Values are plain data or
+// ordered objects, which always marshal.
+func (o *orderedObject) setValue(key string, value interface{}) {
+	raw, _ := marshalJSON(value)
+	o.set(key, raw)
+}
+
+func (o *orderedObject) getString(key string) string {
+	var s string
+	if raw, ok := o.values[key]; ok {
+		_ = json.Unmarshal(raw, &s)
+	}
+	return s
+}
+
+func (o *orderedObject) getBool(key string) bool {
+	var b bool
+	if raw, ok := o.values[key]; ok {
+		_ = json.Unmarshal(raw, &b)
+	}
+	return b
+}
+
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		rawKey, err := marshalJSON(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(rawKey)
+		buf.WriteByte(':')
+		buf.Write(bytes.TrimSpace(o.values[key]))
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
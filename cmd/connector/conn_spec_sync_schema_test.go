@@ -0,0 +1,105 @@
+package connector
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/mocks"
+)
+
+const testSyncSpec = `{
+	"name": "test-connector",
+	"commands": [
+		"std:account:list"
+	],
+	"accountSchema": {
+		"displayAttribute": "firstName",
+		"identityAttribute": "email",
+		"attributes": [
+			{
+				"name": "firstName",
+				"type": "string",
+				"description": "Hand written <b>description</b> & more",
+				"managed": true
+			},
+			{
+				"name": "legacy",
+				"type": "string",
+				"description": "No longer returned"
+			}
+		]
+	}
+}
+`
+
+const testDiscoveredSchema = `{"type":"output","data":{"displayAttribute":"name","identityAttribute":"email","attributes":[` +
+	`{"name":"firstName","type":"string","description":"First name"},` +
+	`{"name":"email","type":"string","description":"Email"},` +
+	`{"name":"groups","type":"string","description":"Groups","entitlement":true,"multi":true}]}}`
+
+func TestSyncAccountSchema(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "connector-spec.json")
+	schemaPath := filepath.Join(dir, "schema.json")
+	if err := os.WriteFile(specPath, []byte(testSyncSpec), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(schemaPath, []byte(testDiscoveredSchema), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("Dry run leaves the spec untouched", func(t *testing.T) {
+		cmd := newConnSpecSyncSchemaCmd(mocks.NewMockClient(ctrl))
+		b := new(bytes.Buffer)
+		cmd.SetOut(b)
+		cmd.SetArgs([]string{"--spec", specPath, "--schema-file", schemaPath, "--dry-run"})
+
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("command failed with err: %s", err)
+		}
+
+		for _, expected := range []string{"+ attribute email (string)", "+ attribute groups (string)", "attribute legacy was not discovered", "displayAttribute kept as firstName"} {
+			if !strings.Contains(b.String(), expected) {
+				t.Errorf("expected output to contain %q, got:\n%s", expected, b.String())
+			}
+		}
+
+		raw, _ := os.ReadFile(specPath)
+		if string(raw) != testSyncSpec {
+			t.Errorf("expected spec to be unchanged, got:\n%s", raw)
+		}
+	})
+
+	t.Run("Merges, prunes and generates the create template", func(t *testing.T) {
+		cmd := newConnSpecSyncSchemaCmd(mocks.NewMockClient(ctrl))
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetArgs([]string{"--spec", specPath, "--schema-file", schemaPath, "--prune", "--create-template"})
+
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("command failed with err: %s", err)
+		}
+
+		raw, _ := os.ReadFile(specPath)
+		spec := string(raw)
+
+		if !strings.Contains(spec, `"description": "Hand written <b>description</b> & more",`+"\n\t\t\t\t"+`"managed": true`) {
+			t.Errorf("expected hand edited attribute to be preserved, got:\n%s", spec)
+		}
+		if strings.Contains(spec, "legacy") {
+			t.Errorf("expected legacy attribute to be pruned, got:\n%s", spec)
+		}
+		if strings.Index(spec, `"commands"`) > strings.Index(spec, `"accountSchema"`) {
+			t.Errorf("expected key order to be preserved, got:\n%s", spec)
+		}
+		if !strings.Contains(spec, `"key": "email"`) || strings.Contains(spec, `"key": "groups"`) {
+			t.Errorf("expected create template field for email only, got:\n%s", spec)
+		}
+	})
+}
@@ -107,6 +107,8 @@ func newConnValidateCmd(apiClient client.Client) *cobra.Command {
 	cmd.PersistentFlags().StringP("id", "c", "", "Connector ID or Alias")
 	cmd.MarkFlagRequired("id")
 
+	cmd.PersistentFlags().Bool("runtime", false, "Run checks through the direct runtime endpoint and log per-invocation timing")
+
 	return cmd
 }
 
@@ -19,6 +19,11 @@ func newConnInstancesCmd(client client.Client) *cobra.Command {
 
 	cmd.AddCommand(
 		newInstanceListCmd(client),
+		newInstanceGetCmd(client),
+		newInstanceCreateCmd(client),
+		newInstanceUpdateCmd(client),
+		newInstanceDeleteCmd(client),
+		newInstanceShowConfigCmd(client),
 	)
 
 	return cmd
@@ -0,0 +1,86 @@
+// Copyright (c) 2025, SailPoint Technologies, Inc. All rights reserved.
+package connector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/client"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+func newInstanceCreateCmd(client client.Client) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "create <instance-name>",
+		Short:   "Create connector instance",
+		Example: "sail conn instances create \"My Instance\" --connector 1234 --config-path config.json",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			connectorID := cmd.Flags().Lookup("connector").Value.String()
+			customizerID := cmd.Flags().Lookup("customizer").Value.String()
+			configPath := cmd.Flags().Lookup("config-path").Value.String()
+
+			create := instanceCreate{
+				Name:         args[0],
+				ConnectorID:  connectorID,
+				CustomizerId: customizerID,
+			}
+
+			if configPath != "" {
+				rawConfig, err := os.ReadFile(configPath)
+				if err != nil {
+					return err
+				}
+				err = json.Unmarshal(rawConfig, &create.Config)
+				if err != nil {
+					return fmt.Errorf("failed to parse config %s: %v", configPath, err)
+				}
+			}
+
+			raw, err := json.Marshal(create)
+			if err != nil {
+				return err
+			}
+
+			resp, err := client.Post(cmd.Context(), util.ResourceUrl(connectorInstancesEndpoint), "application/json", bytes.NewReader(raw), nil)
+			if err != nil {
+				return err
+			}
+			defer func(Body io.ReadCloser) {
+				_ = Body.Close()
+			}(resp.Body)
+
+			if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+				body, _ := io.ReadAll(resp.Body)
+				return fmt.Errorf("create connector instance failed. status: %s\nbody: %s", resp.Status, string(body))
+			}
+
+			var inst instance
+			err = json.NewDecoder(resp.Body).Decode(&inst)
+			if err != nil {
+				return err
+			}
+
+			table := tablewriter.NewWriter(cmd.OutOrStdout())
+			table.Header(toAny(instanceColumns)...)
+			table.Append(inst.columns())
+			table.Render()
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("connector", "", "Connector ID the instance runs")
+	_ = cmd.MarkFlagRequired("connector")
+
+	cmd.Flags().String("customizer", "", "Optional - Connector customizer ID to link the instance to")
+	cmd.Flags().StringP("config-path", "p", "", "Optional - Path to a JSON file with the instance config")
+
+	return cmd
+}
@@ -0,0 +1,71 @@
+package connector
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/mocks"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+)
+
+func TestNewInstanceCreateCmd_missingConnector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cmd := newInstanceCreateCmd(mocks.NewMockClient(ctrl))
+	cmd.SetArgs([]string{"My Instance"})
+	if err := cmd.Execute(); err == nil {
+		t.Error("expected error when --connector is missing")
+	}
+}
+
+func TestNewInstanceCreateCmd_success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClient := mocks.NewMockClient(ctrl)
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"baseUrl":"https://example.com"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedBody := `{"name":"My Instance","connectorId":"conn-1","connectorCustomizerId":"cust-1","config":{"baseUrl":"https://example.com"}}`
+	created := instance{ID: "inst-1", Name: "My Instance", ConnectorID: "conn-1", CustomizerId: "cust-1"}
+	rawOut, _ := json.Marshal(created)
+
+	mockClient.
+		EXPECT().
+		Post(gomock.Any(), gomock.Eq(util.ResourceUrl(connectorInstancesEndpoint)), gomock.Eq("application/json"), gomock.Any(), gomock.Nil()).
+		DoAndReturn(func(_ interface{}, _ string, _ string, body io.Reader, _ map[string]string) (*http.Response, error) {
+			raw, _ := io.ReadAll(body)
+			if string(raw) != expectedBody {
+				t.Errorf("expected body: %s, actual: %s", expectedBody, raw)
+			}
+			return &http.Response{
+				StatusCode: http.StatusCreated,
+				Status:     http.StatusText(http.StatusCreated),
+				Body:       io.NopCloser(bytes.NewReader(rawOut)),
+			}, nil
+		}).
+		Times(1)
+
+	cmd := newInstanceCreateCmd(mockClient)
+	var outBuf bytes.Buffer
+	cmd.SetOut(&outBuf)
+	cmd.SetArgs([]string{"My Instance", "--connector", "conn-1", "--customizer", "cust-1", "-p", configPath})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected Execute error: %v", err)
+	}
+
+	if !strings.Contains(outBuf.String(), "inst-1") {
+		t.Errorf("output missing created instance, got:\n%s", outBuf.String())
+	}
+}
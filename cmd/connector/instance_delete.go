@@ -0,0 +1,45 @@
+// Copyright (c) 2025, SailPoint Technologies, Inc. All rights reserved.
+package connector
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/sailpoint-oss/sailpoint-cli/internal/client"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+func newInstanceDeleteCmd(client client.Client) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "delete",
+		Short:   "Delete connector instance",
+		Example: "sail conn instances delete -i 1234",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := cmd.Flags().Lookup("id").Value.String()
+
+			resp, err := client.Delete(cmd.Context(), util.ResourceUrl(connectorInstancesEndpoint, id), nil, nil)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				_ = resp.Body.Close()
+			}()
+
+			if resp.StatusCode != http.StatusNoContent {
+				body, _ := io.ReadAll(resp.Body)
+				return fmt.Errorf("delete connector instance failed. status: %s\nbody: %s", resp.Status, string(body))
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "connector instance %s deleted.\n", id)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("id", "i", "", "Connector instance ID")
+	_ = cmd.MarkFlagRequired("id")
+
+	return cmd
+}
@@ -0,0 +1,42 @@
+package connector
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/mocks"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+)
+
+func TestNewInstanceDeleteCmd_success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mocks.NewMockClient(ctrl)
+	mockClient.
+		EXPECT().
+		Delete(gomock.Any(), gomock.Eq(util.ResourceUrl(connectorInstancesEndpoint, "inst-1")), gomock.Nil(), gomock.Nil()).
+		Return(&http.Response{
+			StatusCode: http.StatusNoContent,
+			Status:     http.StatusText(http.StatusNoContent),
+			Body:       io.NopCloser(bytes.NewReader(nil)),
+		}, nil).
+		Times(1)
+
+	cmd := newInstanceDeleteCmd(mockClient)
+	var outBuf bytes.Buffer
+	cmd.SetOut(&outBuf)
+	cmd.SetArgs([]string{"-i", "inst-1"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected Execute error: %v", err)
+	}
+
+	if !strings.Contains(outBuf.String(), "connector instance inst-1 deleted") {
+		t.Errorf("unexpected output: %s", outBuf.String())
+	}
+}
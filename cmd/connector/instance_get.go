@@ -0,0 +1,67 @@
+// Copyright (c) 2025, SailPoint Technologies, Inc. All rights reserved.
+package connector
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/client"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+func newInstanceGetCmd(client client.Client) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "get",
+		Short:   "Get connector instance",
+		Example: "sail conn instances get -i 1234",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := cmd.Flags().Lookup("id").Value.String()
+
+			inst, err := getInstance(cmd, client, id)
+			if err != nil {
+				return err
+			}
+
+			table := tablewriter.NewWriter(cmd.OutOrStdout())
+			table.Header(toAny(instanceColumns)...)
+			table.Append(inst.columns())
+			table.Render()
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("id", "i", "", "Connector instance ID")
+	_ = cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+// getInstance fetches a single connector instance by ID
+func getInstance(cmd *cobra.Command, client client.Client, id string) (*instance, error) {
+	resp, err := client.Get(cmd.Context(), util.ResourceUrl(connectorInstancesEndpoint, id), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("get connector instance failed. status: %s\nbody: %s", resp.Status, string(body))
+	}
+
+	var inst instance
+	err = json.NewDecoder(resp.Body).Decode(&inst)
+	if err != nil {
+		return nil, err
+	}
+
+	return &inst, nil
+}
@@ -0,0 +1,146 @@
+package connector
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/mocks"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+)
+
+func TestNewInstanceGetCmd_missingFlags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cmd := newInstanceGetCmd(mocks.NewMockClient(ctrl))
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err == nil {
+		t.Error("expected error when -i is missing")
+	}
+}
+
+func TestNewInstanceGetCmd_httpError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClient := mocks.NewMockClient(ctrl)
+
+	mockClient.
+		EXPECT().
+		Get(gomock.Any(), gomock.Eq(util.ResourceUrl(connectorInstancesEndpoint, "inst-1")), gomock.Nil()).
+		Return(&http.Response{
+			StatusCode: http.StatusNotFound,
+			Status:     http.StatusText(http.StatusNotFound),
+			Body:       io.NopCloser(strings.NewReader("missing")),
+		}, nil).
+		Times(1)
+
+	cmd := newInstanceGetCmd(mockClient)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"-i", "inst-1"})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "get connector instance failed") {
+		t.Fatalf("expected HTTP-error, got %v", err)
+	}
+}
+
+func TestNewInstanceShowConfigCmd_success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClient := mocks.NewMockClient(ctrl)
+
+	inst := instance{ID: "inst-1", Name: "Inst", Config: map[string]interface{}{"baseUrl": "https://example.com"}}
+	rawOut, _ := json.Marshal(inst)
+
+	mockClient.
+		EXPECT().
+		Get(gomock.Any(), gomock.Eq(util.ResourceUrl(connectorInstancesEndpoint, "inst-1")), gomock.Nil()).
+		Return(&http.Response{
+			StatusCode: http.StatusOK,
+			Status:     http.StatusText(http.StatusOK),
+			Body:       io.NopCloser(bytes.NewReader(rawOut)),
+		}, nil).
+		Times(1)
+
+	cmd := newInstanceShowConfigCmd(mockClient)
+	var outBuf bytes.Buffer
+	cmd.SetOut(&outBuf)
+	cmd.SetArgs([]string{"-i", "inst-1"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected Execute error: %v", err)
+	}
+
+	if !strings.Contains(outBuf.String(), `"baseUrl": "https://example.com"`) {
+		t.Errorf("output missing config, got:\n%s", outBuf.String())
+	}
+}
+
+func TestNewInstanceShowConfigCmd_masksSecrets(t *testing.T) {
+	inst := instance{ID: "inst-1", Name: "Inst", Config: map[string]interface{}{
+		"baseUrl":  "https://example.com",
+		"tokenUrl": "https://example.com/oauth/token",
+		"password": "hunter2",
+		"auth": map[string]interface{}{
+			"clientSecret": "s3cr3t",
+			"apiKeys":      []interface{}{"k1"},
+		},
+		"servers": []interface{}{
+			map[string]interface{}{"host": "ldap1", "bindPassword": "p4ss"},
+		},
+	}}
+	rawOut, _ := json.Marshal(inst)
+
+	for _, tt := range []struct {
+		args     []string
+		contains []string
+		excludes []string
+	}{
+		{
+			args:     []string{"-i", "inst-1"},
+			contains: []string{`"baseUrl": "https://example.com"`, `"tokenUrl": "https://example.com/oauth/token"`, `"password": "******"`, `"clientSecret": "******"`, `"apiKeys": "******"`, `"bindPassword": "******"`, `"host": "ldap1"`},
+			excludes: []string{"hunter2", "s3cr3t", "k1", "p4ss"},
+		},
+		{
+			args:     []string{"-i", "inst-1", "--show-secrets"},
+			contains: []string{`"password": "hunter2"`, `"clientSecret": "s3cr3t"`, `"bindPassword": "p4ss"`},
+		},
+	} {
+		ctrl := gomock.NewController(t)
+		mockClient := mocks.NewMockClient(ctrl)
+		mockClient.
+			EXPECT().
+			Get(gomock.Any(), gomock.Eq(util.ResourceUrl(connectorInstancesEndpoint, "inst-1")), gomock.Nil()).
+			Return(&http.Response{
+				StatusCode: http.StatusOK,
+				Status:     http.StatusText(http.StatusOK),
+				Body:       io.NopCloser(bytes.NewReader(rawOut)),
+			}, nil).
+			Times(1)
+
+		cmd := newInstanceShowConfigCmd(mockClient)
+		var outBuf bytes.Buffer
+		cmd.SetOut(&outBuf)
+		cmd.SetArgs(tt.args)
+
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("unexpected Execute error: %v", err)
+		}
+		for _, want := range tt.contains {
+			if !strings.Contains(outBuf.String(), want) {
+				t.Errorf("%v: output missing %s, got:\n%s", tt.args, want, outBuf.String())
+			}
+		}
+		for _, secret := range tt.excludes {
+			if strings.Contains(outBuf.String(), secret) {
+				t.Errorf("%v: output leaks %s, got:\n%s", tt.args, secret, outBuf.String())
+			}
+		}
+		ctrl.Finish()
+	}
+}
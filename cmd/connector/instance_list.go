@@ -17,7 +17,7 @@ func newInstanceListCmd(client client.Client) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "list",
 		Short:   "List all connector instances",
-		Example: "sail conn instances list",
+		Example: "sail conn instances list\nsail conn instances list --connector 1234",
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			resp, err := client.Get(cmd.Context(), util.ResourceUrl(connectorInstancesEndpoint), nil)
@@ -39,9 +39,18 @@ func newInstanceListCmd(client client.Client) *cobra.Command {
 				return err
 			}
 
+			connectorID := cmd.Flags().Lookup("connector").Value.String()
+			customizerID := cmd.Flags().Lookup("customizer").Value.String()
+
 			table := tablewriter.NewWriter(cmd.OutOrStdout())
 			table.Header(toAny(instanceColumns)...)
 			for _, c := range instances {
+				if connectorID != "" && c.ConnectorID != connectorID {
+					continue
+				}
+				if customizerID != "" && c.CustomizerId != customizerID {
+					continue
+				}
 				table.Append(c.columns())
 			}
 			table.Render()
@@ -50,5 +59,8 @@ func newInstanceListCmd(client client.Client) *cobra.Command {
 		},
 	}
 
+	cmd.Flags().String("connector", "", "Only list instances of this connector ID")
+	cmd.Flags().String("customizer", "", "Only list instances linked to this connector customizer ID")
+
 	return cmd
 }
@@ -0,0 +1,70 @@
+package connector
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/mocks"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+)
+
+func TestNewInstanceListCmd_filters(t *testing.T) {
+	instances := []instance{
+		{ID: "inst-1", Name: "First", ConnectorID: "conn-1", CustomizerId: "cust-1"},
+		{ID: "inst-2", Name: "Second", ConnectorID: "conn-1"},
+		{ID: "inst-3", Name: "Third", ConnectorID: "conn-2", CustomizerId: "cust-1"},
+	}
+	rawOut, _ := json.Marshal(instances)
+
+	tests := []struct {
+		args     []string
+		expected []string
+		excluded []string
+	}{
+		{args: []string{}, expected: []string{"inst-1", "inst-2", "inst-3"}},
+		{args: []string{"--connector", "conn-1"}, expected: []string{"inst-1", "inst-2"}, excluded: []string{"inst-3"}},
+		{args: []string{"--customizer", "cust-1"}, expected: []string{"inst-1", "inst-3"}, excluded: []string{"inst-2"}},
+		{args: []string{"--connector", "conn-2", "--customizer", "cust-1"}, expected: []string{"inst-3"}, excluded: []string{"inst-1", "inst-2"}},
+	}
+
+	for _, tt := range tests {
+		ctrl := gomock.NewController(t)
+		mockClient := mocks.NewMockClient(ctrl)
+		mockClient.
+			EXPECT().
+			Get(gomock.Any(), gomock.Eq(util.ResourceUrl(connectorInstancesEndpoint)), gomock.Nil()).
+			Return(&http.Response{
+				StatusCode: http.StatusOK,
+				Status:     http.StatusText(http.StatusOK),
+				Body:       io.NopCloser(bytes.NewReader(rawOut)),
+			}, nil).
+			Times(1)
+
+		cmd := newInstanceListCmd(mockClient)
+		var outBuf bytes.Buffer
+		cmd.SetOut(&outBuf)
+		cmd.SetArgs(tt.args)
+
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("unexpected Execute error: %v", err)
+		}
+
+		out := outBuf.String()
+		for _, id := range tt.expected {
+			if !strings.Contains(out, id) {
+				t.Errorf("args %v: output missing %s, got:\n%s", tt.args, id, out)
+			}
+		}
+		for _, id := range tt.excluded {
+			if strings.Contains(out, id) {
+				t.Errorf("args %v: output should not contain %s, got:\n%s", tt.args, id, out)
+			}
+		}
+		ctrl.Finish()
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2025, SailPoint Technologies, Inc. All rights reserved.
+package connector
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	connclient "github.com/sailpoint-oss/sailpoint-cli/cmd/connector/client"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/client"
+	"github.com/spf13/cobra"
+)
+
+// secretKeyPattern matches the config keys whose values are masked unless
+// --show-secrets is set. Keys naming a URL, like tokenUrl, are left alone.
+var secretKeyPattern = regexp.MustCompile(`(?i)password|passwd|secret|apikey|api_key|token|privatekey|private_key|credential`)
+
+func isSecretKey(key string) bool {
+	return secretKeyPattern.MatchString(key) && !strings.HasSuffix(strings.ToLower(key), "url")
+}
+
+func newInstanceShowConfigCmd(client client.Client) *cobra.Command {
+	var showSecrets bool
+	cmd := &cobra.Command{
+		Use:     "show-config",
+		Short:   "Show the config of a connector instance",
+		Example: "sail conn instances show-config -i 1234",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := cmd.Flags().Lookup("id").Value.String()
+
+			inst, err := getInstance(cmd, client, id)
+			if err != nil {
+				return err
+			}
+
+			var config interface{} = inst.Config
+			if inst.Config == nil {
+				config = map[string]interface{}{}
+			} else if !showSecrets {
+				config = maskConfigSecrets(inst.Config)
+			}
+
+			raw, err := json.MarshalIndent(config, "", "  ")
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), string(raw))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("id", "i", "", "Connector instance ID")
+	cmd.Flags().BoolVar(&showSecrets, "show-secrets", false, "Print passwords, tokens and other secret values instead of masking them")
+	_ = cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+// maskConfigSecrets returns a copy of value with the values of secret looking
+// keys replaced, in nested maps and lists too
+func maskConfigSecrets(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if isSecretKey(key) && item != nil && item != "" {
+				masked[key] = connclient.MaskedPassword
+			} else {
+				masked[key] = maskConfigSecrets(item)
+			}
+		}
+		return masked
+	case []interface{}:
+		masked := make([]interface{}, len(v))
+		for i, item := range v {
+			masked[i] = maskConfigSecrets(item)
+		}
+		return masked
+	default:
+		return v
+	}
+}
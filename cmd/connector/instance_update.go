@@ -0,0 +1,92 @@
+// Copyright (c) 2025, SailPoint Technologies, Inc. All rights reserved.
+package connector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/client"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+func newInstanceUpdateCmd(client client.Client) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update connector instance with a JSON patch",
+		Example: "sail conn instances update -i 1234 -f patch.json\n" +
+			"sail conn instances update -i 1234 --patch '[{\"op\":\"replace\",\"path\":\"/name\",\"value\":\"New Name\"}]'",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := cmd.Flags().Lookup("id").Value.String()
+			file := cmd.Flags().Lookup("file").Value.String()
+			patch := cmd.Flags().Lookup("patch").Value.String()
+
+			var raw []byte
+			switch {
+			case file != "" && patch != "":
+				return fmt.Errorf("only one of file or patch can be set")
+			case file != "":
+				var err error
+				raw, err = os.ReadFile(file)
+				if err != nil {
+					return err
+				}
+			case patch != "":
+				raw = []byte(patch)
+			default:
+				return fmt.Errorf("either file or patch must be set")
+			}
+
+			var ops []map[string]interface{}
+			err := json.Unmarshal(raw, &ops)
+			if err != nil {
+				return fmt.Errorf("patch must be a JSON array of operations: %v", err)
+			}
+
+			raw, err = json.Marshal(ops)
+			if err != nil {
+				return err
+			}
+
+			resp, err := client.Patch(cmd.Context(), util.ResourceUrl(connectorInstancesEndpoint, id), bytes.NewReader(raw), nil)
+			if err != nil {
+				return err
+			}
+			defer func(Body io.ReadCloser) {
+				_ = Body.Close()
+			}(resp.Body)
+
+			if resp.StatusCode != http.StatusOK {
+				body, _ := io.ReadAll(resp.Body)
+				return fmt.Errorf("update connector instance failed. status: %s\nbody: %s", resp.Status, string(body))
+			}
+
+			var inst instance
+			err = json.NewDecoder(resp.Body).Decode(&inst)
+			if err != nil {
+				return err
+			}
+
+			table := tablewriter.NewWriter(cmd.OutOrStdout())
+			table.Header(toAny(instanceColumns)...)
+			table.Append(inst.columns())
+			table.Render()
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("id", "i", "", "Connector instance ID")
+	_ = cmd.MarkFlagRequired("id")
+
+	cmd.Flags().StringP("file", "f", "", "Path to a JSON patch file")
+	cmd.Flags().String("patch", "", "JSON patch operations")
+
+	return cmd
+}
@@ -0,0 +1,66 @@
+package connector
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/mocks"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+)
+
+func TestNewInstanceUpdateCmd_invalidPatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClient := mocks.NewMockClient(ctrl)
+	mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	for _, args := range [][]string{
+		{"-i", "inst-1"},
+		{"-i", "inst-1", "--patch", `{"op":"replace"}`},
+		{"-i", "inst-1", "--patch", "[]", "-f", "patch.json"},
+	} {
+		cmd := newInstanceUpdateCmd(mockClient)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err == nil {
+			t.Errorf("expected error for args %v", args)
+		}
+	}
+}
+
+func TestNewInstanceUpdateCmd_success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClient := mocks.NewMockClient(ctrl)
+
+	updated := instance{ID: "inst-1", Name: "Renamed"}
+	rawOut, _ := json.Marshal(updated)
+
+	mockClient.
+		EXPECT().
+		Patch(gomock.Any(), gomock.Eq(util.ResourceUrl(connectorInstancesEndpoint, "inst-1")), gomock.Any(), gomock.Nil()).
+		Return(&http.Response{
+			StatusCode: http.StatusOK,
+			Status:     http.StatusText(http.StatusOK),
+			Body:       io.NopCloser(bytes.NewReader(rawOut)),
+		}, nil).
+		Times(1)
+
+	cmd := newInstanceUpdateCmd(mockClient)
+	var outBuf bytes.Buffer
+	cmd.SetOut(&outBuf)
+	cmd.SetArgs([]string{"-i", "inst-1", "--patch", `[{"op":"replace","path":"/name","value":"Renamed"}]`})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected Execute error: %v", err)
+	}
+
+	if !strings.Contains(outBuf.String(), "Renamed") {
+		t.Errorf("output missing updated instance, got:\n%s", outBuf.String())
+	}
+}
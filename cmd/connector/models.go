@@ -68,16 +68,26 @@ type TagUpdate struct {
 }
 
 type instance struct {
-	ID           string `json:"id"`
-	Name         string `json:"name"`
-	CustomizerId string `json:"connectorCustomizerId"`
+	ID                string                 `json:"id"`
+	Name              string                 `json:"name"`
+	ConnectorID       string                 `json:"connectorId,omitempty"`
+	CustomizerId      string                 `json:"connectorCustomizerId"`
+	CustomizerVersion *int                   `json:"connectorCustomizerVersion,omitempty"`
+	Config            map[string]interface{} `json:"config,omitempty"`
 }
 
 func (c instance) columns() []string {
-	return []string{c.ID, c.Name, c.CustomizerId}
+	return []string{c.ID, c.Name, c.ConnectorID, c.CustomizerId}
 }
 
-var instanceColumns = []string{"ID", "Name", "Customizer ID"}
+var instanceColumns = []string{"ID", "Name", "Connector ID", "Customizer ID"}
+
+type instanceCreate struct {
+	Name         string                 `json:"name"`
+	ConnectorID  string                 `json:"connectorId"`
+	CustomizerId string                 `json:"connectorCustomizerId,omitempty"`
+	Config       map[string]interface{} `json:"config,omitempty"`
+}
 
 type customizer struct {
 	ID           string `json:"id"`
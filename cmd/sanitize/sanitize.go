@@ -4,7 +4,6 @@ package sanitize
 import (
 	_ "embed"
 	"os"
-	"regexp"
 
 	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
 	"github.com/spf13/cobra"
@@ -30,10 +29,7 @@ func NewSanitizeCommand() *cobra.Command {
 					return err
 				}
 
-				tokens := regexp.MustCompile(`(ey[A-Za-z0-9-_=]+).(ey[A-Za-z0-9-_=]+).[A-Za-z0-9-_.+=]+`)
-				origins := regexp.MustCompile(`\{[\s]+"name": "origin",[\s]+"value":[\s]+"[A-Za-z0-9:\/\-.]+"[\s]+\},`)
-
-				redactedFile := origins.ReplaceAllString(tokens.ReplaceAllString(string(file), "REDACTED"), `{"name":"origin", "value":"REDACTED"},`)
+				redactedFile := util.RedactTokens(string(file))
 
 				// Write the sanitized file back to disk
 				err = os.WriteFile(filePath, []byte(redactedFile), 0644)
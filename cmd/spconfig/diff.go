@@ -0,0 +1,155 @@
+package spconfig
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/fatih/color"
+	beta "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/config"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/sdk"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/spconfig"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+//go:embed diff.md
+var diffHelp string
+
+// exportEnvironment runs an export job in a configured environment and waits
+// for its results
+func exportEnvironment(env string, includeTypes []string, excludeTypes []string, wait spconfig.WaitOptions) (beta.SpConfigExportResults, error) {
+	apiClient, err := config.InitEnvAPIClient(env)
+	if err != nil {
+		return beta.SpConfigExportResults{}, err
+	}
+
+	description := "sail spconfig diff"
+	job, resp, err := apiClient.Beta.SPConfigAPI.ExportSpConfig(context.TODO()).ExportPayload(beta.ExportPayload{Description: &description, IncludeTypes: includeTypes, ExcludeTypes: excludeTypes}).Execute()
+	if err != nil {
+		return beta.SpConfigExportResults{}, sdk.HandleSDKError(resp, err)
+	}
+
+	log.Info("Waiting for export task to complete", "env", env, "JobID", job.JobId)
+	export, err := spconfig.WaitForExport(*apiClient, job.JobId, wait)
+	if err != nil {
+		return beta.SpConfigExportResults{}, err
+	}
+
+	return *export, nil
+}
+
+// formatDiffValue renders a field value as compact JSON
+func formatDiffValue(value interface{}) string {
+	if value == nil {
+		return "(none)"
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(raw)
+}
+
+func writeExportDiff(w io.Writer, diff spconfig.ExportDiff) {
+	_, _ = fmt.Fprintf(w, "Comparing %s with %s\n\n", diff.Source, diff.Target)
+
+	for _, object := range diff.Objects {
+		switch object.Status {
+		case spconfig.DiffAdded:
+			_, _ = fmt.Fprintln(w, color.GreenString("+ %s %s", object.Type, object.Name))
+		case spconfig.DiffRemoved:
+			_, _ = fmt.Fprintln(w, color.RedString("- %s %s", object.Type, object.Name))
+		case spconfig.DiffChanged:
+			_, _ = fmt.Fprintln(w, color.YellowString("~ %s %s", object.Type, object.Name))
+			for _, change := range object.Changes {
+				_, _ = fmt.Fprintf(w, "    %s: %s -> %s\n", change.Path, color.RedString(formatDiffValue(change.Old)), color.GreenString(formatDiffValue(change.New)))
+			}
+		}
+	}
+
+	if len(diff.Objects) > 0 {
+		_, _ = fmt.Fprintln(w)
+	}
+	_, _ = fmt.Fprintf(w, "%d added, %d removed, %d changed, %d unchanged\n", diff.Added, diff.Removed, diff.Changed, diff.Unchanged)
+}
+
+func newDiffCommand() *cobra.Command {
+	help := util.ParseHelp(diffHelp)
+	var envs []string
+	var includeTypes []string
+	var excludeTypes []string
+	var format string
+	var timeout time.Duration
+	cmd := &cobra.Command{
+		Use:     "diff [source] [target]",
+		Short:   "Compare two SPConfig exports from files, folders or tenants",
+		Long:    help.Long,
+		Example: help.Example,
+		Args:    cobra.MaximumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "text" && format != "json" {
+				return fmt.Errorf("unsupported format %q, use text or json", format)
+			}
+			if len(args)+len(envs) != 2 {
+				return fmt.Errorf("provide exactly two exports to compare, as paths or with --env")
+			}
+
+			var exports []beta.SpConfigExportResults
+			var labels []string
+
+			for _, path := range args {
+				export, err := spconfig.LoadExport(path)
+				if err != nil {
+					return err
+				}
+				exports = append(exports, export)
+				labels = append(labels, path)
+			}
+
+			for _, env := range envs {
+				export, err := exportEnvironment(env, includeTypes, excludeTypes, spconfig.WaitOptions{Timeout: timeout})
+				if err != nil {
+					return err
+				}
+				exports = append(exports, export)
+				labels = append(labels, "env "+env)
+			}
+
+			source := spconfig.FilterExportTypes(exports[0], includeTypes, excludeTypes)
+			target := spconfig.FilterExportTypes(exports[1], includeTypes, excludeTypes)
+
+			diff, err := spconfig.DiffExports(source, target)
+			if err != nil {
+				return err
+			}
+			diff.Source, diff.Target = labels[0], labels[1]
+
+			if format == "json" {
+				raw, err := json.MarshalIndent(diff, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(raw))
+				return nil
+			}
+
+			writeExportDiff(cmd.OutOrStdout(), diff)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&envs, "env", []string{}, "Configured environment to export and compare, can be given twice")
+	cmd.Flags().StringArrayVarP(&includeTypes, "include", "i", []string{}, "Types to compare, and to export from environments")
+	cmd.Flags().StringArrayVarP(&excludeTypes, "exclude", "e", []string{}, "Types to leave out of the comparison, and of environment exports")
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "Output format, text or json")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Stop waiting for environment exports after this long, for example 10m, leaving them running. Waits until they end by default")
+
+	return cmd
+}
@@ -0,0 +1,47 @@
+package spconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sailpoint-oss/sailpoint-cli/internal/spconfig"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+)
+
+func TestDiffCommandJSON(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source.json")
+	target := filepath.Join(dir, "target.json")
+	if err := os.WriteFile(source, []byte(`{"objects":[{"self":{"type":"ROLE","id":"r1","name":"Auditor"},"object":{"id":"r1","description":"a"}}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte(`{"objects":[{"self":{"type":"ROLE","id":"r9","name":"Auditor"},"object":{"id":"r9","description":"b"}}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newDiffCommand()
+	stderr := new(bytes.Buffer)
+	cmd.SetErr(stderr)
+	cmd.SetArgs([]string{source, target, "-f", "json"})
+
+	// The result goes to the real stdout, cobra's default output, so it can be
+	// piped
+	stdout, err := util.CaptureStdout(cmd.Execute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var diff spconfig.ExportDiff
+	if err := json.Unmarshal([]byte(stdout), &diff); err != nil {
+		t.Fatalf("expected JSON on stdout, got %q (stderr %q): %v", stdout, stderr.String(), err)
+	}
+	if diff.Changed != 1 {
+		t.Errorf("unexpected diff: %+v", diff)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("expected nothing on stderr, got %q", stderr.String())
+	}
+}
@@ -3,6 +3,7 @@ package spconfig
 
 import (
 	_ "embed"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/config"
@@ -19,6 +20,7 @@ func newDownloadCommand() *cobra.Command {
 	var importIDs []string
 	var exportIDs []string
 	var folderPath string
+	var timeout time.Duration
 	cmd := &cobra.Command{
 		Use:     "download {--import <importID> --export <exportID>}",
 		Short:   "Download the results of import or export jobs from Identity Security Cloud",
@@ -35,7 +37,7 @@ func newDownloadCommand() *cobra.Command {
 
 			for _, jobId := range importIDs {
 				log.Info("Checking Import Job", "JobID", jobId)
-				err := spconfig.DownloadImport(*apiClient, jobId, "spconfig-import-"+jobId, folderPath)
+				err := spconfig.DownloadImport(*apiClient, jobId, "spconfig-import-"+jobId, folderPath, spconfig.WaitOptions{Timeout: timeout})
 				if err != nil {
 					return err
 				}
@@ -43,7 +45,7 @@ func newDownloadCommand() *cobra.Command {
 
 			for _, jobId := range exportIDs {
 				log.Info("Checking Export Job", "JobID", jobId)
-				err := spconfig.DownloadExport(*apiClient, jobId, "spconfig-export-"+jobId, folderPath)
+				err := spconfig.DownloadExport(*apiClient, jobId, "spconfig-export-"+jobId, folderPath, spconfig.WaitOptions{Timeout: timeout})
 				if err != nil {
 					return err
 				}
@@ -56,6 +58,7 @@ func newDownloadCommand() *cobra.Command {
 	cmd.Flags().StringArrayVarP(&importIDs, "import", "", []string{}, "Specify the IDs of the import jobs to download results for")
 	cmd.Flags().StringArrayVarP(&exportIDs, "export", "", []string{}, "Specify the IDs of the export jobs to download results for")
 	cmd.Flags().StringVarP(&folderPath, "folderPath", "f", "spconfig-exports", "Folder path to save the search results in. If the directory doesn't exist, then it will be automatically created. (default is the current working directory)")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Stop waiting for the job after this long, for example 10m, leaving it running. Waits until the job ends by default")
 
 	return cmd
 }
@@ -0,0 +1,122 @@
+package spconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/fatih/color"
+	sailpoint "github.com/sailpoint-oss/golang-sdk/v2"
+	beta "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/output"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/sdk"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/spconfig"
+)
+
+// dryRunReport is the saved outcome of an import dry run
+type dryRunReport struct {
+	Preview  *beta.SpConfigImportResults `json:"preview,omitempty"`
+	Analysis spconfig.ImportAnalysis     `json:"analysis"`
+}
+
+// dryRunImport previews an import on the platform, then analyzes the payload
+// locally against the objects of the tenant, and saves both in folderPath
+func dryRunImport(w io.Writer, apiClient *sailpoint.APIClient, file *os.File, payload beta.SpConfigExportResults, options *beta.ImportOptions, folderPath string, wait spconfig.WaitOptions) error {
+	var report dryRunReport
+
+	request := apiClient.Beta.SPConfigAPI.ImportSpConfig(context.TODO()).Data(file).Preview(true)
+	if options != nil {
+		request = request.Options(*options)
+	}
+	job, resp, err := request.Execute()
+	if err != nil {
+		log.Warn("Import preview is not available, only the local analysis is run", "err", sdk.HandleSDKError(resp, err))
+	} else {
+		log.Info("Waiting for import preview to complete", "JobID", job.JobId)
+		results, err := spconfig.WaitForImport(*apiClient, job.JobId, wait)
+		var stopped *spconfig.JobStoppedError
+		if errors.As(err, &stopped) {
+			return err
+		} else if err != nil {
+			log.Warn("Import preview failed, only the local analysis is run", "err", err)
+		} else {
+			report.Preview = results
+			_, _ = fmt.Fprintln(w, color.New(color.Bold).Sprint("Platform preview"))
+			spconfig.PrintImportResults(w, *results)
+			_, _ = fmt.Fprintln(w)
+		}
+	}
+
+	var warnings []string
+	if options != nil {
+		payload = spconfig.FilterExportTypes(payload, options.IncludeTypes, options.ExcludeTypes)
+		if options.ObjectOptions != nil {
+			payload, warnings = spconfig.FilterObjectOptions(payload, *options.ObjectOptions)
+		}
+	}
+
+	types := spconfig.ReferencedTypes(payload)
+	description := "sail spconfig import --dry-run"
+	exportJob, resp, err := apiClient.Beta.SPConfigAPI.ExportSpConfig(context.TODO()).ExportPayload(beta.ExportPayload{Description: &description, IncludeTypes: types}).Execute()
+	if err != nil {
+		return sdk.HandleSDKError(resp, err)
+	}
+
+	log.Info("Exporting the tenant objects for the local analysis", "types", strings.Join(types, ","), "JobID", exportJob.JobId)
+	tenant, err := spconfig.WaitForExport(*apiClient, exportJob.JobId, wait)
+	if err != nil {
+		return err
+	}
+
+	report.Analysis, err = spconfig.AnalyzeImport(payload, *tenant, types, newTenantResolver(apiClient))
+	if err != nil {
+		return err
+	}
+	report.Analysis.Warnings = append(report.Analysis.Warnings, warnings...)
+
+	writeImportAnalysis(w, report.Analysis)
+
+	fileName := "spconfig-dry-run-" + time.Now().Format("20060102-150405")
+	log.Info("Saving dry run report", "filePath", folderPath+"/"+fileName+".json")
+	return output.SaveJSONFile(report, fileName, folderPath)
+}
+
+func writeImportAnalysis(w io.Writer, analysis spconfig.ImportAnalysis) {
+	actions, statuses := analysis.Counts()
+
+	_, _ = fmt.Fprintln(w, color.New(color.Bold).Sprint("Local analysis"))
+	_, _ = fmt.Fprintf(w, "Objects: %d to create, %d to overwrite, %d unchanged\n", actions[spconfig.ActionCreate], actions[spconfig.ActionOverwrite], actions[spconfig.ActionUnchanged])
+
+	var entries [][]string
+	for _, object := range analysis.Objects {
+		changes := ""
+		if object.Action == spconfig.ActionOverwrite {
+			changes = fmt.Sprint(object.Changes)
+		}
+		entries = append(entries, []string{object.Type, object.Name, object.Action, changes})
+	}
+	output.WriteTable(w, []string{"Type", "Name", "Action", "Changed Fields"}, entries, "")
+
+	_, _ = fmt.Fprintf(w, "\nReferences: %d in payload, %d matched, %d need remapping, %d unresolved, %d unchecked\n",
+		statuses[spconfig.ReferenceInPayload], statuses[spconfig.ReferenceMatched], statuses[spconfig.ReferenceRemap], statuses[spconfig.ReferenceUnresolved], statuses[spconfig.ReferenceUnchecked])
+
+	entries = nil
+	for _, reference := range analysis.References {
+		if reference.Status != spconfig.ReferenceRemap && reference.Status != spconfig.ReferenceUnresolved {
+			continue
+		}
+		entries = append(entries, []string{reference.FromType + " " + reference.FromName, reference.Path, reference.Type + " " + reference.Name + " (" + reference.Id + ")", reference.Status, reference.TenantId, reference.Detail})
+	}
+	if len(entries) > 0 {
+		output.WriteTable(w, []string{"Object", "Path", "Reference", "Status", "Tenant ID", "Details"}, entries, "")
+	}
+
+	for _, warning := range analysis.Warnings {
+		_, _ = fmt.Fprintln(w, color.YellowString("Warning: %s", warning))
+	}
+}
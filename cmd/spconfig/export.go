@@ -5,6 +5,7 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
+	"time"
 
 	"github.com/charmbracelet/log"
 
@@ -27,6 +28,8 @@ func newExportCommand() *cobra.Command {
 	var includeTypes []string
 	var excludeTypes []string
 	var wait bool
+	var split bool
+	var timeout time.Duration
 
 	cmd := &cobra.Command{
 		Use:     "export",
@@ -58,9 +61,21 @@ func newExportCommand() *cobra.Command {
 
 			spconfig.PrintJob(*job)
 
-			if wait {
+			if split {
 				log.Warn("Waiting for export task to complete")
-				downloadErr := spconfig.DownloadExport(*apiClient, job.JobId, "spconfig-export-"+job.JobId, folderPath)
+				exportData, err := spconfig.WaitForExport(*apiClient, job.JobId, spconfig.WaitOptions{Timeout: timeout})
+				if err != nil {
+					return err
+				}
+
+				count, err := spconfig.WriteSplitExport(*exportData, folderPath)
+				if err != nil {
+					return err
+				}
+				log.Info("Saved split export", "objects", count, "folderPath", folderPath)
+			} else if wait {
+				log.Warn("Waiting for export task to complete")
+				downloadErr := spconfig.DownloadExport(*apiClient, job.JobId, "spconfig-export-"+job.JobId, folderPath, spconfig.WaitOptions{Timeout: timeout})
 				if downloadErr != nil {
 					return downloadErr
 				}
@@ -76,6 +91,8 @@ func newExportCommand() *cobra.Command {
 	cmd.Flags().StringArrayVarP(&excludeTypes, "exclude", "e", []string{}, "Types to exclude in export job")
 	cmd.Flags().StringVarP(&objectOptions, "objectOptions", "o", "", "Options for the object types being exported")
 	cmd.Flags().BoolVarP(&wait, "wait", "w", false, "Wait for the export job to finish, and then download the results")
+	cmd.Flags().BoolVar(&split, "split", false, "Wait for the export job to finish, and then save one normalized file per object under <folderPath>/<type>/<name>.json")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Stop waiting for the job after this long, for example 10m, leaving it running. Waits until the job ends by default")
 
 	return cmd
 }
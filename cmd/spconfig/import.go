@@ -3,10 +3,15 @@ package spconfig
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"os"
+	"time"
 
 	"github.com/charmbracelet/log"
+	beta "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/config"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/output"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/spconfig"
 	"github.com/spf13/cobra"
 )
@@ -14,31 +19,122 @@ import (
 func newImportCommand() *cobra.Command {
 	var filePath string
 	var folderPath string
+	var fromDir string
 	var wait bool
+	var dryRun bool
+	var includeTypes []string
+	var excludeTypes []string
+	var objectOptions string
+	var mappingPath string
+	var remap bool
+	var discover bool
+	var allowUnresolved bool
+	var timeout time.Duration
 
 	cmd := &cobra.Command{
 		Use:     "import",
 		Short:   "Start an import job in Identity Security Cloud",
-		Long:    "\nStart an import job in Identity Security Cloud\n\n",
-		Example: "sail spconfig import",
+		Long:    "\nStart an import job in Identity Security Cloud\n\nThe payload is an export file, or the folder of a split export written by sail spconfig export --split.\n\nWith --dry-run nothing is imported. The import is previewed on the platform, and the payload is compared with the objects of the tenant to list the objects that would be created or overwritten, and the references to sources, identity profiles or other objects that are missing from the tenant or exist there under another ID and need remapping. References to types that are not exported, like identity owners, are looked up in the tenant by name. The report is saved in the folder path.\n\nExports hold the IDs of the source tenant. With --mapping, references to objects that are not part of the payload are rewritten to objects of the target tenant before upload. The mapping file is keyed by reference type, then by the name or ID of the object in the source tenant, and gives the target ID, or a name or an email to look the target up with:\n\n  SOURCE:\n    Active Directory: 2c9180835d2e5168015d32f890ca1581\n  IDENTITY:\n    jane.doe:\n      email: jane.doe@acme.com\n\nReferences missing from the mapping are looked up by name in the target tenant, unless --discover=false. --remap does the lookups without a mapping file. A mapping report is saved in the folder path, along with the mapping that was applied, which can be reused as a mapping file. The import stops when references are ambiguous, not found or could not be looked up, or are mapped by name or email with --discover=false, unless --allow-unresolved is set.\n\n",
+		Example: "sail spconfig import -f spconfig-exports/spconfig-export-<jobId>.json --wait\nsail spconfig import --from-dir config/tenant --wait\nsail spconfig import --from-dir config/tenant --dry-run\nsail spconfig import -f spconfig-exports/spconfig-export-<jobId>.json --mapping prod-mapping.yaml --dry-run",
 		Aliases: []string{"imp"},
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 
+			if filePath == "" && fromDir == "" {
+				return fmt.Errorf("provide the import payload with --filePath or --from-dir")
+			}
+
+			var options *beta.ImportOptions
+			if len(includeTypes) > 0 || len(excludeTypes) > 0 || objectOptions != "" {
+				options = &beta.ImportOptions{IncludeTypes: includeTypes, ExcludeTypes: excludeTypes}
+				if objectOptions != "" {
+					err := json.Unmarshal([]byte(objectOptions), &options.ObjectOptions)
+					if err != nil {
+						return err
+					}
+				}
+			}
+
 			apiClient, err := config.InitAPIClient(false)
 			if err != nil {
 				return err
 			}
 
-			file, err := os.Open(filePath)
-			if err != nil {
-				return err
+			remap = remap || mappingPath != ""
+
+			var payload beta.SpConfigExportResults
+			if fromDir != "" {
+				payload, err = spconfig.ReadSplitExport(fromDir)
+				if err != nil {
+					return err
+				}
+				log.Info("Reassembled split export", "objects", len(payload.Objects), "folderPath", fromDir)
+			} else if dryRun || remap {
+				payload, err = spconfig.LoadExport(filePath)
+				if err != nil {
+					return err
+				}
+			}
+
+			if remap {
+				mapping := spconfig.ReferenceMapping{}
+				if mappingPath != "" {
+					mapping, err = spconfig.LoadReferenceMapping(mappingPath)
+					if err != nil {
+						return err
+					}
+				}
+
+				var resolve spconfig.ReferenceResolver
+				if discover {
+					resolve = newTenantResolver(apiClient)
+				}
+
+				var report spconfig.RemapReport
+				payload, report = spconfig.RemapReferences(payload, mapping, resolve)
+				writeRemapReport(cmd.OutOrStdout(), report)
+
+				timestamp := time.Now().Format("20060102-150405")
+				log.Info("Saving mapping report", "filePath", folderPath+"/spconfig-remap-"+timestamp+".json")
+				if err := output.SaveJSONFile(report, "spconfig-remap-"+timestamp, folderPath); err != nil {
+					return err
+				}
+				log.Info("Saving mapping", "filePath", folderPath+"/spconfig-mapping-"+timestamp+".json")
+				if err := output.SaveJSONFile(report.Mapping, "spconfig-mapping-"+timestamp, folderPath); err != nil {
+					return err
+				}
+
+				if unresolved := report.Unresolved(); len(unresolved) > 0 && !dryRun && !allowUnresolved {
+					return fmt.Errorf("%d references could not be remapped and would keep the IDs of the source tenant, add them to the mapping file or use --allow-unresolved", len(unresolved))
+				}
+			}
+
+			var file *os.File
+			if fromDir != "" || remap {
+				file, err = spconfig.WriteImportPayload(payload)
+				if err != nil {
+					return err
+				}
+				defer os.Remove(file.Name())
+			} else {
+				file, err = os.Open(filePath)
+				if err != nil {
+					return err
+				}
 			}
 			defer file.Close()
 
+			if dryRun {
+				return dryRunImport(cmd.OutOrStdout(), apiClient, file, payload, options, folderPath, spconfig.WaitOptions{Timeout: timeout})
+			}
+
 			ctx := context.TODO()
 
-			job, _, err := apiClient.Beta.SPConfigAPI.ImportSpConfig(ctx).Data(file).Execute()
+			request := apiClient.Beta.SPConfigAPI.ImportSpConfig(ctx).Data(file)
+			if options != nil {
+				request = request.Options(*options)
+			}
+			job, _, err := request.Execute()
 			if err != nil {
 				return err
 			}
@@ -47,7 +143,7 @@ func newImportCommand() *cobra.Command {
 
 			if wait {
 				log.Warn("Waiting for import task to complete")
-				downloadErr := spconfig.DownloadImport(*apiClient, job.JobId, "spconfig-import-"+job.JobId, folderPath)
+				downloadErr := spconfig.DownloadImport(*apiClient, job.JobId, "spconfig-import-"+job.JobId, folderPath, spconfig.WaitOptions{Timeout: timeout})
 				if downloadErr != nil {
 					return downloadErr
 				}
@@ -60,7 +156,18 @@ func newImportCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&filePath, "filePath", "f", "", "Path to the file containing the import payload")
 	cmd.Flags().StringVarP(&folderPath, "folderPath", "p", "spconfig-imports", "Folder path to save the import results in. If the directory doesn't exist, then it will be automatically created. (default is the current working directory)")
 	cmd.Flags().BoolVarP(&wait, "wait", "w", false, "Wait for the import job to finish, and then download the results")
-	cmd.MarkFlagRequired("filepath")
+	cmd.Flags().StringVar(&fromDir, "from-dir", "", "Folder of a split export, written by sail spconfig export --split, to reassemble into the import payload")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview the import on the platform and analyze the payload against the tenant, without importing")
+	cmd.Flags().StringArrayVarP(&includeTypes, "include", "i", []string{}, "Types to include in the import")
+	cmd.Flags().StringArrayVarP(&excludeTypes, "exclude", "e", []string{}, "Types to exclude from the import")
+	cmd.Flags().StringVarP(&objectOptions, "objectOptions", "o", "", "Options for the object types being imported")
+	cmd.Flags().StringVarP(&mappingPath, "mapping", "m", "", "YAML or JSON file mapping references of the source tenant to objects of the target tenant")
+	cmd.Flags().BoolVar(&remap, "remap", false, "Remap references to objects of the target tenant, looked up by name, without a mapping file")
+	cmd.Flags().BoolVar(&discover, "discover", true, "Look up the references missing from the mapping file in the target tenant")
+	cmd.Flags().BoolVar(&allowUnresolved, "allow-unresolved", false, "Import even when some references could not be remapped, keeping their source tenant IDs")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Stop waiting for the job after this long, for example 10m, leaving it running. Waits until the job ends by default")
+	cmd.MarkFlagsMutuallyExclusive("filePath", "from-dir")
+	cmd.MarkFlagsMutuallyExclusive("dry-run", "wait")
 
 	return cmd
 }
@@ -0,0 +1,126 @@
+package spconfig
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	sailpoint "github.com/sailpoint-oss/golang-sdk/v2"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/output"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/sdk"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/search"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/spconfig"
+)
+
+// searchIndices are the search indices references are looked up in
+var searchIndices = map[string]string{
+	"IDENTITY":       "identities",
+	"ACCESS_PROFILE": "accessprofiles",
+	"ROLE":           "roles",
+	"ENTITLEMENT":    "entitlements",
+}
+
+// quoteQuery quotes a value for a search query
+func quoteQuery(value string) string {
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(value, `\`, `\\`), `"`, `\"`) + `"`
+}
+
+// quoteFilter quotes a value for a list API filter
+func quoteFilter(value string) string {
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
+
+// newTenantResolver looks references up in the tenant of apiClient, with
+// search for identities, access profiles, roles and entitlements, and the
+// list APIs for sources, identity profiles and governance groups
+func newTenantResolver(apiClient *sailpoint.APIClient) spconfig.ReferenceResolver {
+	return func(referenceType string, target spconfig.ReferenceTarget) ([]string, error) {
+		ctx := context.TODO()
+
+		if index, ok := searchIndices[referenceType]; ok {
+			field, value := "name", target.Name
+			if target.Email != "" {
+				if referenceType != "IDENTITY" {
+					return nil, fmt.Errorf("%s references cannot be looked up by email", referenceType)
+				}
+				field, value = "email", target.Email
+			}
+
+			query, err := search.BuildSearch(field+":"+quoteQuery(value), nil, []string{index})
+			if err != nil {
+				return nil, err
+			}
+			hits, resp, err := apiClient.V3.SearchAPI.SearchPost(ctx).Search(query).Limit(10).Execute()
+			if err != nil {
+				return nil, sdk.HandleSDKError(resp, err)
+			}
+
+			// Search matches words, keep the exact matches only
+			var ids []string
+			for _, hit := range hits {
+				if found, _ := hit[field].(string); strings.EqualFold(found, value) {
+					if id, _ := hit["id"].(string); id != "" {
+						ids = append(ids, id)
+					}
+				}
+			}
+			return ids, nil
+		}
+
+		if target.Email != "" {
+			return nil, fmt.Errorf("%s references cannot be looked up by email", referenceType)
+		}
+		filter := "name eq " + quoteFilter(target.Name)
+
+		var ids []string
+		switch referenceType {
+		case "SOURCE":
+			sources, resp, err := apiClient.V3.SourcesAPI.ListSources(ctx).Filters(filter).Execute()
+			if err != nil {
+				return nil, sdk.HandleSDKError(resp, err)
+			}
+			for _, source := range sources {
+				ids = append(ids, source.GetId())
+			}
+		case "IDENTITY_PROFILE":
+			profiles, resp, err := apiClient.V3.IdentityProfilesAPI.ListIdentityProfiles(ctx).Filters(filter).Execute()
+			if err != nil {
+				return nil, sdk.HandleSDKError(resp, err)
+			}
+			for _, profile := range profiles {
+				ids = append(ids, profile.GetId())
+			}
+		case "GOVERNANCE_GROUP":
+			groups, resp, err := apiClient.Beta.GovernanceGroupsAPI.ListWorkgroups(ctx).Filters(filter).Execute()
+			if err != nil {
+				return nil, sdk.HandleSDKError(resp, err)
+			}
+			for _, group := range groups {
+				ids = append(ids, group.GetId())
+			}
+		default:
+			return nil, spconfig.ErrUnsupportedReference
+		}
+
+		return ids, nil
+	}
+}
+
+func writeRemapReport(w io.Writer, report spconfig.RemapReport) {
+	counts := report.Counts()
+	_, _ = fmt.Fprintf(w, "References: %d mapped, %d discovered, %d unchanged, %d ambiguous, %d not found, %d skipped\n",
+		counts[spconfig.RemapMapped], counts[spconfig.RemapDiscovered], counts[spconfig.RemapUnchanged],
+		counts[spconfig.RemapAmbiguous], counts[spconfig.RemapNotFound], counts[spconfig.RemapSkipped])
+
+	var entries [][]string
+	for _, entry := range report.Entries {
+		if entry.Status == spconfig.RemapUnchanged {
+			continue
+		}
+		entries = append(entries, []string{entry.Type, entry.Name, entry.OldId, entry.NewId, entry.Status, entry.FromType + " " + entry.FromName, entry.Error})
+	}
+	if len(entries) > 0 {
+		output.WriteTable(w, []string{"Type", "Name", "Source ID", "Target ID", "Status", "Referenced By", "Details"}, entries, "")
+	}
+}
@@ -31,6 +31,7 @@ func NewSPConfigCommand() *cobra.Command {
 		newTemplateCommand(),
 		newDownloadCommand(),
 		newImportCommand(),
+		newDiffCommand(),
 	)
 
 	return cmd
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/config"
@@ -26,6 +27,7 @@ func newTemplateCommand() *cobra.Command {
 	var folderPath string
 	var template string
 	var wait bool
+	var timeout time.Duration
 	cmd := &cobra.Command{
 		Use:     "template",
 		Short:   "Begin an SPConfig export task in Identity Security Cloud, using a template",
@@ -89,7 +91,10 @@ func newTemplateCommand() *cobra.Command {
 
 			if wait {
 				log.Info("Checking Export Job", "JobID", job.JobId)
-				spconfig.DownloadExport(*apiClient, job.JobId, "spconfig-export-"+template+"-"+job.JobId+".json", folderPath)
+				err := spconfig.DownloadExport(*apiClient, job.JobId, "spconfig-export-"+template+"-"+job.JobId, folderPath, spconfig.WaitOptions{Timeout: timeout})
+				if err != nil {
+					return err
+				}
 			}
 
 			return nil
@@ -98,6 +103,7 @@ func newTemplateCommand() *cobra.Command {
 
 	cmd.Flags().StringVarP(&folderPath, "folderPath", "f", "spconfig-exports", "Folder path to save the search results in. If the directory doesn't exist, then it will be automatically created. (default is the current working directory)")
 	cmd.Flags().BoolVarP(&wait, "wait", "w", false, "Wait for the export job to finish, and then download the results")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Stop waiting for the job after this long, for example 10m, leaving it running. Waits until the job ends by default")
 
 	return cmd
 }
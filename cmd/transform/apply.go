@@ -0,0 +1,294 @@
+package transform
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	sailpoint "github.com/sailpoint-oss/golang-sdk/v2"
+	v3 "github.com/sailpoint-oss/golang-sdk/v2/api_v3"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/config"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/output"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/sdk"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/terminal"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Plan actions of sail transform apply
+const (
+	applyCreate    = "create"
+	applyUpdate    = "update"
+	applyDelete    = "delete"
+	applyUnchanged = "unchanged"
+)
+
+// transformChange is a planned change to a transform of the tenant
+type transformChange struct {
+	Action string
+	Name   string
+	Type   string
+	// Id of the tenant transform, empty for creations
+	Id string
+	// Path of the file, empty for deletions
+	Path string
+	// Fields are the changed attributes of an update
+	Fields     []string
+	Attributes map[string]interface{}
+}
+
+// planTransforms compares the transforms of a folder with the tenant ones,
+// matched by name. Internal transforms are never changed. Creations come in
+// dependency order and deletions in reverse dependency order, so references
+// between transforms resolve at every step.
+func planTransforms(files []transformFile, tenant []v3.TransformRead, prune bool) ([]transformChange, error) {
+	existing := map[string]v3.TransformRead{}
+	for _, transform := range tenant {
+		existing[transform.Name] = transform
+	}
+
+	var creates, updates, unchanged, deletes []transformChange
+	local := map[string]bool{}
+	for _, file := range files {
+		local[file.Name] = true
+		change := transformChange{Action: applyCreate, Name: file.Name, Type: file.Type, Path: file.Path, Attributes: file.Attributes}
+
+		current, ok := existing[file.Name]
+		if !ok {
+			creates = append(creates, change)
+			continue
+		}
+		if current.Internal {
+			return nil, fmt.Errorf("%s defines %q, an internal transform that cannot be changed", file.Path, file.Name)
+		}
+		if current.Type != file.Type {
+			return nil, fmt.Errorf("%s changes the type of %q from %s to %s, delete the transform first", file.Path, file.Name, current.Type, file.Type)
+		}
+
+		change.Id = current.Id
+		fields, err := changedAttributes(current.Attributes, file.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) == 0 {
+			change.Action = applyUnchanged
+			unchanged = append(unchanged, change)
+			continue
+		}
+		change.Action = applyUpdate
+		change.Fields = fields
+		updates = append(updates, change)
+	}
+
+	if prune {
+		for _, transform := range tenant {
+			if local[transform.Name] || transform.Internal {
+				continue
+			}
+			deletes = append(deletes, transformChange{Action: applyDelete, Name: transform.Name, Type: transform.Type, Id: transform.Id, Attributes: transform.Attributes})
+		}
+	}
+
+	creates = dependencyOrder(creates)
+	deletes = dependencyOrder(deletes)
+	for i, j := 0, len(deletes)-1; i < j; i, j = i+1, j-1 {
+		deletes[i], deletes[j] = deletes[j], deletes[i]
+	}
+	sort.Slice(updates, func(i, j int) bool { return updates[i].Name < updates[j].Name })
+	sort.Slice(unchanged, func(i, j int) bool { return unchanged[i].Name < unchanged[j].Name })
+
+	plan := append(creates, updates...)
+	plan = append(plan, deletes...)
+	return append(plan, unchanged...), nil
+}
+
+// changedAttributes returns the top level attributes that differ, sorted
+func changedAttributes(current map[string]interface{}, desired map[string]interface{}) ([]string, error) {
+	before, err := normalizeAttributes(current)
+	if err != nil {
+		return nil, err
+	}
+	after, err := normalizeAttributes(desired)
+	if err != nil {
+		return nil, err
+	}
+	b, _ := before.(map[string]interface{})
+	a, _ := after.(map[string]interface{})
+
+	var fields []string
+	for key, value := range b {
+		if other, ok := a[key]; !ok || !reflect.DeepEqual(value, other) {
+			fields = append(fields, "attributes."+key)
+		}
+	}
+	for key := range a {
+		if _, ok := b[key]; !ok {
+			fields = append(fields, "attributes."+key)
+		}
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+// dependencyOrder sorts changes so that a transform comes after the ones it
+// references. Transforms in a reference cycle keep their name order.
+func dependencyOrder(changes []transformChange) []transformChange {
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+
+	byName := map[string]transformChange{}
+	for _, change := range changes {
+		byName[change.Name] = change
+	}
+
+	var ordered []transformChange
+	state := map[string]int{}
+	var visit func(change transformChange)
+	visit = func(change transformChange) {
+		if state[change.Name] != 0 {
+			return
+		}
+		state[change.Name] = 1
+		for _, name := range referencedTransforms(change.Type, change.Attributes) {
+			if dependency, ok := byName[name]; ok {
+				visit(dependency)
+			}
+		}
+		state[change.Name] = 2
+		ordered = append(ordered, change)
+	}
+	for _, change := range changes {
+		visit(change)
+	}
+	return ordered
+}
+
+func writeTransformPlan(w io.Writer, plan []transformChange) int {
+	counts := map[string]int{}
+	var entries [][]string
+	for _, change := range plan {
+		counts[change.Action]++
+		if change.Action == applyUnchanged {
+			continue
+		}
+		entries = append(entries, []string{change.Action, change.Name, change.Type, change.Path, strings.Join(change.Fields, ", ")})
+	}
+
+	if len(entries) > 0 {
+		output.WriteTable(w, []string{"Action", "Name", "Type", "File", "Changed Fields"}, entries, "")
+	}
+	_, _ = fmt.Fprintf(w, "%d to create, %d to update, %d to delete, %d unchanged\n", counts[applyCreate], counts[applyUpdate], counts[applyDelete], counts[applyUnchanged])
+
+	return len(entries)
+}
+
+// applyTransformChange makes a planned change in the tenant
+func applyTransformChange(apiClient *sailpoint.APIClient, change transformChange) error {
+	transform := v3.Transform{Name: change.Name, Type: change.Type, Attributes: change.Attributes}
+
+	switch change.Action {
+	case applyCreate:
+		created, resp, err := apiClient.V3.TransformsAPI.CreateTransform(context.TODO()).Transform(transform).Execute()
+		if err != nil {
+			return sdk.HandleSDKError(resp, err)
+		}
+		log.Info("Transform created", "name", change.Name, "transformID", created.Id)
+	case applyUpdate:
+		_, resp, err := apiClient.V3.TransformsAPI.UpdateTransform(context.TODO(), change.Id).Transform(transform).Execute()
+		if err != nil {
+			return sdk.HandleSDKError(resp, err)
+		}
+		log.Info("Transform updated", "name", change.Name, "transformID", change.Id)
+	case applyDelete:
+		resp, err := apiClient.V3.TransformsAPI.DeleteTransform(context.TODO(), change.Id).Execute()
+		if err != nil {
+			return sdk.HandleSDKError(resp, err)
+		}
+		log.Info("Transform deleted", "name", change.Name, "transformID", change.Id)
+	}
+
+	return nil
+}
+
+func newApplyCommand() *cobra.Command {
+	var directory string
+	var prune bool
+	var dryRun bool
+	var yes bool
+	cmd := &cobra.Command{
+		Use:     "apply",
+		Short:   "Reconcile the transforms of Identity Security Cloud with a folder",
+		Long:    "\nReconcile the transforms of Identity Security Cloud with a folder of transform files\n\nTransforms are matched by name. Missing transforms are created and changed ones are updated, the id and internal fields of the files are ignored. With --prune, transforms that have no file are deleted, internal transforms are never changed.\n\nThe plan is printed and confirmed before any change. Use --dry-run to only print the plan, and --yes to apply it without confirmation, for example from CI.\n\n",
+		Example: "sail transform apply -d transforms\nsail transform apply -d transforms --prune --dry-run\nsail transform apply -d transforms --prune --yes",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			files, err := readTransformDir(directory)
+			if err != nil {
+				return err
+			}
+			if len(files) == 0 && prune {
+				return fmt.Errorf("no transform files found in %s, refusing to prune every transform", directory)
+			}
+
+			apiClient, err := config.InitAPIClient(false)
+			if err != nil {
+				return err
+			}
+
+			tenant, resp, err := sailpoint.PaginateWithDefaults[v3.TransformRead](apiClient.V3.TransformsAPI.ListTransforms(context.TODO()))
+			if err != nil {
+				return sdk.HandleSDKError(resp, err)
+			}
+
+			plan, err := planTransforms(files, tenant, prune)
+			if err != nil {
+				return err
+			}
+
+			if writeTransformPlan(cmd.OutOrStdout(), plan) == 0 {
+				log.Info("Transforms are up to date", "directory", directory)
+				return nil
+			}
+			if dryRun {
+				return nil
+			}
+
+			if !yes {
+				if !term.IsTerminal(int(os.Stdin.Fd())) {
+					return fmt.Errorf("confirmation needs a terminal, use --yes to apply the plan")
+				}
+				answer := terminal.InputPrompt("Apply these changes? [y/N]")
+				if answer != "y" && answer != "yes" {
+					log.Warn("Nothing applied")
+					return nil
+				}
+			}
+
+			var failed []string
+			for _, change := range plan {
+				if err := applyTransformChange(apiClient, change); err != nil {
+					log.Error("Unable to "+change.Action+" transform", "name", change.Name, "err", err)
+					failed = append(failed, change.Name)
+				}
+			}
+			if len(failed) > 0 {
+				return fmt.Errorf("%d transform changes failed: %s", len(failed), strings.Join(failed, ", "))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&directory, "directory", "d", "transform_files", "Folder of transform files to apply, searched recursively")
+	cmd.Flags().BoolVar(&prune, "prune", false, "Delete the transforms of the tenant that have no file")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the plan without applying it")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Apply the plan without confirmation")
+	cmd.MarkFlagsMutuallyExclusive("dry-run", "yes")
+
+	return cmd
+}
@@ -0,0 +1,74 @@
+package transform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	v3 "github.com/sailpoint-oss/golang-sdk/v2/api_v3"
+)
+
+func TestPlanTransforms(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Lower.json": `{"id": "stale-id", "internal": false, "name": "Lower", "type": "lower", "attributes": {"input": "x"}}`,
+		"Upper.json": `{"name": "Upper", "type": "upper", "attributes": {"input": "y"}}`,
+		// Names sort opposite to the dependencies, A and C must come after Z and Y
+		"nested/A.json": `{"name": "A", "type": "reference", "attributes": {"id": "Z"}}`,
+		"Z.json":        `{"name": "Z", "type": "trim", "attributes": {}}`,
+		"C.json":        `{"name": "C", "type": "concat", "attributes": {"values": [{"type": "reference", "attributes": {"id": "Y"}}, "-"]}}`,
+		"Y.json":        `{"name": "Y", "type": "lower", "attributes": {}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	local, err := readTransformDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tenant := []v3.TransformRead{
+		{Id: "1", Name: "Lower", Type: "lower", Attributes: map[string]interface{}{"input": "x"}},
+		{Id: "2", Name: "Upper", Type: "upper", Attributes: map[string]interface{}{"input": "z"}},
+		{Id: "3", Name: "Old", Type: "trim", Attributes: map[string]interface{}{}},
+		{Id: "4", Name: "ToUpper", Type: "upper", Internal: true},
+	}
+
+	plan, err := planTransforms(local, tenant, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct{ action, name string }{
+		{applyCreate, "Z"},
+		{applyCreate, "A"},
+		{applyCreate, "Y"},
+		{applyCreate, "C"},
+		{applyUpdate, "Upper"},
+		{applyDelete, "Old"},
+		{applyUnchanged, "Lower"},
+	}
+	if len(plan) != len(expected) {
+		t.Fatalf("expected %d changes, got %+v", len(expected), plan)
+	}
+	for i, change := range plan {
+		if change.Action != expected[i].action || change.Name != expected[i].name {
+			t.Errorf("expected change %d to %s %s, got %s %s", i, expected[i].action, expected[i].name, change.Action, change.Name)
+		}
+	}
+	if len(plan[4].Fields) != 1 || plan[4].Fields[0] != "attributes.input" {
+		t.Errorf("expected the update to change attributes.input, got %v", plan[4].Fields)
+	}
+
+	tenant[0].Type = "upper"
+	if _, err := planTransforms(local, tenant, false); err == nil {
+		t.Error("expected a type change to be refused")
+	}
+}
@@ -0,0 +1,186 @@
+package transform
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/charmbracelet/log"
+	v2024 "github.com/sailpoint-oss/golang-sdk/v2/api_v2024"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/output"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/search"
+)
+
+// batchPreviewOptions are the inputs of a preview across identities
+type batchPreviewOptions struct {
+	query          string
+	limit          int32
+	profile        string
+	attribute      string
+	compareCurrent bool
+	csvPath        string
+}
+
+// previewResult is the transform result for an identity
+type previewResult struct {
+	IdentityId string
+	Name       string
+	Current    string
+	New        string
+	Changed    bool
+	Error      string
+}
+
+// previewRow reads the transform result of an identity preview, and the
+// current value of the compared attribute when there is one
+func previewRow(identity search.Identity, preview v2024.IdentityPreviewResponse, attribute string) previewResult {
+	result := previewResult{IdentityId: identity.ID, Name: identity.DisplayName}
+	if result.Name == "" {
+		result.Name = identity.Name
+	}
+
+	for _, v := range preview.PreviewAttributes {
+		switch v.GetName() {
+		case previewAttributeName:
+			if len(v.GetErrorMessages()) > 0 {
+				result.Error = v.GetErrorMessages()[0].GetText()
+			}
+			result.New = v.GetValue()
+		case attribute:
+			// The previous value is the stored one, the value the one the
+			// profile computes today
+			result.Current = v.GetPreviousValue()
+			if result.Current == "" {
+				result.Current = v.GetValue()
+			}
+		}
+	}
+
+	if attribute != "" && result.Error == "" {
+		result.Changed = result.Current != result.New
+	}
+	return result
+}
+
+// batchPreview previews the transform for the identities matching a search
+// query, each with the attribute configuration of its identity profile
+func batchPreview(w io.Writer, session *previewSession, opts batchPreviewOptions) error {
+	if opts.compareCurrent && opts.attribute == "" {
+		return fmt.Errorf("--compare-current needs the identity attribute to compare with, set it with --attribute")
+	}
+	if !opts.compareCurrent {
+		opts.attribute = ""
+	}
+
+	searchObj, err := search.BuildSearch(opts.query, []string{"name"}, []string{"identities"})
+	if err != nil {
+		return err
+	}
+	searchObj.QueryResultFilter.Includes = []string{"id", "name", "displayName", "identityProfile"}
+
+	found, err := search.PerformSearchWithLimit(*session.apiClient, searchObj, opts.limit)
+	if err != nil {
+		return err
+	}
+	if len(found.Identities) == 0 {
+		return fmt.Errorf("no identities match the query %q", opts.query)
+	}
+
+	var results []previewResult
+	for i, identity := range found.Identities {
+		profile := opts.profile
+		if profile == "" {
+			profile = identity.IdentityProfile.ID
+		}
+		log.Info("Previewing", "identity", identity.Name, "progress", fmt.Sprintf("%d/%d", i+1, len(found.Identities)))
+
+		if profile == "" {
+			results = append(results, previewResult{IdentityId: identity.ID, Name: identity.Name, Error: "the identity has no identity profile"})
+			continue
+		}
+		preview, err := session.preview(identity.ID, profile)
+		if err != nil {
+			results = append(results, previewResult{IdentityId: identity.ID, Name: identity.Name, Error: err.Error()})
+			continue
+		}
+		results = append(results, previewRow(identity, *preview, opts.attribute))
+	}
+
+	writePreviewResults(w, results, opts.compareCurrent)
+
+	if opts.csvPath != "" {
+		if err := writePreviewCSV(opts.csvPath, results, opts.compareCurrent); err != nil {
+			return err
+		}
+		log.Info("Preview results saved", "file", opts.csvPath)
+	}
+
+	return nil
+}
+
+func writePreviewResults(w io.Writer, results []previewResult, compare bool) {
+	headers := []string{"Identity", "Identity ID", "Result", "Error"}
+	if compare {
+		headers = []string{"Identity", "Identity ID", "Current", "New", "Changed", "Error"}
+	}
+
+	var changed, failed int
+	var entries [][]string
+	for _, result := range results {
+		if result.Changed {
+			changed++
+		}
+		if result.Error != "" {
+			failed++
+		}
+		if compare {
+			flag := ""
+			if result.Changed {
+				flag = "yes"
+			}
+			entries = append(entries, []string{result.Name, result.IdentityId, result.Current, result.New, flag, result.Error})
+		} else {
+			entries = append(entries, []string{result.Name, result.IdentityId, result.New, result.Error})
+		}
+	}
+	output.WriteTable(w, headers, entries, "")
+
+	if compare {
+		_, _ = fmt.Fprintf(w, "%d identities, %d would change, %d errors\n", len(results), changed, failed)
+	} else {
+		_, _ = fmt.Fprintf(w, "%d identities, %d errors\n", len(results), failed)
+	}
+}
+
+func writePreviewCSV(path string, results []previewResult, compare bool) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	header := []string{"identityId", "name", "result", "error"}
+	if compare {
+		header = []string{"identityId", "name", "current", "new", "changed", "error"}
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, result := range results {
+		record := []string{result.IdentityId, result.Name, result.New, result.Error}
+		if compare {
+			record = []string{result.IdentityId, result.Name, result.Current, result.New, strconv.FormatBool(result.Changed), result.Error}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,69 @@
+package transform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	v2024 "github.com/sailpoint-oss/golang-sdk/v2/api_v2024"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/search"
+)
+
+func previewAttribute(name string, value string, previous string) v2024.IdentityAttributePreview {
+	attribute := v2024.IdentityAttributePreview{}
+	attribute.SetName(name)
+	attribute.SetValue(value)
+	if previous != "" {
+		attribute.SetPreviousValue(previous)
+	}
+	return attribute
+}
+
+func TestPreviewRow(t *testing.T) {
+	identity := search.Identity{ID: "id-1", Name: "jdoe", DisplayName: "Jane Doe"}
+
+	preview := v2024.IdentityPreviewResponse{PreviewAttributes: []v2024.IdentityAttributePreview{
+		previewAttribute("uid", "jdoe", "jdoe"),
+		previewAttribute(previewAttributeName, "jane.doe", ""),
+	}}
+
+	result := previewRow(identity, preview, "uid")
+	if result.Name != "Jane Doe" || result.Current != "jdoe" || result.New != "jane.doe" || !result.Changed {
+		t.Errorf("expected jdoe to change to jane.doe, got %+v", result)
+	}
+
+	result = previewRow(identity, preview, "")
+	if result.Changed || result.Current != "" {
+		t.Errorf("expected no comparison without an attribute, got %+v", result)
+	}
+
+	failed := previewAttribute(previewAttributeName, "", "")
+	message := v2024.ErrorMessageDto{}
+	message.SetText("attribute not found")
+	failed.ErrorMessages = []v2024.ErrorMessageDto{message}
+	result = previewRow(identity, v2024.IdentityPreviewResponse{PreviewAttributes: []v2024.IdentityAttributePreview{previewAttribute("uid", "jdoe", ""), failed}}, "uid")
+	if result.Error != "attribute not found" || result.Changed {
+		t.Errorf("expected the error without a change flag, got %+v", result)
+	}
+}
+
+func TestWritePreviewCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "preview.csv")
+	results := []previewResult{
+		{IdentityId: "id-1", Name: "Doe, Jane", Current: "jdoe", New: "jane.doe", Changed: true},
+		{IdentityId: "id-2", Name: "John Roe", Current: "jroe", New: "jroe"},
+	}
+
+	if err := writePreviewCSV(path, results, true); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "identityId,name,current,new,changed,error\nid-1,\"Doe, Jane\",jdoe,jane.doe,true,\nid-2,John Roe,jroe,jroe,false,\n"
+	if string(raw) != expected {
+		t.Errorf("expected CSV:\n%s\ngot:\n%s", expected, raw)
+	}
+}
@@ -0,0 +1,94 @@
+package transform
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/log"
+	"github.com/sailpoint-oss/sailpoint-cli/cmd/transform/evaluator"
+)
+
+// previewOptions are the inputs of an offline preview
+type previewOptions struct {
+	transformPath string
+	samplePath    string
+	directory     string
+	input         *string
+	resultOnly    bool
+}
+
+// loadEvaluator builds an evaluator from a sample file and the transforms of
+// a folder, for references
+func loadEvaluator(samplePath string, directory string) (evaluator.Evaluator, error) {
+	e := evaluator.Evaluator{Transforms: map[string]map[string]interface{}{}}
+
+	if samplePath != "" {
+		sample, err := evaluator.LoadSample(samplePath)
+		if err != nil {
+			return e, err
+		}
+		e.Sample = sample
+	}
+
+	if directory != "" {
+		files, err := readTransformDir(directory)
+		if err != nil {
+			return e, err
+		}
+		for _, file := range files {
+			e.Transforms[file.Name] = map[string]interface{}{"name": file.Name, "type": file.Type, "attributes": file.Attributes}
+		}
+	}
+
+	return e, nil
+}
+
+// offlinePreview evaluates a transform file locally against a sample
+func offlinePreview(w io.Writer, opts previewOptions) error {
+	raw, err := os.ReadFile(opts.transformPath)
+	if err != nil {
+		return err
+	}
+	var definition map[string]interface{}
+	if err := json.Unmarshal(raw, &definition); err != nil {
+		return fmt.Errorf("invalid transform file %s: %v", opts.transformPath, err)
+	}
+
+	e, err := loadEvaluator(opts.samplePath, opts.directory)
+	if err != nil {
+		return err
+	}
+	if name, ok := definition["name"].(string); ok && name != "" {
+		e.Transforms[name] = definition
+	}
+
+	var input interface{}
+	if opts.input != nil {
+		input = *opts.input
+	}
+
+	result, err := e.Evaluate(definition, input)
+	if errors.Is(err, evaluator.ErrUnsupported) {
+		return fmt.Errorf("%v, preview it in the tenant with --live", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	if opts.resultOnly {
+		if result != nil {
+			_, _ = fmt.Fprintln(w, result)
+		}
+		return nil
+	}
+
+	if result == nil {
+		log.Info("", "transform result", "null")
+	} else {
+		log.Info("", "transform result", result)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package evaluator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// win32EpochOffset is the number of seconds from the start of Windows file
+// times, 1601-01-01, to the Unix epoch. File times count 100ns intervals,
+// which overflows time.Duration past 1893, so they are computed from Unix
+// seconds.
+const win32EpochOffset = 11644473600
+
+// FormatDate converts a date between the named formats of the dateFormat
+// transform (ISO8601, LDAP, PEOPLE_SOFT, EPOCH_TIME_JAVA, EPOCH_TIME_WIN32)
+// or Java SimpleDateFormat patterns. Empty formats default to ISO8601.
+func FormatDate(input string, inputFormat string, outputFormat string) (string, error) {
+	date, err := parseDate(strings.TrimSpace(input), inputFormat)
+	if err != nil {
+		return "", err
+	}
+
+	switch outputFormat {
+	case "", "ISO8601":
+		return date.UTC().Format("2006-01-02T15:04:05.000Z"), nil
+	case "LDAP":
+		return date.UTC().Format("20060102150405Z"), nil
+	case "PEOPLE_SOFT":
+		return date.Format("01/02/2006"), nil
+	case "EPOCH_TIME_JAVA":
+		return strconv.FormatInt(date.UnixMilli(), 10), nil
+	case "EPOCH_TIME_WIN32":
+		return strconv.FormatInt((date.Unix()+win32EpochOffset)*1e7+int64(date.Nanosecond())/100, 10), nil
+	}
+
+	layout, err := JavaLayout(outputFormat)
+	if err != nil {
+		return "", err
+	}
+	return date.Format(layout), nil
+}
+
+func parseDate(input string, format string) (time.Time, error) {
+	switch format {
+	case "", "ISO8601":
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+			if date, err := time.Parse(layout, input); err == nil {
+				return date, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("%q is not an ISO8601 date", input)
+	case "LDAP":
+		for _, layout := range []string{"20060102150405Z0700", "20060102150405Z", "20060102150405.0Z"} {
+			if date, err := time.Parse(layout, input); err == nil {
+				return date, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("%q is not an LDAP date", input)
+	case "PEOPLE_SOFT":
+		return time.Parse("01/02/2006", input)
+	case "EPOCH_TIME_JAVA":
+		millis, err := strconv.ParseInt(input, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%q is not an epoch time in milliseconds", input)
+		}
+		return time.UnixMilli(millis).UTC(), nil
+	case "EPOCH_TIME_WIN32":
+		intervals, err := strconv.ParseInt(input, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%q is not a Windows file time", input)
+		}
+		return time.Unix(intervals/1e7-win32EpochOffset, (intervals%1e7)*100).UTC(), nil
+	}
+
+	layout, err := JavaLayout(format)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(layout, input)
+}
+
+// javaTokens maps SimpleDateFormat letter runs to Go layout elements
+var javaTokens = map[string]string{
+	"yyyy": "2006", "yy": "06", "y": "2006",
+	"MMMM": "January", "MMM": "Jan", "MM": "01", "M": "1",
+	"dd": "02", "d": "2",
+	"EEEE": "Monday", "EEE": "Mon",
+	"HH": "15", "H": "15", "hh": "03", "h": "3",
+	"mm": "04", "m": "4",
+	"ss": "05", "s": "5",
+	"SSS": "000", "SS": "00", "S": "0",
+	"a": "PM",
+	"z": "MST",
+	"Z": "-0700",
+	"X": "Z07", "XX": "Z0700", "XXX": "Z07:00",
+}
+
+// JavaLayout converts a Java SimpleDateFormat pattern to a Go time layout
+func JavaLayout(pattern string) (string, error) {
+	var layout strings.Builder
+	chars := []rune(pattern)
+	for i := 0; i < len(chars); {
+		c := chars[i]
+
+		// Quoted literals, with '' as an escaped quote
+		if c == '\'' {
+			if i+1 < len(chars) && chars[i+1] == '\'' {
+				layout.WriteRune('\'')
+				i += 2
+				continue
+			}
+			end := i + 1
+			for end < len(chars) && chars[end] != '\'' {
+				end++
+			}
+			layout.WriteString(string(chars[i+1 : end]))
+			i = end + 1
+			continue
+		}
+
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			layout.WriteRune(c)
+			i++
+			continue
+		}
+
+		run := i
+		for run < len(chars) && chars[run] == c {
+			run++
+		}
+		token := string(chars[i:run])
+		element, ok := javaTokens[token]
+		if !ok && len(token) > 4 && (c == 'y' || c == 'M' || c == 'E') {
+			element, ok = javaTokens[token[:4]]
+		}
+		if !ok {
+			return "", fmt.Errorf("date pattern %q uses %q, which is not supported offline", pattern, token)
+		}
+		layout.WriteString(element)
+		i = run
+	}
+	return layout.String(), nil
+}
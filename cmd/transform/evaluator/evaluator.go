@@ -0,0 +1,528 @@
+// Package evaluator runs common transforms locally, against sample identity
+// and account attributes, without calling Identity Security Cloud
+package evaluator
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrUnsupported is returned for transform types that can only be previewed
+// in Identity Security Cloud
+var ErrUnsupported = errors.New("transform type cannot be evaluated offline")
+
+// SupportedTypes are the transform types the evaluator runs
+var SupportedTypes = []string{
+	"accountAttribute", "base64Decode", "base64Encode", "concat", "conditional", "dateFormat",
+	"decomposeDiacriticalMarks", "firstValid", "getEndOfString", "identityAttribute", "indexOf",
+	"lastIndexOf", "leftPad", "lookup", "lower", "reference", "replace", "replaceAll", "rightPad",
+	"split", "static", "substring", "trim", "upper",
+}
+
+// maxDepth bounds nested transforms and references, to stop on cycles
+const maxDepth = 64
+
+var errTooDeep = fmt.Errorf("transforms are nested more than %d levels, check for reference cycles", maxDepth)
+
+// Sample holds the attributes transforms are evaluated against. Identity
+// attributes are read from identity.attributes, then from identity itself.
+// Accounts are keyed by source name.
+type Sample struct {
+	Identity map[string]interface{}            `json:"identity"`
+	Accounts map[string]map[string]interface{} `json:"accounts"`
+}
+
+// LoadSample reads a sample JSON file
+func LoadSample(path string) (Sample, error) {
+	var sample Sample
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return sample, err
+	}
+	if err := json.Unmarshal(raw, &sample); err != nil {
+		return sample, fmt.Errorf("invalid sample file %s: %v", path, err)
+	}
+	return sample, nil
+}
+
+// IdentityAttribute returns an identity attribute of the sample
+func (s Sample) IdentityAttribute(name string) (interface{}, bool) {
+	if attributes, ok := s.Identity["attributes"].(map[string]interface{}); ok {
+		if value, ok := attributes[name]; ok {
+			return value, true
+		}
+	}
+	value, ok := s.Identity[name]
+	return value, ok
+}
+
+// Evaluator evaluates transforms against a sample. Transforms holds the
+// definitions reference transforms point to, by name.
+type Evaluator struct {
+	Sample     Sample
+	Transforms map[string]map[string]interface{}
+}
+
+// Evaluate runs a transform definition, a map with a type and attributes, on
+// input. Input is used when the transform has no explicit input attribute, as
+// the account attribute of an identity profile mapping would be. The result
+// is a string, or nil.
+func (e Evaluator) Evaluate(definition map[string]interface{}, input interface{}) (interface{}, error) {
+	return e.evaluate(definition, input, 0)
+}
+
+func (e Evaluator) evaluate(definition map[string]interface{}, input interface{}, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, errTooDeep
+	}
+
+	transformType, _ := definition["type"].(string)
+	attributes, _ := definition["attributes"].(map[string]interface{})
+	if attributes == nil {
+		attributes = map[string]interface{}{}
+	}
+
+	// An explicit input replaces the implicit one
+	if explicit, ok := attributes["input"]; ok {
+		value, err := e.value(explicit, input, depth)
+		if errors.Is(err, errTooDeep) {
+			return nil, err
+		} else if err != nil {
+			return nil, fmt.Errorf("%s input: %w", transformType, err)
+		}
+		input = value
+	}
+
+	result, err := e.apply(transformType, attributes, input, depth)
+	if errors.Is(err, errTooDeep) {
+		return nil, err
+	} else if err != nil {
+		if name, ok := definition["name"].(string); ok && name != "" {
+			return nil, fmt.Errorf("%s (%s): %w", name, transformType, err)
+		}
+		return nil, fmt.Errorf("%s: %w", transformType, err)
+	}
+	return result, nil
+}
+
+// value evaluates an attribute value, a nested transform or a literal
+func (e Evaluator) value(v interface{}, input interface{}, depth int) (interface{}, error) {
+	if nested, ok := v.(map[string]interface{}); ok {
+		if _, typed := nested["type"]; typed {
+			return e.evaluate(nested, input, depth+1)
+		}
+	}
+	if v == nil {
+		return nil, nil
+	}
+	return toString(v), nil
+}
+
+func (e Evaluator) apply(transformType string, attributes map[string]interface{}, input interface{}, depth int) (interface{}, error) {
+	switch transformType {
+	case "accountAttribute":
+		source, _ := attributes["sourceName"].(string)
+		name, _ := attributes["attributeName"].(string)
+		account, ok := e.Sample.Accounts[source]
+		if !ok {
+			return nil, fmt.Errorf("the sample has no account on source %q", source)
+		}
+		return nullable(account[name]), nil
+
+	case "identityAttribute":
+		name, _ := attributes["name"].(string)
+		value, _ := e.Sample.IdentityAttribute(name)
+		return nullable(value), nil
+
+	case "static":
+		value, _ := attributes["value"].(string)
+		return e.template(value, attributes, input, depth)
+
+	case "reference":
+		id, _ := attributes["id"].(string)
+		referenced, ok := e.Transforms[id]
+		if !ok {
+			return nil, fmt.Errorf("referenced transform %q was not found", id)
+		}
+		return e.evaluate(referenced, input, depth+1)
+
+	case "concat":
+		values, ok := attributes["values"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("values must be a list")
+		}
+		var b strings.Builder
+		for _, item := range values {
+			value, err := e.value(item, input, depth)
+			if err != nil {
+				return nil, err
+			}
+			if value != nil {
+				b.WriteString(toString(value))
+			}
+		}
+		return b.String(), nil
+
+	case "firstValid":
+		values, ok := attributes["values"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("values must be a list")
+		}
+		ignoreErrors := isTrue(attributes["ignoreErrors"])
+		for _, item := range values {
+			value, err := e.value(item, input, depth)
+			if err != nil {
+				if ignoreErrors {
+					continue
+				}
+				return nil, err
+			}
+			if value != nil && toString(value) != "" {
+				return value, nil
+			}
+		}
+		return nil, nil
+
+	case "conditional":
+		expression, _ := attributes["expression"].(string)
+		left, right, ok := strings.Cut(expression, " eq ")
+		if !ok {
+			return nil, fmt.Errorf("expression %q must have the form \"ValueA eq ValueB\"", expression)
+		}
+		variables, err := e.variables(attributes, input, depth)
+		if err != nil {
+			return nil, err
+		}
+		branch := "negativeCondition"
+		if strings.TrimSpace(substitute(left, variables)) == strings.TrimSpace(substitute(right, variables)) {
+			branch = "positiveCondition"
+		}
+		value, _ := attributes[branch].(string)
+		return substitute(value, variables), nil
+
+	case "lookup":
+		table, ok := attributes["table"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("table must be a map")
+		}
+		if value, ok := table[toString(input)]; ok && input != nil {
+			return nullable(value), nil
+		}
+		if value, ok := table["default"]; ok {
+			return nullable(value), nil
+		}
+		return nil, fmt.Errorf("no table entry matches %q and there is no default", toString(input))
+	}
+
+	// The remaining types work on the input as a string
+	if input == nil {
+		if _, known := stringTransforms[transformType]; known {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, transformType)
+	}
+	apply, known := stringTransforms[transformType]
+	if !known {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, transformType)
+	}
+	return apply(toString(input), attributes)
+}
+
+// stringTransforms are the transforms of a single string input
+var stringTransforms = map[string]func(input string, attributes map[string]interface{}) (interface{}, error){
+	"lower": func(input string, _ map[string]interface{}) (interface{}, error) {
+		return strings.ToLower(input), nil
+	},
+	"upper": func(input string, _ map[string]interface{}) (interface{}, error) {
+		return strings.ToUpper(input), nil
+	},
+	"trim": func(input string, _ map[string]interface{}) (interface{}, error) {
+		return strings.TrimSpace(input), nil
+	},
+	"base64Encode": func(input string, _ map[string]interface{}) (interface{}, error) {
+		return base64.StdEncoding.EncodeToString([]byte(input)), nil
+	},
+	"base64Decode": func(input string, _ map[string]interface{}) (interface{}, error) {
+		decoded, err := base64.StdEncoding.DecodeString(input)
+		if err != nil {
+			return nil, err
+		}
+		return string(decoded), nil
+	},
+	"decomposeDiacriticalMarks": func(input string, _ map[string]interface{}) (interface{}, error) {
+		result, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), input)
+		return result, err
+	},
+	"indexOf": func(input string, attributes map[string]interface{}) (interface{}, error) {
+		substring, _ := attributes["substring"].(string)
+		return strconv.Itoa(runeIndex(input, strings.Index(input, substring))), nil
+	},
+	"lastIndexOf": func(input string, attributes map[string]interface{}) (interface{}, error) {
+		substring, _ := attributes["substring"].(string)
+		return strconv.Itoa(runeIndex(input, strings.LastIndex(input, substring))), nil
+	},
+	"getEndOfString": func(input string, attributes map[string]interface{}) (interface{}, error) {
+		count, err := intAttribute(attributes, "numChars", -1)
+		if err != nil {
+			return nil, err
+		}
+		chars := []rune(input)
+		if count < 0 {
+			return nil, fmt.Errorf("numChars is required")
+		}
+		if count > len(chars) {
+			return nil, nil
+		}
+		return string(chars[len(chars)-count:]), nil
+	},
+	"substring": func(input string, attributes map[string]interface{}) (interface{}, error) {
+		chars := []rune(input)
+		begin, err := intAttribute(attributes, "begin", 0)
+		if err != nil {
+			return nil, err
+		}
+		end, err := intAttribute(attributes, "end", -1)
+		if err != nil {
+			return nil, err
+		}
+		beginOffset, err := intAttribute(attributes, "beginOffset", 0)
+		if err != nil {
+			return nil, err
+		}
+		endOffset, err := intAttribute(attributes, "endOffset", 0)
+		if err != nil {
+			return nil, err
+		}
+		// -1 starts at the first character, and ends at the last, without
+		// applying the offsets
+		if begin < 0 {
+			begin = 0
+		} else {
+			begin += beginOffset
+		}
+		if end < 0 {
+			end = len(chars)
+		} else {
+			end += endOffset
+		}
+		if begin < 0 || end > len(chars) || begin > end {
+			return nil, fmt.Errorf("substring %d to %d is out of the bounds of %q", begin, end, input)
+		}
+		return string(chars[begin:end]), nil
+	},
+	"replace": func(input string, attributes map[string]interface{}) (interface{}, error) {
+		pattern, _ := attributes["regex"].(string)
+		replacement, _ := attributes["replacement"].(string)
+		result, err := replaceRegex(input, pattern, replacement)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	},
+	"replaceAll": func(input string, attributes map[string]interface{}) (interface{}, error) {
+		table, ok := attributes["table"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("table must be a map")
+		}
+		patterns := make([]string, 0, len(table))
+		for pattern := range table {
+			patterns = append(patterns, pattern)
+		}
+		sort.Strings(patterns)
+
+		result := input
+		for _, pattern := range patterns {
+			var err error
+			if result, err = replaceRegex(result, pattern, toString(table[pattern])); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	},
+	"split": func(input string, attributes map[string]interface{}) (interface{}, error) {
+		delimiter, _ := attributes["delimiter"].(string)
+		index, err := intAttribute(attributes, "index", -1)
+		if err != nil {
+			return nil, err
+		}
+		parts := strings.Split(input, delimiter)
+		if index < 0 || index >= len(parts) {
+			if isTrue(attributes["throws"]) {
+				return nil, fmt.Errorf("index %d is out of the %d parts of %q", index, len(parts), input)
+			}
+			return nil, nil
+		}
+		return parts[index], nil
+	},
+	"leftPad": func(input string, attributes map[string]interface{}) (interface{}, error) {
+		return pad(input, attributes, true)
+	},
+	"rightPad": func(input string, attributes map[string]interface{}) (interface{}, error) {
+		return pad(input, attributes, false)
+	},
+	"dateFormat": func(input string, attributes map[string]interface{}) (interface{}, error) {
+		inputFormat, _ := attributes["inputFormat"].(string)
+		outputFormat, _ := attributes["outputFormat"].(string)
+		return FormatDate(input, inputFormat, outputFormat)
+	},
+}
+
+// variables evaluates the attributes of a static or conditional transform
+// that are not part of its definition, for use as $name in its values
+func (e Evaluator) variables(attributes map[string]interface{}, input interface{}, depth int) (map[string]string, error) {
+	variables := map[string]string{}
+	for name, v := range attributes {
+		switch name {
+		case "value", "expression", "positiveCondition", "negativeCondition", "requiresPeriodicRefresh":
+			continue
+		}
+		value, err := e.value(v, input, depth)
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %w", name, err)
+		}
+		if value != nil {
+			variables[name] = toString(value)
+		}
+	}
+	return variables, nil
+}
+
+var velocityDirective = regexp.MustCompile(`#(if|foreach|set|else|elseif|end|macro)\b`)
+
+// template renders a static value, substituting $name and ${name} variables.
+// Velocity directives are not supported offline.
+func (e Evaluator) template(value string, attributes map[string]interface{}, input interface{}, depth int) (interface{}, error) {
+	if velocityDirective.MatchString(value) {
+		return nil, fmt.Errorf("%w: Velocity directives in static values", ErrUnsupported)
+	}
+	variables, err := e.variables(attributes, input, depth)
+	if err != nil {
+		return nil, err
+	}
+	return substitute(value, variables), nil
+}
+
+var variableReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// substitute replaces $name and ${name} with variables, leaving unknown
+// names as they are
+func substitute(value string, variables map[string]string) string {
+	return variableReference.ReplaceAllStringFunc(value, func(match string) string {
+		name := strings.Trim(match, "${}")
+		if replacement, ok := variables[name]; ok {
+			return replacement
+		}
+		return match
+	})
+}
+
+var javaGroupReference = regexp.MustCompile(`\$(\d+)`)
+
+// replaceRegex replaces matches of a Java regular expression, $1 style group
+// references included
+func replaceRegex(input string, pattern string, replacement string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid regex %q: %v", pattern, err)
+	}
+	return re.ReplaceAllString(input, javaGroupReference.ReplaceAllString(replacement, "$${$1}")), nil
+}
+
+func pad(input string, attributes map[string]interface{}, left bool) (interface{}, error) {
+	length, err := intAttribute(attributes, "length", -1)
+	if err != nil {
+		return nil, err
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("length is required")
+	}
+	padding, _ := attributes["padding"].(string)
+	if padding == "" {
+		padding = " "
+	}
+
+	missing := length - len([]rune(input))
+	if missing <= 0 {
+		return input, nil
+	}
+	fill := []rune(strings.Repeat(padding, missing))[:missing]
+	if left {
+		return string(fill) + input, nil
+	}
+	return input + string(fill), nil
+}
+
+// runeIndex converts a byte index of s to a character index
+func runeIndex(s string, index int) int {
+	if index < 0 {
+		return index
+	}
+	return len([]rune(s[:index]))
+}
+
+// intAttribute reads an integer attribute, given as a number or a string
+func intAttribute(attributes map[string]interface{}, name string, fallback int) (int, error) {
+	value, ok := attributes[name]
+	if !ok || value == nil {
+		return fallback, nil
+	}
+	switch v := value.(type) {
+	case float64:
+		return int(v), nil
+	case json.Number:
+		n, err := v.Int64()
+		return int(n), err
+	case string:
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, fmt.Errorf("%s must be a number, got %q", name, v)
+		}
+		return n, nil
+	}
+	return 0, fmt.Errorf("%s must be a number", name)
+}
+
+func isTrue(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
+
+// nullable returns nil for missing values and the string form of others
+func nullable(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return toString(value)
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]interface{}, []interface{}:
+		raw, _ := json.Marshal(v)
+		return string(raw)
+	}
+	return fmt.Sprint(value)
+}
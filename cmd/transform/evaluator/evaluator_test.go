@@ -0,0 +1,128 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	e := Evaluator{
+		Sample: Sample{
+			Identity: map[string]interface{}{
+				"attributes": map[string]interface{}{"firstname": "Jane", "lastname": "Doe", "department": "Engineering"},
+				"email":      "jane.doe@acme.com",
+			},
+			Accounts: map[string]map[string]interface{}{
+				"HR": {"hireDate": "2021-03-04T00:00:00Z", "country": "US", "employeeNumber": float64(42)},
+			},
+		},
+		Transforms: map[string]map[string]interface{}{
+			"Username": {"type": "lower", "attributes": map[string]interface{}{
+				"input": map[string]interface{}{"type": "concat", "attributes": map[string]interface{}{"values": []interface{}{
+					map[string]interface{}{"type": "identityAttribute", "attributes": map[string]interface{}{"name": "firstname"}},
+					".",
+					map[string]interface{}{"type": "identityAttribute", "attributes": map[string]interface{}{"name": "lastname"}},
+				}}},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		definition string
+		input      interface{}
+		expected   interface{}
+	}{
+		{"reference", `{"type": "reference", "attributes": {"id": "Username"}}`, nil, "jane.doe"},
+		{"upper", `{"type": "upper"}`, "abc", "ABC"},
+		{"trim", `{"type": "trim"}`, "  abc ", "abc"},
+		{"null input", `{"type": "upper"}`, nil, nil},
+		{"identity top level", `{"type": "identityAttribute", "attributes": {"name": "email"}}`, nil, "jane.doe@acme.com"},
+		{"account number", `{"type": "accountAttribute", "attributes": {"sourceName": "HR", "attributeName": "employeeNumber"}}`, nil, "42"},
+		{"substring", `{"type": "substring", "attributes": {"begin": 1, "end": 3}}`, "abcdef", "bc"},
+		{"substring to end", `{"type": "substring", "attributes": {"begin": 2}}`, "abcdef", "cdef"},
+		{"substring from start", `{"type": "substring", "attributes": {"begin": -1, "beginOffset": 2, "end": 3}}`, "abcdef", "abc"},
+		{"replace", `{"type": "replace", "attributes": {"regex": "(\\w+)@(\\w+)", "replacement": "$2/$1"}}`, "jane@acme", "acme/jane"},
+		{"replaceAll", `{"type": "replaceAll", "attributes": {"table": {"-": " ", "_": " "}}}`, "a-b_c", "a b c"},
+		{"split", `{"type": "split", "attributes": {"delimiter": ",", "index": 1}}`, "a,b,c", "b"},
+		{"split out of range", `{"type": "split", "attributes": {"delimiter": ",", "index": 5}}`, "a,b,c", nil},
+		{"leftPad", `{"type": "leftPad", "attributes": {"length": "5", "padding": "0"}}`, "42", "00042"},
+		{"getEndOfString", `{"type": "getEndOfString", "attributes": {"numChars": 3}}`, "abcdef", "def"},
+		{"indexOf", `{"type": "indexOf", "attributes": {"substring": "c"}}`, "abc", "2"},
+		{"lookup", `{"type": "lookup", "attributes": {"table": {"US": "United States", "default": "Other"}}}`, "US", "United States"},
+		{"lookup default", `{"type": "lookup", "attributes": {"table": {"US": "United States", "default": "Other"}}}`, "FR", "Other"},
+		{"static", `{"type": "static", "attributes": {"dept": {"type": "identityAttribute", "attributes": {"name": "department"}}, "value": "Dept: ${dept}"}}`, nil, "Dept: Engineering"},
+		{"conditional", `{"type": "conditional", "attributes": {"expression": "$dept eq Engineering", "positiveCondition": "R&D", "negativeCondition": "$dept", "dept": {"type": "identityAttribute", "attributes": {"name": "department"}}}}`, nil, "R&D"},
+		{"firstValid", `{"type": "firstValid", "attributes": {"values": [{"type": "identityAttribute", "attributes": {"name": "nickname"}}, "fallback"]}}`, nil, "fallback"},
+		{"dateFormat", `{"type": "dateFormat", "attributes": {"inputFormat": "ISO8601", "outputFormat": "MM/dd/yyyy", "input": {"type": "accountAttribute", "attributes": {"sourceName": "HR", "attributeName": "hireDate"}}}}`, nil, "03/04/2021"},
+		{"dateFormat epoch", `{"type": "dateFormat", "attributes": {"inputFormat": "EPOCH_TIME_JAVA", "outputFormat": "ISO8601"}}`, "0", "1970-01-01T00:00:00.000Z"},
+		{"diacritics", `{"type": "decomposeDiacriticalMarks"}`, "Amélie", "Amelie"},
+	}
+
+	for _, test := range tests {
+		var definition map[string]interface{}
+		if err := json.Unmarshal([]byte(test.definition), &definition); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		result, err := e.Evaluate(definition, test.input)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if result != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, result)
+		}
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	e := Evaluator{Transforms: map[string]map[string]interface{}{
+		"Loop": {"type": "reference", "attributes": map[string]interface{}{"id": "Loop"}},
+	}}
+
+	_, err := e.Evaluate(map[string]interface{}{"type": "rule", "attributes": map[string]interface{}{"name": "Custom"}}, "x")
+	if !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected rule transforms to be unsupported, got %v", err)
+	}
+
+	_, err = e.Evaluate(map[string]interface{}{"type": "reference", "attributes": map[string]interface{}{"id": "Loop"}}, "x")
+	if err == nil {
+		t.Error("expected a reference cycle to fail")
+	}
+
+	_, err = e.Evaluate(map[string]interface{}{"type": "lookup", "attributes": map[string]interface{}{"table": map[string]interface{}{"US": "United States"}}}, "FR")
+	if err == nil {
+		t.Error("expected a lookup without a match or default to fail")
+	}
+}
+
+func TestFormatDateWin32(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		in     string
+		out    string
+		expect string
+	}{
+		{"to file time", "2024-01-01T00:00:00Z", "ISO8601", "EPOCH_TIME_WIN32", "133485408000000000"},
+		{"to file time with milliseconds", "2024-01-01T00:00:00.123Z", "ISO8601", "EPOCH_TIME_WIN32", "133485408001230000"},
+		{"to file time at the Unix epoch", "1970-01-01T00:00:00Z", "ISO8601", "EPOCH_TIME_WIN32", "116444736000000000"},
+		{"from file time", "133485408000000000", "EPOCH_TIME_WIN32", "ISO8601", "2024-01-01T00:00:00.000Z"},
+		{"from file time with milliseconds", "133485408001230000", "EPOCH_TIME_WIN32", "ISO8601", "2024-01-01T00:00:00.123Z"},
+		{"from file time zero", "0", "EPOCH_TIME_WIN32", "ISO8601", "1601-01-01T00:00:00.000Z"},
+		{"from file time to Java epoch", "116444736000000000", "EPOCH_TIME_WIN32", "EPOCH_TIME_JAVA", "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatDate(tt.input, tt.in, tt.out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.expect {
+				t.Errorf("FormatDate(%q, %s, %s) = %q, want %q", tt.input, tt.in, tt.out, got, tt.expect)
+			}
+		})
+	}
+}
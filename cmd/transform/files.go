@@ -0,0 +1,130 @@
+package transform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// transformFile is a transform definition read from a file. Server fields
+// like id and internal are ignored.
+type transformFile struct {
+	Path       string
+	Name       string
+	Type       string
+	Attributes map[string]interface{}
+}
+
+// readTransformFile reads a transform from a JSON file, as written by sail
+// transform download
+func readTransformFile(path string) (transformFile, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return transformFile{}, err
+	}
+
+	var content struct {
+		Name       string                 `json:"name"`
+		Type       string                 `json:"type"`
+		Attributes map[string]interface{} `json:"attributes"`
+	}
+	if err := json.Unmarshal(raw, &content); err != nil {
+		return transformFile{}, fmt.Errorf("invalid transform file %s: %v", path, err)
+	}
+	if content.Name == "" || content.Type == "" {
+		return transformFile{}, fmt.Errorf("transform file %s must have a name and a type", path)
+	}
+	if content.Attributes == nil {
+		content.Attributes = map[string]interface{}{}
+	}
+
+	return transformFile{Path: path, Name: content.Name, Type: content.Type, Attributes: content.Attributes}, nil
+}
+
+// readTransformDir reads the transforms of the JSON files under dir, sorted by
+// path. Two files defining the same transform name are an error.
+func readTransformDir(dir string) ([]transformFile, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && strings.EqualFold(filepath.Ext(path), ".json") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var transforms []transformFile
+	names := map[string]string{}
+	for _, path := range paths {
+		transform, err := readTransformFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if previous, ok := names[transform.Name]; ok {
+			return nil, fmt.Errorf("transform %q is defined in both %s and %s", transform.Name, previous, path)
+		}
+		names[transform.Name] = path
+		transforms = append(transforms, transform)
+	}
+
+	return transforms, nil
+}
+
+// normalizeAttributes decodes attributes the same way whatever their source,
+// so they can be compared
+func normalizeAttributes(attributes map[string]interface{}) (interface{}, error) {
+	raw, err := json.Marshal(attributes)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(raw, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// referencedTransforms returns the sorted names of the transforms referenced
+// by a transform, when it is a reference transform, or by the reference
+// transforms nested in its attributes
+func referencedTransforms(transformType string, attributes map[string]interface{}) []string {
+	names := map[string]bool{}
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if v["type"] == "reference" {
+				if nested, ok := v["attributes"].(map[string]interface{}); ok {
+					if id, ok := nested["id"].(string); ok && id != "" {
+						names[id] = true
+					}
+				}
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(map[string]interface{}{"type": transformType, "attributes": attributes})
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
@@ -0,0 +1,468 @@
+package transform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	sailpoint "github.com/sailpoint-oss/golang-sdk/v2"
+	v3 "github.com/sailpoint-oss/golang-sdk/v2/api_v3"
+	"github.com/sailpoint-oss/sailpoint-cli/cmd/transform/evaluator"
+	transmodel "github.com/sailpoint-oss/sailpoint-cli/cmd/transform/model"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/config"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/output"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/sdk"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// namedDateFormats are the dateFormat formats that are not Java patterns
+var namedDateFormats = []string{"ISO8601", "LDAP", "PEOPLE_SOFT", "EPOCH_TIME_JAVA", "EPOCH_TIME_WIN32"}
+
+// lintReport is the outcome of sail transform lint
+type lintReport struct {
+	Issues []transmodel.LintIssue      `json:"issues"`
+	Usages []transmodel.TransformUsage `json:"usages"`
+	// Unused are the linted transforms no identity profile uses
+	Unused []string `json:"unused,omitempty"`
+}
+
+func (r lintReport) errors() int {
+	count := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == transmodel.SeverityError {
+			count++
+		}
+	}
+	return count
+}
+
+// readLintTargets reads the transforms of files and folders
+func readLintTargets(paths []string) ([]transformFile, error) {
+	var files []transformFile
+	names := map[string]string{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var read []transformFile
+		if info.IsDir() {
+			read, err = readTransformDir(path)
+		} else {
+			var file transformFile
+			file, err = readTransformFile(path)
+			read = []transformFile{file}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range read {
+			if previous, ok := names[file.Name]; ok {
+				return nil, fmt.Errorf("transform %q is defined in both %s and %s", file.Name, previous, file.Path)
+			}
+			names[file.Name] = file.Path
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// lintStructure checks a transform definition against the rules of its type,
+// then the transforms nested in its attributes
+func lintStructure(file transformFile) []transmodel.LintIssue {
+	var issues []transmodel.LintIssue
+	report := func(path string, severity string, format string, args ...interface{}) {
+		issues = append(issues, transmodel.LintIssue{File: file.Path, Transform: file.Name, Path: path, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	var check func(path string, transformType string, attributes map[string]interface{})
+	check = func(path string, transformType string, attributes map[string]interface{}) {
+		rule, known := transmodel.TransformTypes[transformType]
+		if !known {
+			report(path, transmodel.SeverityError, "unknown transform type %q", transformType)
+		}
+
+		for _, name := range rule.Required {
+			if value, ok := attributes[name]; !ok || value == nil || value == "" {
+				report(path, transmodel.SeverityError, "%s transforms need the %s attribute", transformType, name)
+			}
+		}
+		for _, name := range rule.Present {
+			if value, ok := attributes[name]; !ok || value == nil {
+				report(path, transmodel.SeverityError, "%s transforms need the %s attribute", transformType, name)
+			}
+		}
+		if len(rule.OneOf) > 0 {
+			found := false
+			for _, name := range rule.OneOf {
+				if value, ok := attributes[name]; ok && value != "" {
+					found = true
+				}
+			}
+			if !found {
+				report(path, transmodel.SeverityError, "%s transforms need one of the %s attributes", transformType, strings.Join(rule.OneOf, ", "))
+			}
+		}
+		for _, name := range rule.Lists {
+			if value, ok := attributes[name]; ok {
+				if _, isList := value.([]interface{}); !isList {
+					report(path, transmodel.SeverityError, "%s must be a list", name)
+				}
+			}
+		}
+		for _, name := range rule.Maps {
+			if value, ok := attributes[name]; ok {
+				if _, isMap := value.(map[string]interface{}); !isMap {
+					report(path, transmodel.SeverityError, "%s must be a map", name)
+				}
+			}
+		}
+
+		switch transformType {
+		case "conditional":
+			if expression, ok := attributes["expression"].(string); ok && !strings.Contains(expression, " eq ") {
+				report(path, transmodel.SeverityError, "expression %q must have the form \"ValueA eq ValueB\"", expression)
+			}
+		case "replace":
+			if pattern, ok := attributes["regex"].(string); ok {
+				if _, err := regexp.Compile(pattern); err != nil {
+					report(path, transmodel.SeverityWarning, "regex %q could not be checked: %v", pattern, err)
+				}
+			}
+		case "dateFormat":
+			for _, name := range []string{"inputFormat", "outputFormat"} {
+				format, ok := attributes[name].(string)
+				if !ok || containsString(namedDateFormats, format) {
+					continue
+				}
+				if _, err := evaluator.JavaLayout(format); err != nil {
+					report(path, transmodel.SeverityWarning, "%s could not be checked: %v", name, err)
+				}
+			}
+		case "lookup":
+			if table, ok := attributes["table"].(map[string]interface{}); ok {
+				if _, hasDefault := table["default"]; !hasDefault {
+					report(path, transmodel.SeverityWarning, "the lookup table has no default entry, unmatched values fail")
+				}
+			}
+		}
+
+		for _, key := range sortedKeys(attributes) {
+			checkNested(joinPath(path, "attributes."+key), attributes[key], check)
+		}
+	}
+
+	check("", file.Type, file.Attributes)
+	return issues
+}
+
+// checkNested finds the transforms nested in an attribute value
+func checkNested(path string, value interface{}, check func(path string, transformType string, attributes map[string]interface{})) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if transformType, ok := v["type"].(string); ok {
+			attributes, _ := v["attributes"].(map[string]interface{})
+			if attributes == nil {
+				attributes = map[string]interface{}{}
+			}
+			check(path, transformType, attributes)
+			return
+		}
+		for _, key := range sortedKeys(v) {
+			checkNested(path+"."+key, v[key], check)
+		}
+	case []interface{}:
+		for i, child := range v {
+			checkNested(path+"["+strconv.Itoa(i)+"]", child, check)
+		}
+	}
+}
+
+func joinPath(path string, child string) string {
+	if path == "" {
+		return child
+	}
+	return path + "." + child
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// referenceCycles returns the reference cycles of a graph of transform names,
+// each starting with its smallest name and ending where it starts
+func referenceCycles(graph map[string][]string) [][]string {
+	names := make([]string, 0, len(graph))
+	for name := range graph {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var cycles [][]string
+	seen := map[string]bool{}
+	state := map[string]int{}
+	var stack []string
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = 1
+		stack = append(stack, name)
+		for _, next := range graph[name] {
+			switch state[next] {
+			case 0:
+				if _, ok := graph[next]; ok {
+					visit(next)
+				}
+			case 1:
+				start := len(stack) - 1
+				for stack[start] != next {
+					start--
+				}
+				cycle := append([]string{}, stack[start:]...)
+				smallest := 0
+				for i := range cycle {
+					if cycle[i] < cycle[smallest] {
+						smallest = i
+					}
+				}
+				cycle = append(cycle[smallest:], cycle[:smallest]...)
+				cycle = append(cycle, cycle[0])
+				if key := strings.Join(cycle, "\x00"); !seen[key] {
+					seen[key] = true
+					cycles = append(cycles, cycle)
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = 2
+	}
+	for _, name := range names {
+		if state[name] == 0 {
+			visit(name)
+		}
+	}
+	return cycles
+}
+
+// lintTransforms lints local transforms along with the tenant ones, which the
+// local ones override by name, and the identity profiles using them
+func lintTransforms(files []transformFile, tenant []v3.TransformRead, profiles []transmodel.IdentityProfile) lintReport {
+	report := lintReport{Issues: []transmodel.LintIssue{}, Usages: []transmodel.TransformUsage{}}
+
+	graph := map[string][]string{}
+	for _, transform := range tenant {
+		graph[transform.Name] = referencedTransforms(transform.Type, transform.Attributes)
+	}
+	paths := map[string]string{}
+	for _, file := range files {
+		graph[file.Name] = referencedTransforms(file.Type, file.Attributes)
+		paths[file.Name] = file.Path
+	}
+
+	for _, file := range files {
+		report.Issues = append(report.Issues, lintStructure(file)...)
+		for _, name := range graph[file.Name] {
+			if _, ok := graph[name]; !ok {
+				report.Issues = append(report.Issues, transmodel.LintIssue{File: file.Path, Transform: file.Name, Severity: transmodel.SeverityError, Message: fmt.Sprintf("references the missing transform %q", name)})
+			}
+		}
+	}
+
+	for _, cycle := range referenceCycles(graph) {
+		local := false
+		for _, name := range cycle {
+			if _, ok := paths[name]; ok {
+				local = true
+			}
+		}
+		if local {
+			report.Issues = append(report.Issues, transmodel.LintIssue{File: paths[cycle[0]], Transform: cycle[0], Severity: transmodel.SeverityError, Message: "reference cycle " + strings.Join(cycle, " -> ")})
+		}
+	}
+
+	used := map[string]bool{}
+	for _, profile := range profiles {
+		for _, attribute := range profile.IdentityAttributeConfig.AttributeTransforms {
+			definition := attribute.TransformDefinition
+			attributes, _ := definition.Attributes.(map[string]interface{})
+			direct := referencedTransforms(definition.Type, attributes)
+
+			// Transforms used through references are used by the profile too
+			via := map[string]string{}
+			queue := append([]string{}, direct...)
+			for _, name := range direct {
+				via[name] = ""
+			}
+			for len(queue) > 0 {
+				name := queue[0]
+				queue = queue[1:]
+				if _, ok := graph[name]; !ok {
+					report.Issues = append(report.Issues, transmodel.LintIssue{Transform: name, Severity: transmodel.SeverityWarning, Message: fmt.Sprintf("used by identity profile %q for %s but found neither locally nor in the tenant", profile.Name, attribute.IdentityAttributeName)})
+					continue
+				}
+				for _, next := range graph[name] {
+					if _, ok := via[next]; !ok {
+						via[next] = name
+						queue = append(queue, next)
+					}
+				}
+			}
+
+			for name, through := range via {
+				if _, ok := paths[name]; !ok {
+					continue
+				}
+				used[name] = true
+				identityAttribute := attribute.IdentityAttributeName
+				if through != "" {
+					identityAttribute += " (via " + through + ")"
+				}
+				report.Usages = append(report.Usages, transmodel.TransformUsage{Transform: name, ProfileId: profile.Id, ProfileName: profile.Name, IdentityAttribute: identityAttribute})
+			}
+		}
+	}
+
+	for _, file := range files {
+		if !used[file.Name] {
+			report.Unused = append(report.Unused, file.Name)
+		}
+	}
+	sort.Strings(report.Unused)
+	sort.SliceStable(report.Usages, func(i, j int) bool {
+		a, b := report.Usages[i], report.Usages[j]
+		if a.Transform != b.Transform {
+			return a.Transform < b.Transform
+		}
+		if a.ProfileName != b.ProfileName {
+			return a.ProfileName < b.ProfileName
+		}
+		return a.IdentityAttribute < b.IdentityAttribute
+	})
+
+	return report
+}
+
+func writeLintReport(w io.Writer, report lintReport, profilesChecked bool) {
+	if len(report.Issues) > 0 {
+		var entries [][]string
+		for _, issue := range report.Issues {
+			entries = append(entries, []string{issue.Severity, issue.Transform, issue.File, issue.Path, issue.Message})
+		}
+		output.WriteTable(w, []string{"Severity", "Transform", "File", "Path", "Message"}, entries, "")
+	}
+	errors := report.errors()
+	_, _ = fmt.Fprintf(w, "%d errors, %d warnings\n", errors, len(report.Issues)-errors)
+
+	if !profilesChecked {
+		return
+	}
+
+	_, _ = fmt.Fprintln(w)
+	var entries [][]string
+	for _, usage := range report.Usages {
+		entries = append(entries, []string{usage.Transform, usage.ProfileName, usage.IdentityAttribute})
+	}
+	for _, name := range report.Unused {
+		entries = append(entries, []string{name, "(unused)", ""})
+	}
+	output.WriteTable(w, []string{"Transform", "Identity Profile", "Identity Attribute"}, entries, "")
+}
+
+// fetchLintContext lists the transforms and identity profiles of the tenant
+func fetchLintContext(apiClient *sailpoint.APIClient) ([]v3.TransformRead, []transmodel.IdentityProfile, error) {
+	tenant, resp, err := sailpoint.PaginateWithDefaults[v3.TransformRead](apiClient.V3.TransformsAPI.ListTransforms(context.TODO()))
+	if err != nil {
+		return nil, nil, sdk.HandleSDKError(resp, err)
+	}
+
+	identityProfiles, resp, err := sailpoint.PaginateWithDefaults[v3.IdentityProfile](apiClient.V3.IdentityProfilesAPI.ListIdentityProfiles(context.TODO()))
+	if err != nil {
+		return nil, nil, sdk.HandleSDKError(resp, err)
+	}
+
+	raw, err := json.Marshal(identityProfiles)
+	if err != nil {
+		return nil, nil, err
+	}
+	var profiles []transmodel.IdentityProfile
+	if err := json.Unmarshal(raw, &profiles); err != nil {
+		return nil, nil, err
+	}
+
+	return tenant, profiles, nil
+}
+
+func newLintCommand() *cobra.Command {
+	var offline bool
+	var format string
+	cmd := &cobra.Command{
+		Use:     "lint [files or folders]",
+		Short:   "Check transform files before deploying them",
+		Long:    "\nCheck transform files before deploying them\n\nThe attributes of each transform, and of the transforms nested in it, are checked against the rules of its type. References to missing transforms and reference cycles are found across the files and the transforms of the tenant, and the identity profiles using each transform are listed. Use --offline to only check the files. The command fails when errors are found.\n\n",
+		Example: "sail transform lint transform_files\nsail transform lint transforms/Username.json transforms/Email.json\nsail transform lint transforms --offline -f json",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "text" && format != "json" {
+				return fmt.Errorf("invalid format %q, use text or json", format)
+			}
+
+			files, err := readLintTargets(args)
+			if err != nil {
+				return err
+			}
+
+			var tenant []v3.TransformRead
+			var profiles []transmodel.IdentityProfile
+			if !offline {
+				apiClient, err := config.InitAPIClient(false)
+				if err != nil {
+					return err
+				}
+				tenant, profiles, err = fetchLintContext(apiClient)
+				if err != nil {
+					return err
+				}
+			}
+
+			report := lintTransforms(files, tenant, profiles)
+
+			if format == "json" {
+				fmt.Fprintln(cmd.OutOrStdout(), util.PrettyPrint(report))
+			} else {
+				writeLintReport(cmd.OutOrStdout(), report, !offline)
+			}
+
+			if errors := report.errors(); errors > 0 {
+				return fmt.Errorf("%d errors found in %d transforms", errors, len(files))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&offline, "offline", false, "Only check the files, without the transforms and identity profiles of the tenant")
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "Output format, text or json")
+
+	return cmd
+}
@@ -0,0 +1,139 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v3 "github.com/sailpoint-oss/golang-sdk/v2/api_v3"
+	transmodel "github.com/sailpoint-oss/sailpoint-cli/cmd/transform/model"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+)
+
+func TestLintTransforms(t *testing.T) {
+	files := []transformFile{
+		{Path: "Username.json", Name: "Username", Type: "lower", Attributes: map[string]interface{}{
+			"input": map[string]interface{}{"type": "reference", "attributes": map[string]interface{}{"id": "Base Name"}},
+		}},
+		{Path: "Base Name.json", Name: "Base Name", Type: "concat", Attributes: map[string]interface{}{
+			"values": []interface{}{
+				map[string]interface{}{"type": "identityAttribute", "attributes": map[string]interface{}{}},
+				map[string]interface{}{"type": "reference", "attributes": map[string]interface{}{"id": "Tenant Only"}},
+			},
+		}},
+		{Path: "Ping.json", Name: "Ping", Type: "reference", Attributes: map[string]interface{}{"id": "Pong"}},
+		{Path: "Pong.json", Name: "Pong", Type: "reference", Attributes: map[string]interface{}{"id": "Ping"}},
+		{Path: "Typo.json", Name: "Typo", Type: "uper", Attributes: map[string]interface{}{}},
+		{Path: "Missing.json", Name: "Missing", Type: "reference", Attributes: map[string]interface{}{"id": "Nowhere"}},
+		{Path: "Country.json", Name: "Country", Type: "lookup", Attributes: map[string]interface{}{"table": map[string]interface{}{"US": "United States"}}},
+	}
+	tenant := []v3.TransformRead{
+		{Id: "1", Name: "Tenant Only", Type: "trim", Attributes: map[string]interface{}{}},
+	}
+	profiles := []transmodel.IdentityProfile{
+		{Id: "p1", Name: "Employees", IdentityAttributeConfig: transmodel.IdentityAttributeConfig{AttributeTransforms: []transmodel.AttributeTransform{
+			{IdentityAttributeName: "uid", TransformDefinition: transmodel.TransformDefinition{Type: "reference", Attributes: map[string]interface{}{"id": "Username"}}},
+		}}},
+	}
+
+	report := lintTransforms(files, tenant, profiles)
+
+	expected := []string{
+		"Base Name|attributes.values[0]|error|identityAttribute transforms need the name attribute",
+		"Typo||error|unknown transform type \"uper\"",
+		"Missing||error|references the missing transform \"Nowhere\"",
+		"Ping||error|reference cycle Ping -> Pong -> Ping",
+		"Country||warning|the lookup table has no default entry, unmatched values fail",
+	}
+	found := map[string]bool{}
+	for _, issue := range report.Issues {
+		found[strings.Join([]string{issue.Transform, issue.Path, issue.Severity, issue.Message}, "|")] = true
+	}
+	for _, issue := range expected {
+		if !found[issue] {
+			t.Errorf("expected issue %s, got %+v", issue, report.Issues)
+		}
+	}
+	if len(report.Issues) != len(expected) {
+		t.Errorf("expected %d issues, got %+v", len(expected), report.Issues)
+	}
+
+	usages := map[string]string{}
+	for _, usage := range report.Usages {
+		usages[usage.Transform] = usage.ProfileName + " " + usage.IdentityAttribute
+	}
+	if usages["Username"] != "Employees uid" || usages["Base Name"] != "Employees uid (via Username)" {
+		t.Errorf("expected Username and Base Name to be used by Employees, got %v", usages)
+	}
+	if _, ok := usages["Tenant Only"]; ok {
+		t.Error("expected only local transforms in the usages")
+	}
+	if strings.Join(report.Unused, ",") != "Country,Missing,Ping,Pong,Typo" {
+		t.Errorf("expected the unused transforms, got %v", report.Unused)
+	}
+}
+
+func TestLintStructureEmptyValues(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       transformFile
+		wantIssues []string
+	}{
+		{
+			name: "replace with an empty replacement",
+			file: transformFile{Name: "Strip", Type: "replace", Attributes: map[string]interface{}{"regex": "[^a-z]", "replacement": ""}},
+		},
+		{
+			name: "conditional with empty conditions",
+			file: transformFile{Name: "Flag", Type: "conditional", Attributes: map[string]interface{}{"expression": "$a eq b", "positiveCondition": "", "negativeCondition": ""}},
+		},
+		{
+			name:       "replace without a replacement",
+			file:       transformFile{Name: "Strip", Type: "replace", Attributes: map[string]interface{}{"regex": "[^a-z]"}},
+			wantIssues: []string{"replace transforms need the replacement attribute"},
+		},
+		{
+			name:       "conditional with a null condition",
+			file:       transformFile{Name: "Flag", Type: "conditional", Attributes: map[string]interface{}{"expression": "$a eq b", "positiveCondition": "yes", "negativeCondition": nil}},
+			wantIssues: []string{"conditional transforms need the negativeCondition attribute"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messages []string
+			for _, issue := range lintStructure(tt.file) {
+				messages = append(messages, issue.Message)
+			}
+			if strings.Join(messages, "\n") != strings.Join(tt.wantIssues, "\n") {
+				t.Errorf("expected issues %q, got %q", tt.wantIssues, messages)
+			}
+		})
+	}
+}
+
+func TestLintCommandJSON(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Lower.json"), []byte(`{"name": "Lower", "type": "lower", "attributes": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newLintCommand()
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{dir, "--offline", "-f", "json"})
+
+	// The report goes to the real stdout, cobra's default output, so it can be
+	// piped
+	stdout, err := util.CaptureStdout(cmd.Execute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report map[string]interface{}
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("expected a JSON report on stdout, got %q: %v", stdout, err)
+	}
+}
@@ -71,6 +71,8 @@ type ObjectRef struct {
 	Name string `json:"name"`
 }
 type IdentityProfile struct {
+	Id                      string                  `json:"id"`
+	Name                    string                  `json:"name"`
 	AuthoritativeSource     ObjectRef               `json:"authoritativeSource"`
 	IdentityAttributeConfig IdentityAttributeConfig `json:"identityAttributeConfig"`
 }
@@ -135,3 +137,84 @@ func MakePreviewBodyExplicit(identityAttribute string, transformData map[string]
 
 	return previewBody
 }
+
+// TransformUsage is an identity attribute of an identity profile computed
+// with a transform
+type TransformUsage struct {
+	Transform         string `json:"transform"`
+	ProfileId         string `json:"profileId"`
+	ProfileName       string `json:"profileName"`
+	IdentityAttribute string `json:"identityAttribute"`
+}
+
+// Lint issue severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// LintIssue is a problem found in a transform definition
+type LintIssue struct {
+	File      string `json:"file,omitempty"`
+	Transform string `json:"transform"`
+	Path      string `json:"path,omitempty"`
+	Severity  string `json:"severity"`
+	Message   string `json:"message"`
+}
+
+// TypeRule describes the attributes a transform type needs
+type TypeRule struct {
+	// Required attributes must be set
+	Required []string
+	// Present attributes must be set but may be empty, such as a replacement
+	// that deletes what it matched
+	Present []string
+	// OneOf needs at least one of these attributes to be set
+	OneOf []string
+	// Lists and Maps are attributes that must hold a list or a map
+	Lists []string
+	Maps  []string
+}
+
+// TransformTypes are the rules of the known transform types
+var TransformTypes = map[string]TypeRule{
+	"accountAttribute":              {Required: []string{"attributeName"}, OneOf: []string{"sourceName", "applicationId", "applicationName"}},
+	"base64Decode":                  {},
+	"base64Encode":                  {},
+	"concat":                        {Required: []string{"values"}, Lists: []string{"values"}},
+	"conditional":                   {Required: []string{"expression"}, Present: []string{"positiveCondition", "negativeCondition"}},
+	"dateCompare":                   {Required: []string{"firstDate", "secondDate", "operator"}, Present: []string{"positiveCondition", "negativeCondition"}},
+	"dateFormat":                    {},
+	"dateMath":                      {Required: []string{"expression"}},
+	"decomposeDiacriticalMarks":     {},
+	"displayName":                   {},
+	"e164phone":                     {},
+	"firstValid":                    {Required: []string{"values"}, Lists: []string{"values"}},
+	"generateRandomString":          {Required: []string{"name", "operation"}},
+	"getEndOfString":                {Required: []string{"numChars"}},
+	"getReferenceIdentityAttribute": {Required: []string{"variable", "uid"}},
+	"identityAttribute":             {Required: []string{"name"}},
+	"indexOf":                       {Required: []string{"substring"}},
+	"iso3166":                       {},
+	"lastIndexOf":                   {Required: []string{"substring"}},
+	"leftPad":                       {Required: []string{"length"}},
+	"lookup":                        {Required: []string{"table"}, Maps: []string{"table"}},
+	"lower":                         {},
+	"nameNormalizer":                {},
+	"normalizeNames":                {},
+	"randomAlphaNumeric":            {},
+	"randomNumeric":                 {},
+	"reference":                     {Required: []string{"id"}},
+	"replace":                       {Required: []string{"regex"}, Present: []string{"replacement"}},
+	"replaceAll":                    {Required: []string{"table"}, Maps: []string{"table"}},
+	"rfc5646":                       {},
+	"rightPad":                      {Required: []string{"length"}},
+	"rule":                          {Required: []string{"name"}},
+	"split":                         {Required: []string{"delimiter", "index"}},
+	"static":                        {Required: []string{"value"}},
+	"substring":                     {Required: []string{"begin"}},
+	"trim":                          {},
+	"upper":                         {},
+	"usernameGenerator":             {Required: []string{"patterns"}, Lists: []string{"patterns"}},
+	"uuid":                          {},
+}
@@ -6,7 +6,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
+	"strings"
 
 	"github.com/charmbracelet/log"
 	sailpoint "github.com/sailpoint-oss/golang-sdk/v2"
@@ -14,6 +16,7 @@ import (
 	v2024 "github.com/sailpoint-oss/golang-sdk/v2/api_v2024"
 	v3 "github.com/sailpoint-oss/golang-sdk/v2/api_v3"
 
+	"github.com/sailpoint-oss/sailpoint-cli/cmd/transform/evaluator"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/config"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/output"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/sdk"
@@ -29,20 +32,38 @@ func newPreviewCommand() *cobra.Command {
 	var filepath string
 	var profile string
 	var identity string
-	var identityProfile *v2024.IdentityProfile
-	var identityAttributeConfig *v2024.IdentityAttributeConfig
 	var identityPreview *v2024.IdentityPreviewResponse
+	var query string
+	var limit int32
+	var attribute string
+	var compareCurrent bool
+	var csvPath string
+	var samplePath string
+	var directory string
+	var input string
+	var live bool
 	cmd := &cobra.Command{
 		Use:     "preview",
 		Short:   "Preview a transform result in Identity Security Cloud",
-		Long:    "\nPreview a transform result in Identity Security Cloud\n\n",
-		Example: "sail transform preview | sail transform pre",
+		Long:    "\nPreview a transform result in Identity Security Cloud\n\nWith --sample, the transform is evaluated offline against the identity and account attributes of a sample JSON file, without changing the tenant:\n\n  {\n    \"identity\": {\"attributes\": {\"firstname\": \"Jane\", \"department\": \"Engineering\"}},\n    \"accounts\": {\"Active Directory\": {\"sAMAccountName\": \"jdoe\"}}\n  }\n\nThe common transform types are supported offline: " + strings.Join(evaluator.SupportedTypes, ", ") + ". --input sets the value transforms without an input attribute receive, and --directory the folder of the transforms that reference transforms point to.\n\nWith --live, or without a sample, a temporary transform and identity attribute are created in the tenant to preview the transform on an identity, and deleted afterwards.\n\nWith --query, the transform is previewed in the tenant for each identity matching the search query, up to --limit, with the identity profile of each identity. --compare-current shows the current value of the --attribute identity attribute next to the result and flags the identities whose value would change. --csv exports the results.\n\n",
+		Example: "sail transform preview -f transform.json --sample identity.json\nsail transform preview -f transform.json --sample identity.json --input \"Jane Doe\" -d transform_files\nsail transform preview --live -f transform.json --profile <profileID> --identity <identityID>\nsail transform preview -f transform.json --query \"attributes.department:Engineering\" --limit 100 --attribute uid --compare-current --csv uid-preview.csv",
 		Aliases: []string{"pre"},
 		Args:    cobra.OnlyValidArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var transform v2024.Transform
 			var decoder *json.Decoder
 
+			if samplePath != "" && !live {
+				if filepath == "" {
+					return fmt.Errorf("provide the transform to preview with --file")
+				}
+				opts := previewOptions{transformPath: filepath, samplePath: samplePath, directory: directory, resultOnly: resultOnly}
+				if cmd.Flags().Changed("input") {
+					opts.input = &input
+				}
+				return offlinePreview(cmd.OutOrStdout(), opts)
+			}
+
 			if profile == "" && identity == "" {
 				showLongCommand = true
 			}
@@ -79,21 +100,14 @@ func newPreviewCommand() *cobra.Command {
 				return err
 			}
 
-			transformObj, resp, err := apiClient.V2024.TransformsAPI.CreateTransform(context.TODO()).Transform(transform).Execute()
-
-			defer cleanupPreviewObjects(apiClient, transformObj.GetId())
-
+			session, cleanup, err := startPreviewSession(apiClient, transform)
 			if err != nil {
-				return sdk.HandleSDKError(resp, err)
+				return err
 			}
+			defer cleanup()
 
-			var attributeType = "string"
-			identityAttribute, resp, err := apiClient.Beta.IdentityAttributesAPI.CreateIdentityAttribute(context.TODO()).IdentityAttribute(beta.IdentityAttribute{Name: "sailpointCLIPreview", Type: *beta.NewNullableString(&attributeType)}).Execute()
-
-			defer cleanupIdentityAttribute(apiClient, identityAttribute.GetName())
-
-			if err != nil {
-				return sdk.HandleSDKError(resp, err)
+			if query != "" {
+				return batchPreview(cmd.OutOrStdout(), session, batchPreviewOptions{query: query, limit: limit, profile: profile, attribute: attribute, compareCurrent: compareCurrent, csvPath: csvPath})
 			}
 
 			if profile == "" {
@@ -108,14 +122,6 @@ func newPreviewCommand() *cobra.Command {
 				}
 			}
 
-			identityProfile, resp, err = apiClient.V2024.IdentityProfilesAPI.GetIdentityProfile(context.TODO(), profile).Execute()
-
-			if err != nil {
-				return sdk.HandleSDKError(resp, err)
-			}
-
-			identityAttributeConfig = identityProfile.IdentityAttributeConfig
-
 			if identity == "" {
 				searchObj, err := search.BuildSearch(fmt.Sprintf("identityProfile.id:%s", profile), []string{"name"}, []string{"identities"})
 				if err != nil {
@@ -135,36 +141,15 @@ func newPreviewCommand() *cobra.Command {
 				}
 			}
 
-			var transformType = "reference"
-			identityAttributeConfig.AttributeTransforms = append(identityAttributeConfig.AttributeTransforms, v2024.IdentityAttributeTransform{
-				IdentityAttributeName: &identityAttribute.Name,
-				TransformDefinition: &v2024.TransformDefinition{
-					Type: &transformType,
-					Attributes: map[string]interface{}{
-						"id": transformObj.GetName(),
-					},
-				},
-			})
-
-			var enabled = true
-			var request = v2024.IdentityPreviewRequest{
-				IdentityId: &identity,
-				IdentityAttributeConfig: &v2024.IdentityAttributeConfig{
-					Enabled:             &enabled,
-					AttributeTransforms: identityAttributeConfig.AttributeTransforms,
-				},
-			}
-
-			identityPreview, resp, err = apiClient.V2024.IdentityProfilesAPI.GenerateIdentityPreview(context.TODO()).IdentityPreviewRequest(request).Execute()
-
+			identityPreview, err = session.preview(identity, profile)
 			if err != nil {
-				//fmt.Fprintf(os.Stderr, "Full HTTP response: %v\n", r)
+				return err
 			}
 
 			var entries [][]string
 
 			for _, v := range identityPreview.PreviewAttributes {
-				if v.GetName() == "sailpointCLIPreview" {
+				if v.GetName() == previewAttributeName {
 					if v.GetErrorMessages() != nil {
 						errorMap, err := v.GetErrorMessages()[0].ToMap()
 						if err != nil {
@@ -206,6 +191,16 @@ func newPreviewCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&profile, "profile", "p", "", "The identity profile of the transform you wish to preview")
 	cmd.Flags().StringVarP(&identity, "identity", "i", "", "The identity you wish to preview the transform with")
 	cmd.Flags().BoolVarP(&resultOnly, "result-only", "r", false, "Only show the result of the transform")
+	cmd.Flags().StringVarP(&samplePath, "sample", "s", "", "Sample JSON file of identity and account attributes to evaluate the transform against offline")
+	cmd.Flags().StringVar(&input, "input", "", "Value given to the transform when it has no input attribute, offline only")
+	cmd.Flags().StringVarP(&directory, "directory", "d", "", "Folder of transform files for the references of the transform, offline only")
+	cmd.Flags().BoolVar(&live, "live", false, "Preview the transform in the tenant, even with a sample")
+	cmd.Flags().StringVarP(&query, "query", "q", "", "Search query of the identities to preview the transform with, in batch")
+	cmd.Flags().Int32Var(&limit, "limit", 50, "Maximum number of identities to preview with --query")
+	cmd.Flags().StringVarP(&attribute, "attribute", "a", "", "Identity attribute the transform is meant for, to compare with --compare-current")
+	cmd.Flags().BoolVar(&compareCurrent, "compare-current", false, "Show the current value of --attribute next to the transform result, and flag the identities it would change")
+	cmd.Flags().StringVar(&csvPath, "csv", "", "File to export the batch preview results to, as CSV")
+	cmd.MarkFlagsMutuallyExclusive("query", "identity")
 
 	return cmd
 }
@@ -242,6 +237,101 @@ func SelectIdentity[T search.Identity](identities []search.Identity) (string, er
 
 }
 
+// previewAttributeName is the temporary identity attribute a live preview
+// computes with the transform
+const previewAttributeName = "sailpointCLIPreview"
+
+// previewSession holds the temporary transform and identity attribute of a
+// live preview, and the identity profiles previewed with
+type previewSession struct {
+	apiClient     *sailpoint.APIClient
+	transformName string
+	profiles      map[string]*v2024.IdentityProfile
+}
+
+// startPreviewSession creates the temporary objects of a live preview. The
+// returned cleanup deletes them, and is safe to call when creation failed
+// half way.
+func startPreviewSession(apiClient *sailpoint.APIClient, transform v2024.Transform) (*previewSession, func(), error) {
+	var cleanups []func()
+	cleanup := func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}
+
+	transformObj, resp, err := apiClient.V2024.TransformsAPI.CreateTransform(context.TODO()).Transform(transform).Execute()
+	if err != nil {
+		return nil, cleanup, sdk.HandleSDKError(resp, err)
+	}
+	cleanups = append(cleanups, func() {
+		if err := cleanupPreviewObjects(apiClient, transformObj.GetId()); err != nil {
+			log.Warn("Unable to delete the preview transform", "transformID", transformObj.GetId(), "err", err)
+		}
+	})
+
+	var attributeType = "string"
+	identityAttribute, resp, err := apiClient.Beta.IdentityAttributesAPI.CreateIdentityAttribute(context.TODO()).IdentityAttribute(beta.IdentityAttribute{Name: previewAttributeName, Type: *beta.NewNullableString(&attributeType)}).Execute()
+	if err != nil {
+		return nil, cleanup, sdk.HandleSDKError(resp, err)
+	}
+	cleanups = append(cleanups, func() {
+		if err := cleanupIdentityAttribute(apiClient, identityAttribute.GetName()); err != nil {
+			log.Warn("Unable to delete the preview identity attribute", "name", identityAttribute.GetName(), "err", err)
+		}
+	})
+
+	return &previewSession{apiClient: apiClient, transformName: transformObj.GetName(), profiles: map[string]*v2024.IdentityProfile{}}, cleanup, nil
+}
+
+// preview computes the identity attributes of an identity, with the
+// attribute configuration of an identity profile plus the preview attribute
+func (s *previewSession) preview(identityId string, profileId string) (*v2024.IdentityPreviewResponse, error) {
+	identityProfile, ok := s.profiles[profileId]
+	if !ok {
+		var resp *http.Response
+		var err error
+		identityProfile, resp, err = s.apiClient.V2024.IdentityProfilesAPI.GetIdentityProfile(context.TODO(), profileId).Execute()
+		if err != nil {
+			return nil, sdk.HandleSDKError(resp, err)
+		}
+		s.profiles[profileId] = identityProfile
+	}
+
+	var attributeTransforms []v2024.IdentityAttributeTransform
+	if identityProfile.IdentityAttributeConfig != nil {
+		attributeTransforms = append(attributeTransforms, identityProfile.IdentityAttributeConfig.AttributeTransforms...)
+	}
+
+	var attributeName = previewAttributeName
+	var transformType = "reference"
+	attributeTransforms = append(attributeTransforms, v2024.IdentityAttributeTransform{
+		IdentityAttributeName: &attributeName,
+		TransformDefinition: &v2024.TransformDefinition{
+			Type: &transformType,
+			Attributes: map[string]interface{}{
+				"id": s.transformName,
+			},
+		},
+	})
+
+	var enabled = true
+	var request = v2024.IdentityPreviewRequest{
+		IdentityId: &identityId,
+		IdentityAttributeConfig: &v2024.IdentityAttributeConfig{
+			Enabled:             &enabled,
+			AttributeTransforms: attributeTransforms,
+		},
+	}
+
+	identityPreview, resp, err := s.apiClient.V2024.IdentityProfilesAPI.GenerateIdentityPreview(context.TODO()).IdentityPreviewRequest(request).Execute()
+	if err != nil {
+		return nil, sdk.HandleSDKError(resp, err)
+	}
+
+	return identityPreview, nil
+}
+
 func cleanupPreviewObjects(apiClient *sailpoint.APIClient, transformId string) error {
 	log.Debug("Cleaning up preview objects")
 
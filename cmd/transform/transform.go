@@ -31,6 +31,8 @@ func NewTransformCommand() *cobra.Command {
 		newUpdateCommand(),
 		newDeleteCommand(),
 		newPreviewCommand(),
+		newApplyCommand(),
+		newLintCommand(),
 	)
 
 	return cmd
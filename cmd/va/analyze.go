@@ -0,0 +1,363 @@
+package va
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+)
+
+//go:embed analyze.md
+var analyzeHelp string
+
+// sampleStackLines caps the stack trace kept as a sample for each signature
+const sampleStackLines = 15
+
+var (
+	uuidPattern   = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	hexPattern    = regexp.MustCompile(`(?i)\b(0x)?[0-9a-f]{16,}\b`)
+	numberPattern = regexp.MustCompile(`\b\d+\b`)
+	quotedPattern = regexp.MustCompile(`'[^']*'|"[^"]*"`)
+)
+
+type analysisReport struct {
+	Files      []string           `json:"files"`
+	Lines      int                `json:"lines"`
+	Errors     int                `json:"errors"`
+	FirstSeen  *time.Time         `json:"firstSeen,omitempty"`
+	LastSeen   *time.Time         `json:"lastSeen,omitempty"`
+	Bucket     string             `json:"bucket"`
+	Signatures []signatureSummary `json:"signatures"`
+	Connectors []countEntry       `json:"connectors"`
+	Buckets    []countEntry       `json:"buckets"`
+}
+
+type signatureSummary struct {
+	Signature     string    `json:"signature"`
+	Exception     string    `json:"exception,omitempty"`
+	Class         string    `json:"class,omitempty"`
+	Method        string    `json:"method,omitempty"`
+	Count         int       `json:"count"`
+	FirstSeen     time.Time `json:"firstSeen"`
+	LastSeen      time.Time `json:"lastSeen"`
+	Connectors    []string  `json:"connectors"`
+	SampleMessage string    `json:"sampleMessage"`
+	SampleStack   string    `json:"sampleStack,omitempty"`
+}
+
+type countEntry struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// logAnalyzer accumulates error statistics over CCG lines
+type logAnalyzer struct {
+	bucket     time.Duration
+	report     analysisReport
+	signatures map[string]*signatureSummary
+	connectors map[string]int
+	buckets    map[string]int
+}
+
+func newLogAnalyzer(bucket time.Duration) *logAnalyzer {
+	return &logAnalyzer{
+		bucket:     bucket,
+		report:     analysisReport{Files: []string{}, Bucket: bucket.String()},
+		signatures: map[string]*signatureSummary{},
+		connectors: map[string]int{},
+		buckets:    map[string]int{},
+	}
+}
+
+// isErrorLine reports whether a CCG line records an error
+func isErrorLine(line CCG) bool {
+	level := strings.ToUpper(line.Level)
+	return level == "ERROR" || level == "FATAL" || line.Exception != ""
+}
+
+// exceptionType returns the exception class of an exception field that may
+// also carry the exception message
+func exceptionType(exception string) string {
+	name, _, _ := strings.Cut(strings.TrimSpace(exception), ":")
+	return strings.TrimSpace(name)
+}
+
+// normalizeMessage strips the variable parts of a message, such as ids,
+// numbers and quoted values, so similar messages group together
+func normalizeMessage(message string) string {
+	message, _, _ = strings.Cut(message, "\n")
+	message = uuidPattern.ReplaceAllString(message, "<uuid>")
+	message = hexPattern.ReplaceAllString(message, "<hex>")
+	message = quotedPattern.ReplaceAllString(message, "<value>")
+	message = numberPattern.ReplaceAllString(message, "<n>")
+	message = strings.Join(strings.Fields(message), " ")
+	if runes := []rune(message); len(runes) > 120 {
+		message = string(runes[:120]) + "…"
+	}
+	return message
+}
+
+// signature identifies an error by its exception type and the class and
+// method that logged it, falling back to the normalized message when the line
+// has no exception
+func signature(line CCG) string {
+	location := line.Class
+	if line.Method != "" {
+		location += "." + line.Method
+	}
+
+	cause := exceptionType(line.Exception)
+	if cause == "" {
+		cause = normalizeMessage(line.Message)
+	}
+
+	if location == "" {
+		return cause
+	}
+	return cause + " @ " + location
+}
+
+func connectorName(line CCG) string {
+	if line.ConnectorLogging != "" {
+		return line.ConnectorLogging
+	}
+	if line.Logger_name != "" {
+		return line.Logger_name
+	}
+	return "unknown"
+}
+
+func (a *logAnalyzer) add(line CCG) {
+	a.report.Errors++
+
+	if a.report.FirstSeen == nil || line.Timestamp.Before(*a.report.FirstSeen) {
+		t := line.Timestamp
+		a.report.FirstSeen = &t
+	}
+	if a.report.LastSeen == nil || line.Timestamp.After(*a.report.LastSeen) {
+		t := line.Timestamp
+		a.report.LastSeen = &t
+	}
+
+	connector := connectorName(line)
+	a.connectors[connector]++
+	a.buckets[line.Timestamp.UTC().Truncate(a.bucket).Format(time.RFC3339)]++
+
+	key := signature(line)
+	summary, ok := a.signatures[key]
+	if !ok {
+		summary = &signatureSummary{
+			Signature:     key,
+			Exception:     exceptionType(line.Exception),
+			Class:         line.Class,
+			Method:        line.Method,
+			FirstSeen:     line.Timestamp,
+			LastSeen:      line.Timestamp,
+			Connectors:    []string{},
+			SampleMessage: line.Message,
+		}
+		a.signatures[key] = summary
+	}
+
+	summary.Count++
+	if line.Timestamp.Before(summary.FirstSeen) {
+		summary.FirstSeen = line.Timestamp
+	}
+	if line.Timestamp.After(summary.LastSeen) {
+		summary.LastSeen = line.Timestamp
+	}
+	if summary.SampleStack == "" && line.Stack != "" {
+		summary.SampleStack = truncateLines(line.Stack, sampleStackLines)
+		summary.SampleMessage = line.Message
+	}
+	if !slices.Contains(summary.Connectors, connector) {
+		summary.Connectors = append(summary.Connectors, connector)
+	}
+}
+
+// analyzeFile feeds the errors of a CCG or charon log to the analyzer
+func (a *logAnalyzer) analyzeFile(filepath string, filter logFilter) error {
+	a.report.Files = append(a.report.Files, filepath)
+
+	return readLogLines(filepath, func(token []byte) error {
+		a.report.Lines++
+
+		var line CCG
+		if json.Unmarshal(token, &line) != nil {
+			return nil
+		}
+		if !isErrorLine(line) || !filter.matchCCG(line, token) {
+			return nil
+		}
+
+		a.add(line)
+		return nil
+	})
+}
+
+// result returns the report with the top signatures, the connectors by error
+// count and the time buckets in order
+func (a *logAnalyzer) result(top int) analysisReport {
+	report := a.report
+
+	report.Signatures = []signatureSummary{}
+	for _, summary := range a.signatures {
+		sort.Strings(summary.Connectors)
+		report.Signatures = append(report.Signatures, *summary)
+	}
+	sort.Slice(report.Signatures, func(i, j int) bool {
+		if report.Signatures[i].Count != report.Signatures[j].Count {
+			return report.Signatures[i].Count > report.Signatures[j].Count
+		}
+		return report.Signatures[i].Signature < report.Signatures[j].Signature
+	})
+	if top > 0 && len(report.Signatures) > top {
+		report.Signatures = report.Signatures[:top]
+	}
+
+	report.Connectors = sortedCounts(a.connectors, true)
+	report.Buckets = sortedCounts(a.buckets, false)
+
+	return report
+}
+
+// sortedCounts orders counts by descending count, or by key when byCount is
+// false
+func sortedCounts(counts map[string]int, byCount bool) []countEntry {
+	entries := []countEntry{}
+	for key, count := range counts {
+		entries = append(entries, countEntry{Key: key, Count: count})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if byCount && entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+func (r analysisReport) markdown() string {
+	var sb strings.Builder
+
+	sb.WriteString("# CCG Log Analysis\n\n")
+	fmt.Fprintf(&sb, "Analyzed %d lines from %s and found %d errors", r.Lines, strings.Join(r.Files, ", "), r.Errors)
+	if r.FirstSeen != nil {
+		fmt.Fprintf(&sb, " between %s and %s", r.FirstSeen.Format(time.RFC3339), r.LastSeen.Format(time.RFC3339))
+	}
+	sb.WriteString(".\n")
+
+	sb.WriteString("\n## Top Error Signatures\n\n")
+	if len(r.Signatures) == 0 {
+		sb.WriteString("No errors found.\n")
+	} else {
+		sb.WriteString("| # | Signature | Count | First Seen | Last Seen | Connectors |\n| --- | --- | --- | --- | --- | --- |\n")
+		for i, s := range r.Signatures {
+			fmt.Fprintf(&sb, "| %d | %s | %d | %s | %s | %s |\n", i+1, markdownCell(s.Signature), s.Count, s.FirstSeen.Format(time.RFC3339), s.LastSeen.Format(time.RFC3339), markdownCell(strings.Join(s.Connectors, ", ")))
+		}
+
+		for i, s := range r.Signatures {
+			fmt.Fprintf(&sb, "\n### %d. %s\n\n", i+1, s.Signature)
+			fmt.Fprintf(&sb, "Sample message: %s\n", s.SampleMessage)
+			if s.SampleStack != "" {
+				fmt.Fprintf(&sb, "\n```\n%s\n```\n", s.SampleStack)
+			}
+		}
+	}
+
+	sb.WriteString("\n## Errors by Connector\n\n")
+	sb.WriteString("| Connector | Errors |\n| --- | --- |\n")
+	for _, c := range r.Connectors {
+		fmt.Fprintf(&sb, "| %s | %d |\n", markdownCell(c.Key), c.Count)
+	}
+
+	fmt.Fprintf(&sb, "\n## Errors per %s\n\n", r.Bucket)
+	sb.WriteString("| Start | Errors |\n| --- | --- |\n")
+	for _, b := range r.Buckets {
+		fmt.Fprintf(&sb, "| %s | %d |\n", b.Key, b.Count)
+	}
+
+	return sb.String()
+}
+
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+func truncateLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) <= n {
+		return strings.Join(lines, "\n")
+	}
+	return strings.Join(lines[:n], "\n") + fmt.Sprintf("\n... %d more lines", len(lines)-n)
+}
+
+func newAnalyzeCommand() *cobra.Command {
+	help := util.ParseHelp(analyzeHelp)
+	var format string
+	var top int
+	var bucket time.Duration
+	var since string
+	var until string
+	cmd := &cobra.Command{
+		Use:     "analyze [file...]",
+		Short:   "Summarize the errors in CCG and charon logs",
+		Long:    help.Long,
+		Example: help.Example,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "markdown" && format != "json" {
+				return fmt.Errorf("unsupported format %q, use markdown or json", format)
+			}
+			if bucket <= 0 {
+				return fmt.Errorf("--bucket must be a positive duration")
+			}
+
+			now := time.Now()
+			var filter logFilter
+			var err error
+			if filter.since, err = parseTimeFlag(since, now); err != nil {
+				return err
+			}
+			if filter.until, err = parseTimeFlag(until, now); err != nil {
+				return err
+			}
+
+			analyzer := newLogAnalyzer(bucket)
+			for _, filepath := range args {
+				if err := analyzer.analyzeFile(filepath, filter); err != nil {
+					return fmt.Errorf("issue analyzing log file %s: %w", filepath, err)
+				}
+			}
+
+			report := analyzer.result(top)
+
+			if format == "json" {
+				raw, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), string(raw))
+			} else {
+				_, _ = fmt.Fprint(cmd.OutOrStdout(), report.markdown())
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "markdown", "Report format, markdown or json")
+	cmd.Flags().IntVar(&top, "top", 10, "Number of error signatures to report, 0 for all")
+	cmd.Flags().DurationVar(&bucket, "bucket", time.Hour, "Width of the time buckets errors are counted in")
+	cmd.Flags().StringVar(&since, "since", "", "Only analyze lines at or after this time (RFC 3339, YYYY-MM-DD[ HH:MM:SS], or a duration like 24h or 7d)")
+	cmd.Flags().StringVar(&until, "until", "", "Only analyze lines at or before this time, in the same formats as --since")
+
+	return cmd
+}
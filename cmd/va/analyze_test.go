@@ -0,0 +1,71 @@
+package va
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+const testAnalyzeLog = `{"@timestamp":"2024-05-01T10:05:00Z","level":"ERROR","connector-logging":"AD","class":"ADConnector","method":"bind","message":"bind failed for user 1234","exception":"javax.naming.CommunicationException: host 10.0.0.1","stack":"javax.naming.CommunicationException\n\tat ADConnector.bind"}
+{"@timestamp":"2024-05-01T10:45:00Z","level":"ERROR","connector-logging":"AD","class":"ADConnector","method":"bind","message":"bind failed for user 99","exception":"javax.naming.CommunicationException: host 10.0.0.2"}
+{"@timestamp":"2024-05-01T11:10:00Z","level":"ERROR","logger_name":"sailpoint.connector.JDBC","class":"JDBCConnector","method":"query","message":"query 'acme' timed out after 30 seconds"}
+{"@timestamp":"2024-05-01T11:20:00Z","level":"ERROR","logger_name":"sailpoint.connector.JDBC","class":"JDBCConnector","method":"query","message":"query 'other' timed out after 45 seconds"}
+{"@timestamp":"2024-05-01T11:30:00Z","level":"ERROR","connector-logging":"AD","class":"ADConnector","method":"bind","message":"bind failed","exception":"javax.naming.CommunicationException"}
+{"@timestamp":"2024-05-01T11:40:00Z","level":"INFO","connector-logging":"AD","class":"ADConnector","message":"error count reset"}`
+
+func TestAnalyzeFile(t *testing.T) {
+	filepath := writeTestLog(t, "ccg.log", testAnalyzeLog, false)
+
+	analyzer := newLogAnalyzer(time.Hour)
+	if err := analyzer.analyzeFile(filepath, logFilter{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report := analyzer.result(10)
+
+	if report.Lines != 6 || report.Errors != 5 {
+		t.Fatalf("expected 6 lines and 5 errors, got %d and %d", report.Lines, report.Errors)
+	}
+	if len(report.Signatures) != 2 {
+		t.Fatalf("expected 2 signatures, got %d: %+v", len(report.Signatures), report.Signatures)
+	}
+
+	top := report.Signatures[0]
+	if top.Signature != "javax.naming.CommunicationException @ ADConnector.bind" || top.Count != 3 {
+		t.Errorf("unexpected top signature: %+v", top)
+	}
+	if !top.LastSeen.Equal(time.Date(2024, 5, 1, 11, 30, 0, 0, time.UTC)) {
+		t.Errorf("unexpected last seen: %s", top.LastSeen)
+	}
+	if !strings.Contains(top.SampleStack, "at ADConnector.bind") {
+		t.Errorf("expected a sample stack, got %q", top.SampleStack)
+	}
+
+	second := report.Signatures[1]
+	if second.Signature != "query <value> timed out after <n> seconds @ JDBCConnector.query" || second.Count != 2 {
+		t.Errorf("unexpected second signature: %+v", second)
+	}
+
+	if report.Connectors[0].Key != "AD" || report.Connectors[0].Count != 3 || report.Connectors[1].Key != "sailpoint.connector.JDBC" {
+		t.Errorf("unexpected connector counts: %+v", report.Connectors)
+	}
+	if len(report.Buckets) != 2 || report.Buckets[0].Count != 2 || report.Buckets[1].Count != 3 {
+		t.Errorf("unexpected bucket counts: %+v", report.Buckets)
+	}
+
+	if md := report.markdown(); !strings.Contains(md, "| 1 | javax.naming.CommunicationException @ ADConnector.bind | 3 |") {
+		t.Errorf("unexpected markdown:\n%s", md)
+	}
+}
+
+func TestNormalizeMessageTruncatesRunes(t *testing.T) {
+	message := strings.Repeat("é", 130)
+
+	got := normalizeMessage(message)
+	if !utf8.ValidString(got) {
+		t.Fatalf("truncated message is not valid UTF-8: %q", got)
+	}
+	if want := strings.Repeat("é", 120) + "…"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,351 @@
+package va
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sailpoint-oss/sailpoint-cli/internal/va"
+	"gopkg.in/yaml.v2"
+)
+
+type checkStatus string
+
+const (
+	checkPass checkStatus = "PASS"
+	checkWarn checkStatus = "WARN"
+	checkFail checkStatus = "FAIL"
+)
+
+const (
+	proxyConfigPath = "/home/sailpoint/proxy.yaml"
+	vaConfigPath    = "/home/sailpoint/config.yaml"
+	ccgLogPath      = "/home/sailpoint/log/ccg.log"
+
+	// ccgLogWindow is the number of trailing ccg.log lines scanned for errors
+	ccgLogWindow = 1000
+	// maxClockSkew is the clock difference tolerated between the VA and this machine
+	maxClockSkew = 60 * time.Second
+)
+
+// vaServices are the systemd units that must be active on a healthy VA
+var vaServices = []string{"ccg", "charon", "va_agent", "fluent"}
+
+// vaContainers are the containers started by the ccg and charon services
+var vaContainers = []string{"ccg", "charon"}
+
+type checkResult struct {
+	Check  string      `json:"check"`
+	Status checkStatus `json:"status"`
+	Detail string      `json:"detail"`
+}
+
+// commandRunner runs a shell command on a VA and returns its combined output
+type commandRunner func(cmd string) (string, error)
+
+type proxyConfig struct {
+	Host string `yaml:"host"`
+	Port string `yaml:"port"`
+	User string `yaml:"user"`
+}
+
+// runTroubleshootChecks runs the VA diagnostics through run. Endpoints found
+// in config.yaml are checked along with extraEndpoints.
+func runTroubleshootChecks(run commandRunner, extraEndpoints []string, now time.Time) []checkResult {
+	var results []checkResult
+
+	proxyResult, proxy := checkProxyConfig(run)
+	results = append(results, proxyResult)
+
+	endpoints := configuredEndpoints(run, extraEndpoints)
+	if len(endpoints) == 0 {
+		results = append(results, checkResult{"Endpoints", checkWarn, "no endpoints found in " + vaConfigPath + ", add some with --check-endpoint"})
+	}
+
+	results = append(results, checkDNS(run, endpoints, proxy)...)
+	results = append(results, checkConnectivity(run, endpoints, proxy)...)
+	results = append(results, checkDisk(run)...)
+	results = append(results, checkTimeSync(run, now))
+	results = append(results, checkServices(run)...)
+	results = append(results, checkContainers(run)...)
+	results = append(results, checkCCGLog(run))
+
+	return results
+}
+
+// checkProxyConfig validates proxy.yaml and returns the proxy it configures,
+// if any
+func checkProxyConfig(run commandRunner) (checkResult, *proxyConfig) {
+	name := "Proxy config"
+
+	out, err := run("cat " + proxyConfigPath)
+	if err != nil || strings.TrimSpace(out) == "" {
+		return checkResult{name, checkPass, "no proxy configured"}, nil
+	}
+
+	var proxy proxyConfig
+	if err := yaml.Unmarshal([]byte(out), &proxy); err != nil {
+		return checkResult{name, checkFail, "proxy.yaml is not valid YAML: " + err.Error()}, nil
+	}
+
+	if proxy.Host == "" {
+		return checkResult{name, checkFail, "proxy.yaml does not set a host"}, nil
+	}
+	if strings.Contains(proxy.Host, "://") {
+		return checkResult{name, checkFail, fmt.Sprintf("proxy host %q must not include a scheme", proxy.Host)}, nil
+	}
+	port, err := strconv.Atoi(proxy.Port)
+	if err != nil || port < 1 || port > 65535 {
+		return checkResult{name, checkFail, fmt.Sprintf("proxy port %q is not a valid port", proxy.Port)}, nil
+	}
+
+	return checkResult{name, checkPass, fmt.Sprintf("proxy %s:%d", proxy.Host, port)}, &proxy
+}
+
+// configuredEndpoints returns the hosts of the URLs in config.yaml along with
+// extra, sorted and without duplicates. Only URLs are read from config.yaml,
+// the credentials it holds are ignored.
+func configuredEndpoints(run commandRunner, extra []string) []string {
+	hosts := map[string]bool{}
+	for _, host := range extra {
+		hosts[host] = true
+	}
+
+	out, err := run("cat " + vaConfigPath)
+	if err == nil {
+		var cfg map[string]interface{}
+		if yaml.Unmarshal([]byte(out), &cfg) == nil {
+			for _, value := range cfg {
+				s, ok := value.(string)
+				if !ok || !(strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")) {
+					continue
+				}
+				if u, err := url.Parse(s); err == nil && u.Hostname() != "" {
+					hosts[u.Hostname()] = true
+				}
+			}
+		}
+	}
+
+	endpoints := make([]string, 0, len(hosts))
+	for host := range hosts {
+		endpoints = append(endpoints, host)
+	}
+	sort.Strings(endpoints)
+
+	return endpoints
+}
+
+// checkDNS verifies a nameserver is configured and resolves the endpoints, or
+// only the proxy when one is configured since the proxy resolves the rest
+func checkDNS(run commandRunner, endpoints []string, proxy *proxyConfig) []checkResult {
+	out, _ := run("grep -E '^nameserver' /etc/resolv.conf")
+
+	var nameservers []string
+	for _, line := range strings.Split(out, "\n") {
+		if fields := strings.Fields(line); len(fields) > 1 && fields[0] == "nameserver" {
+			nameservers = append(nameservers, fields[1])
+		}
+	}
+	if len(nameservers) == 0 {
+		return []checkResult{{"DNS", checkFail, "no nameserver configured in /etc/resolv.conf"}}
+	}
+
+	results := []checkResult{{"DNS", checkPass, "nameservers " + strings.Join(nameservers, ", ")}}
+
+	hosts := endpoints
+	if proxy != nil {
+		hosts = []string{proxy.Host}
+	}
+	for _, host := range hosts {
+		name := "DNS " + host
+		out, err := run("getent hosts " + va.ShellQuote(host))
+		if err != nil || strings.TrimSpace(out) == "" {
+			results = append(results, checkResult{name, checkFail, "could not resolve host"})
+			continue
+		}
+		results = append(results, checkResult{name, checkPass, "resolves to " + strings.Fields(out)[0]})
+	}
+
+	return results
+}
+
+// checkConnectivity opens an HTTPS request to each endpoint, through the
+// proxy when one is configured
+func checkConnectivity(run commandRunner, endpoints []string, proxy *proxyConfig) []checkResult {
+	var results []checkResult
+
+	proxyArg := ""
+	if proxy != nil {
+		proxyArg = "-x " + va.ShellQuote("http://"+proxy.Host+":"+proxy.Port) + " "
+	}
+
+	for _, host := range endpoints {
+		name := "Connect " + host
+		out, err := run("curl -sS -o /dev/null --max-time 10 -w '%{http_code}' " + proxyArg + va.ShellQuote("https://"+host))
+		code := strings.TrimSpace(out)
+		if len(code) > 3 {
+			code = code[len(code)-3:]
+		}
+
+		switch {
+		case code == "407":
+			results = append(results, checkResult{name, checkWarn, "proxy requires authentication, connectivity was checked without credentials"})
+		case err != nil || code == "" || code == "000":
+			results = append(results, checkResult{name, checkFail, "could not connect: " + firstLine(out)})
+		default:
+			results = append(results, checkResult{name, checkPass, "HTTP " + code})
+		}
+	}
+
+	return results
+}
+
+// checkDisk reports the usage of the root and sailpoint home filesystems
+func checkDisk(run commandRunner) []checkResult {
+	out, err := run("df -P / /home/sailpoint")
+	if err != nil && strings.TrimSpace(out) == "" {
+		return []checkResult{{"Disk", checkFail, "could not read disk usage: " + err.Error()}}
+	}
+
+	var results []checkResult
+	seen := map[string]bool{}
+	for _, line := range strings.Split(out, "\n")[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 6 || seen[fields[5]] {
+			continue
+		}
+		seen[fields[5]] = true
+
+		used, err := strconv.Atoi(strings.TrimSuffix(fields[4], "%"))
+		if err != nil {
+			continue
+		}
+
+		status := checkPass
+		if used >= 90 {
+			status = checkFail
+		} else if used >= 80 {
+			status = checkWarn
+		}
+		results = append(results, checkResult{"Disk " + fields[5], status, fmt.Sprintf("%d%% used", used)})
+	}
+
+	if len(results) == 0 {
+		return []checkResult{{"Disk", checkFail, "could not parse disk usage"}}
+	}
+
+	return results
+}
+
+// checkTimeSync verifies the VA clock is synchronized over NTP and close to
+// the clock of this machine
+func checkTimeSync(run commandRunner, now time.Time) checkResult {
+	name := "Time sync"
+
+	out, _ := run("timedatectl show -p NTPSynchronized --value")
+	if strings.TrimSpace(out) != "yes" {
+		return checkResult{name, checkFail, "clock is not synchronized over NTP"}
+	}
+
+	out, err := run("date +%s")
+	if err != nil {
+		return checkResult{name, checkWarn, "NTP synchronized, could not read the VA clock"}
+	}
+	seconds, err := strconv.ParseInt(strings.TrimSpace(out), 10, 64)
+	if err != nil {
+		return checkResult{name, checkWarn, "NTP synchronized, could not read the VA clock"}
+	}
+
+	skew := time.Unix(seconds, 0).Sub(now)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > maxClockSkew {
+		return checkResult{name, checkWarn, fmt.Sprintf("NTP synchronized, clock differs from this machine by %s", skew.Round(time.Second))}
+	}
+
+	return checkResult{name, checkPass, "NTP synchronized"}
+}
+
+func checkServices(run commandRunner) []checkResult {
+	// is-active exits non-zero when a unit is inactive, the states are still printed
+	out, _ := run("systemctl is-active " + strings.Join(vaServices, " "))
+	states := strings.Fields(out)
+
+	var results []checkResult
+	for i, service := range vaServices {
+		name := "Service " + service
+		switch {
+		case i >= len(states):
+			results = append(results, checkResult{name, checkFail, "could not read service state"})
+		case states[i] == "active":
+			results = append(results, checkResult{name, checkPass, "active"})
+		default:
+			results = append(results, checkResult{name, checkFail, states[i]})
+		}
+	}
+
+	return results
+}
+
+// checkContainers verifies the ccg and charon containers are up and not
+// restarting
+func checkContainers(run commandRunner) []checkResult {
+	out, err := run("sudo docker ps -a --format '{{.Names}}|{{.Status}}'")
+	if err != nil {
+		return []checkResult{{"Containers", checkWarn, "could not list containers: " + firstLine(out)}}
+	}
+
+	statuses := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		if name, status, ok := strings.Cut(strings.TrimSpace(line), "|"); ok {
+			statuses[name] = status
+		}
+	}
+
+	var results []checkResult
+	for _, container := range vaContainers {
+		name := "Container " + container
+		status, ok := statuses[container]
+		switch {
+		case !ok:
+			results = append(results, checkResult{name, checkFail, "container not found"})
+		case strings.HasPrefix(status, "Up") && !strings.Contains(status, "Restarting"):
+			results = append(results, checkResult{name, checkPass, status})
+		default:
+			results = append(results, checkResult{name, checkFail, status})
+		}
+	}
+
+	return results
+}
+
+// checkCCGLog counts the errors among the latest ccg.log lines
+func checkCCGLog(run commandRunner) checkResult {
+	name := "CCG log"
+
+	if _, err := run("test -f " + ccgLogPath); err != nil {
+		return checkResult{name, checkWarn, ccgLogPath + " not found"}
+	}
+
+	// grep -c exits non-zero when nothing matches, the count is still printed
+	out, _ := run(fmt.Sprintf(`tail -n %d %s | grep -cE '"level" ?: ?"ERROR"'`, ccgLogWindow, ccgLogPath))
+	count, err := strconv.Atoi(strings.TrimSpace(out))
+	if err != nil {
+		return checkResult{name, checkWarn, "could not read " + ccgLogPath}
+	}
+
+	if count > 0 {
+		return checkResult{name, checkWarn, fmt.Sprintf("%d errors in the last %d lines, inspect them with sail va parse", count, ccgLogWindow)}
+	}
+	return checkResult{name, checkPass, fmt.Sprintf("no errors in the last %d lines", ccgLogWindow)}
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
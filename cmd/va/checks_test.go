@@ -0,0 +1,92 @@
+package va
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeVA answers commands from a map, failing unknown ones
+func fakeVA(responses map[string]string) commandRunner {
+	return func(cmd string) (string, error) {
+		out, ok := responses[cmd]
+		if !ok {
+			return "", errors.New("exit status 1")
+		}
+		return out, nil
+	}
+}
+
+func TestRunTroubleshootChecks(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	run := fakeVA(map[string]string{
+		"cat /home/sailpoint/proxy.yaml":         "host: proxy.example.com\nport: 8080\n",
+		"cat /home/sailpoint/config.yaml":        "apiKey: secret\nurl: https://acme.api.identitynow.com/beta\n",
+		"grep -E '^nameserver' /etc/resolv.conf": "nameserver 10.0.0.2\n",
+		"getent hosts 'proxy.example.com'":       "10.0.0.9 proxy.example.com\n",
+		"curl -sS -o /dev/null --max-time 10 -w '%{http_code}' -x 'http://proxy.example.com:8080' 'https://acme.api.identitynow.com'": "200",
+		"df -P / /home/sailpoint":                     "Filesystem 1024-blocks Used Available Capacity Mounted on\n/dev/sda9 100 85 15 85% /\n/dev/sda9 100 85 15 85% /\n",
+		"timedatectl show -p NTPSynchronized --value": "yes\n",
+		"date +%s": "1700000005\n",
+		"systemctl is-active ccg charon va_agent fluent":                            "active\nactive\nactive\nfailed\n",
+		"sudo docker ps -a --format '{{.Names}}|{{.Status}}'":                       "ccg|Up 2 hours\ncharon|Restarting (1) 5 seconds ago\n",
+		"test -f /home/sailpoint/log/ccg.log":                                       "",
+		`tail -n 1000 /home/sailpoint/log/ccg.log | grep -cE '"level" ?: ?"ERROR"'`: "0\n",
+	})
+
+	results := runTroubleshootChecks(run, nil, now)
+
+	want := map[string]checkStatus{
+		"Proxy config":                     checkPass,
+		"DNS":                              checkPass,
+		"DNS proxy.example.com":            checkPass,
+		"Connect acme.api.identitynow.com": checkPass,
+		"Disk /":                           checkWarn,
+		"Time sync":                        checkPass,
+		"Service ccg":                      checkPass,
+		"Service fluent":                   checkFail,
+		"Container ccg":                    checkPass,
+		"Container charon":                 checkFail,
+		"CCG log":                          checkPass,
+	}
+
+	got := map[string]checkStatus{}
+	for _, result := range results {
+		got[result.Check] = result.Status
+	}
+	for check, status := range want {
+		if got[check] != status {
+			t.Errorf("check %q: expected %s, got %q", check, status, got[check])
+		}
+	}
+	if len(results) != 13 {
+		t.Errorf("expected 13 checks, got %d: %+v", len(results), results)
+	}
+}
+
+func TestCheckProxyConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		status checkStatus
+	}{
+		{"missing", "", checkPass},
+		{"invalid yaml", "host: [proxy", checkFail},
+		{"no host", "port: 8080", checkFail},
+		{"scheme", "host: http://proxy\nport: 8080", checkFail},
+		{"bad port", "host: proxy\nport: 99999", checkFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responses := map[string]string{}
+			if tt.config != "" {
+				responses["cat /home/sailpoint/proxy.yaml"] = tt.config
+			}
+			result, _ := checkProxyConfig(fakeVA(responses))
+			if result.Status != tt.status {
+				t.Errorf("expected %s, got %s: %s", tt.status, result.Status, result.Detail)
+			}
+		})
+	}
+}
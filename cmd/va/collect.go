@@ -2,11 +2,15 @@ package va
 
 import (
 	_ "embed"
+	"fmt"
 	"os"
-	"sync"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/config"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/output"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/terminal"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/va"
@@ -17,85 +21,175 @@ import (
 //go:embed collect.md
 var collectHelp string
 
+// defaultMaxFileSizeMB caps each collected file when neither the profile nor
+// the command sets a limit
+const defaultMaxFileSizeMB = 100
+
+// collectProfiles returns the built-in profiles merged with the profiles in
+// config, which take precedence, and whether each came from config
+func collectProfiles() (map[string]va.CollectProfile, map[string]bool, error) {
+	profiles := map[string]va.CollectProfile{}
+	for name, profile := range va.BuiltinProfiles {
+		profiles[name] = profile
+	}
+
+	configured, err := config.GetVACollectProfiles()
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read collection profiles from config: %w", err)
+	}
+
+	custom := map[string]bool{}
+	for name, profile := range configured {
+		profiles[name] = va.CollectProfile{
+			Name:        name,
+			Description: profile.Description,
+			Files:       profile.Files,
+			MaxFileSize: int64(profile.MaxFileSizeMB) * 1024 * 1024,
+		}
+		custom[name] = true
+	}
+
+	return profiles, custom, nil
+}
+
+// resolveCollectProfile returns the named profile with extra files added and
+// the size cap applied
+func resolveCollectProfile(name string, extraFiles []string, maxFileSizeMB int) (va.CollectProfile, error) {
+	profiles, _, err := collectProfiles()
+	if err != nil {
+		return va.CollectProfile{}, err
+	}
+
+	profile, ok := profiles[strings.ToLower(name)]
+	if !ok {
+		return va.CollectProfile{}, fmt.Errorf("unknown collection profile %q, list the available profiles with --list-profiles", name)
+	}
+
+	profile.Files = append(append([]string{}, profile.Files...), extraFiles...)
+	if len(profile.Files) == 0 {
+		return va.CollectProfile{}, fmt.Errorf("collection profile %q does not list any files", name)
+	}
+
+	if maxFileSizeMB > 0 {
+		profile.MaxFileSize = int64(maxFileSizeMB) * 1024 * 1024
+	} else if profile.MaxFileSize == 0 {
+		profile.MaxFileSize = defaultMaxFileSizeMB * 1024 * 1024
+	}
+
+	return profile, nil
+}
+
+func listCollectProfiles(cmd *cobra.Command) error {
+	profiles, custom, err := collectProfiles()
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var entries [][]string
+	for _, name := range names {
+		source := "built-in"
+		if custom[name] {
+			source = "config"
+		}
+		profile := profiles[name]
+		entries = append(entries, []string{name, source, profile.Description, strings.Join(profile.Files, "\n")})
+	}
+
+	output.WriteTable(cmd.OutOrStdout(), []string{"Name", "Source", "Description", "Files"}, entries, "")
+	return nil
+}
+
 func newCollectCommand(term terminal.Terminal) *cobra.Command {
 	help := util.ParseHelp(collectHelp)
 	var credentials []string
 	var output string
 	var logs bool
 	var config bool
+	var profileName string
+	var extraFiles []string
+	var maxFileSizeMB int
+	var listProfiles bool
+	var ssh sshFlags
+	var targets targetFlags
 	cmd := &cobra.Command{
-		Use:     "collect [-c | -l] [-o output] VA-Network-Address... [-p va-password]",
+		Use:     "collect [-c | -l | --profile name] [-o output] [VA-Network-Address... | --cluster name | --tag tag] [-p va-password]",
 		Short:   "Collect files from a SailPoint virtual appliance",
 		Long:    help.Long,
 		Example: help.Example,
-		Args:    cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			var err error
-
-			logFiles := []string{"/home/sailpoint/log/ccg.log", "/home/sailpoint/log/charon.log"}
-			configFiles := []string{"/home/sailpoint/proxy.yaml", "/etc/systemd/network/static.network", "/etc/resolv.conf"}
-
-			if output == "" {
-				output, _ = os.Getwd()
+			if listProfiles {
+				return listCollectProfiles(cmd)
 			}
-			var files []string
+
 			if logs {
-				files = append(files, logFiles...)
+				profileName = "logs"
 			}
 			if config {
-				files = append(files, configFiles...)
+				profileName = "config"
+			}
+
+			profile, err := resolveCollectProfile(profileName, extraFiles, maxFileSizeMB)
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				output, _ = os.Getwd()
 			}
 
-			if !config && !logs {
-				files = append(files, logFiles...)
-				files = append(files, configFiles...)
+			hosts, err := targets.hosts(term, args, credentials, ssh)
+			if err != nil {
+				return err
 			}
 
-			var wg sync.WaitGroup
 			p := mpb.New(
 				mpb.PopCompletedMode(),
-				mpb.WithRefreshRate(180*time.Millisecond),
-				mpb.WithWaitGroup(&wg))
+				mpb.WithRefreshRate(180*time.Millisecond))
 
 			log.SetOutput(p)
 
-			for i, endpoint := range args {
-				var password string
-
-				if len(credentials) > i {
-					password = credentials[i]
-				}
-
-				if password == "" {
-					password, err = term.PromptPassword("Please enter the password for " + endpoint)
-					if err != nil {
-						return err
-					}
+			results := va.RunFleet(hosts, targets.concurrency, func(host va.Host) (string, error) {
+				bundle, err := va.CollectVABundle(host.Address, host.Options, output, profile, p)
+				if err != nil {
+					log.Error("Error collecting files for", "VA", host.Address, "err", err)
+					return "", err
 				}
-				wg.Add(1)
-				go func(endpoint, password string) {
-					defer wg.Done()
-					outputFolder := output
-
-					err := va.CollectVAFiles(endpoint, password, outputFolder, files, p)
-					if err != nil {
-						log.Error("Error collecting files for", "VA", endpoint, "err", err)
-					}
-				}(endpoint, password)
-			}
+				log.Info("Bundle saved", "VA", host.Address, "file", bundle)
+				return bundle, nil
+			})
 			p.Wait()
+			log.SetOutput(os.Stderr)
+
+			writeSummary(cmd.OutOrStdout(), results)
 
 			log.Info("All Operations Complete")
+
+			if failed := failedCount(results); failed > 0 {
+				return fmt.Errorf("file collection failed on %d of %d VAs", failed, len(results))
+			}
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVarP(&output, "output", "o", "", "Path to save the log files")
-	cmd.Flags().BoolVarP(&logs, "log", "l", false, "Retrieve log files")
-	cmd.Flags().BoolVarP(&config, "config", "c", false, "Retrieve config files")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Path to save the bundles")
+	cmd.Flags().BoolVarP(&logs, "log", "l", false, "Retrieve log files, the same as --profile logs")
+	cmd.Flags().BoolVarP(&config, "config", "c", false, "Retrieve config files, the same as --profile config")
+	cmd.Flags().StringVar(&profileName, "profile", "default", "Collection profile naming the files to retrieve")
+	cmd.Flags().StringArrayVar(&extraFiles, "file", []string{}, "Additional remote file or glob to retrieve, can be repeated")
+	cmd.Flags().IntVar(&maxFileSizeMB, "max-file-size", 0, "Maximum MB collected per file, larger files keep their most recent content (default 100, or the profile limit)")
+	cmd.Flags().BoolVar(&listProfiles, "list-profiles", false, "List the available collection profiles")
 	cmd.Flags().StringArrayVarP(&credentials, "passwords", "p", []string{}, "Passwords for the servers in the same order that the servers are listed as arguments")
 
-	cmd.MarkFlagsMutuallyExclusive("config", "log")
+	addSSHFlags(cmd, &ssh)
+	addTargetFlags(cmd, &targets, 4)
+
+	cmd.MarkFlagsMutuallyExclusive("config", "log", "profile")
 
 	return cmd
 }
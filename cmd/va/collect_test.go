@@ -0,0 +1,49 @@
+package va
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestResolveCollectProfile(t *testing.T) {
+	viper.Set("vacollectprofiles", map[string]interface{}{
+		"ldap": map[string]interface{}{
+			"description":   "LDAP troubleshooting",
+			"files":         []string{"/home/sailpoint/log/ccg.log*"},
+			"maxfilesizemb": 10,
+		},
+	})
+	defer viper.Set("vacollectprofiles", nil)
+
+	profile, err := resolveCollectProfile("ldap", []string{"/etc/hosts"}, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(profile.Files) != 2 || profile.Files[1] != "/etc/hosts" {
+		t.Errorf("unexpected files: %v", profile.Files)
+	}
+	if profile.MaxFileSize != 10*1024*1024 {
+		t.Errorf("expected the profile size cap, got %d", profile.MaxFileSize)
+	}
+
+	profile, err = resolveCollectProfile("logs", nil, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if profile.MaxFileSize != 5*1024*1024 {
+		t.Errorf("expected the flag size cap, got %d", profile.MaxFileSize)
+	}
+
+	profile, err = resolveCollectProfile("default", nil, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if profile.MaxFileSize != defaultMaxFileSizeMB*1024*1024 {
+		t.Errorf("expected the default size cap, got %d", profile.MaxFileSize)
+	}
+
+	if _, err := resolveCollectProfile("missing", nil, 0); err == nil {
+		t.Error("expected an error for an unknown profile")
+	}
+}
@@ -1,5 +1,7 @@
 package va
 
-const TroubleshootingScript = "/bin/bash -c \"$(curl -fsSL https://raw.githubusercontent.com/luke-hagar-sp/VA-Scripts/main/stunt.sh)\""
 const UpdateCommand = "sudo update_engine_client -check_for_update"
+const UpdateStatusCommand = "update_engine_client -status"
 const RebootCommand = "sudo reboot"
+const BootIDCommand = "cat /proc/sys/kernel/random/boot_id"
+const OSVersionCommand = "grep -E '^VERSION=' /etc/os-release"
@@ -0,0 +1,91 @@
+package va
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/terminal"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/va"
+	"github.com/spf13/cobra"
+)
+
+//go:embed exec.md
+var execHelp string
+
+func newExecCommand(term terminal.Terminal) *cobra.Command {
+	help := util.ParseHelp(execHelp)
+	var credentials []string
+	var ssh sshFlags
+	var targets targetFlags
+	cmd := &cobra.Command{
+		Use:     "exec [VA-Network-Address... | --cluster name | --tag tag] -- command",
+		Short:   "Run a command on SailPoint virtual appliances",
+		Long:    help.Long,
+		Example: help.Example,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dash := cmd.ArgsLenAtDash()
+			if dash < 0 || dash == len(args) {
+				return fmt.Errorf("provide the command to run after --")
+			}
+			command := shellCommand(args[dash:])
+
+			hosts, err := targets.hosts(term, args[:dash], credentials, ssh)
+			if err != nil {
+				return err
+			}
+
+			results := va.RunFleet(hosts, targets.concurrency, func(host va.Host) (string, error) {
+				return va.RunVACmd(host.Address, host.Options, command)
+			})
+
+			for _, result := range results {
+				printHostOutput(cmd.OutOrStdout(), result)
+			}
+			writeSummary(cmd.OutOrStdout(), results)
+
+			if failed := failedCount(results); failed > 0 {
+				return fmt.Errorf("command failed on %d of %d VAs", failed, len(results))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&credentials, "passwords", "p", []string{}, "Passwords for the servers in the same order that the servers are listed as arguments")
+	addSSHFlags(cmd, &ssh)
+	addTargetFlags(cmd, &targets, 4)
+
+	return cmd
+}
+
+// shellCommand builds the remote command line. A single argument is a shell
+// command string and runs as written, several arguments are quoted so each
+// reaches the command as one word.
+func shellCommand(args []string) string {
+	if len(args) == 1 {
+		return args[0]
+	}
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = va.ShellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// printHostOutput writes the output of a VA under a header naming it
+func printHostOutput(w io.Writer, result va.HostResult) {
+	header := result.Host.Address
+	if result.Host.Cluster != "" {
+		header += " (" + result.Host.Cluster + ")"
+	}
+	_, _ = fmt.Fprintln(w, color.New(color.Bold).Sprintf("==> %s <==", header))
+
+	out := strings.TrimRight(result.Output, "\n")
+	if out != "" {
+		_, _ = fmt.Fprintln(w, out)
+	}
+	_, _ = fmt.Fprintln(w)
+}
@@ -0,0 +1,20 @@
+package va
+
+import "testing"
+
+func TestShellCommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"df -h /home | tail -1"}, "df -h /home | tail -1"},
+		{[]string{"grep", "a b", "file"}, "'grep' 'a b' 'file'"},
+		{[]string{"echo", "it's"}, `'echo' 'it'\''s'`},
+	}
+
+	for _, tt := range tests {
+		if got := shellCommand(tt.args); got != tt.want {
+			t.Errorf("shellCommand(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
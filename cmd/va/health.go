@@ -0,0 +1,87 @@
+package va
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sailpoint "github.com/sailpoint-oss/golang-sdk/v2"
+	beta "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/sdk"
+)
+
+// clientHealth reports whether a VA is in a normal state and has checked in
+// since the given time, and why not otherwise. A status read right after a
+// reboot can still be the one reported before it, so it only counts once the
+// VA has been seen again.
+func clientHealth(id string, status beta.ManagedClientStatusEnum, lastSeen time.Time, since time.Time) (bool, string) {
+	switch status {
+	case beta.MANAGEDCLIENTSTATUSENUM_NORMAL, beta.MANAGEDCLIENTSTATUSENUM_NOT_CONFIGURED:
+	default:
+		return false, fmt.Sprintf("VA %s is %s", id, status)
+	}
+
+	if !lastSeen.After(since) {
+		return false, fmt.Sprintf("VA %s has not checked in since %s", id, since.Format(time.RFC3339))
+	}
+
+	return true, ""
+}
+
+// clusterHealth reports whether a managed cluster is operational with every
+// configured VA in a normal state and seen since the given time, and why not
+// otherwise
+func clusterHealth(ctx context.Context, apiClient *sailpoint.APIClient, clusterID string, since time.Time) (bool, string, error) {
+	cluster, resp, err := apiClient.Beta.ManagedClustersAPI.GetManagedCluster(ctx, clusterID).Execute()
+	if err != nil {
+		return false, "", sdk.HandleSDKError(resp, err)
+	}
+
+	if cluster.Operational == nil || !*cluster.Operational {
+		return false, fmt.Sprintf("cluster %s is not operational", cluster.GetName()), nil
+	}
+
+	for _, id := range cluster.ClientIds {
+		status, resp, err := apiClient.Beta.ManagedClientsAPI.GetManagedClientStatus(ctx, id).Type_("VA").Execute()
+		if err != nil {
+			return false, "", sdk.HandleSDKError(resp, err)
+		}
+		client, resp, err := apiClient.V3.ManagedClientsAPI.GetManagedClient(ctx, id).Execute()
+		if err != nil {
+			return false, "", sdk.HandleSDKError(resp, err)
+		}
+
+		if healthy, reason := clientHealth(id, status.Status, client.GetLastSeen().Time, since); !healthy {
+			return false, reason, nil
+		}
+	}
+
+	return true, "", nil
+}
+
+// waitForClusterHealth polls the cluster until it is healthy with every VA
+// seen since the given time, or timeout expires. API errors are retried until
+// then, the VA may still be starting.
+func waitForClusterHealth(ctx context.Context, apiClient *sailpoint.APIClient, clusterID string, since time.Time, interval time.Duration, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		healthy, reason, err := clusterHealth(ctx, apiClient, clusterID, since)
+		if healthy {
+			return nil
+		}
+		if err != nil {
+			reason = err.Error()
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("cluster %s not healthy after %s: %s", clusterID, timeout, reason)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
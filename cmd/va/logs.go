@@ -0,0 +1,334 @@
+package va
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/fatih/color"
+	sailpoint "github.com/sailpoint-oss/golang-sdk/v2"
+	beta "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/config"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/sdk"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/terminal"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/va"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+)
+
+//go:embed logs.md
+var logsHelp string
+
+// vaLogPaths are the VA logs that can be streamed, by name
+var vaLogPaths = map[string]string{
+	"ccg":    ccgLogPath,
+	"charon": "/home/sailpoint/log/charon.log",
+}
+
+// vaLogLine is a CCG line along with the VA it was read from
+type vaLogLine struct {
+	VA string `json:"va"`
+	CCG
+}
+
+// logStream writes the lines streamed from several VAs to one output. CCG
+// JSON lines go through the filter, other lines such as charon lines and
+// stack traces are only kept in text output when no CCG field is filtered on.
+type logStream struct {
+	lock   sync.Mutex
+	out    io.Writer
+	format string
+	writer *logWriter
+	filter logFilter
+}
+
+func newLogStream(out io.Writer, format string, filter logFilter) *logStream {
+	s := &logStream{out: out, format: format, filter: filter}
+	if format != "text" {
+		s.writer = newLogWriter(out, format, append([]string{"va"}, ccgCSVHeader...))
+	}
+	return s
+}
+
+func (s *logStream) handle(host string, token []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var line CCG
+	if json.Unmarshal(token, &line) != nil {
+		if s.format != "text" || len(s.filter.levels) > 0 || len(s.filter.loggers) > 0 || len(s.filter.classes) > 0 {
+			return nil
+		}
+		if s.filter.errorsOnly && !ErrorCheck(token) {
+			return nil
+		}
+		_, err := fmt.Fprintf(s.out, "%s %s\n", color.CyanString(host), token)
+		return err
+	}
+
+	if !s.filter.matchCCG(line, token) {
+		return nil
+	}
+
+	if s.format == "text" {
+		level := line.Level
+		switch strings.ToUpper(level) {
+		case "ERROR", "FATAL":
+			level = color.RedString(level)
+		case "WARN":
+			level = color.YellowString(level)
+		}
+
+		_, err := fmt.Fprintf(s.out, "%s %s %s %s %s\n", color.CyanString(host), line.Timestamp.Local().Format(time.RFC3339), level, line.Logger_name, line.Message)
+		if err == nil && line.Exception != "" {
+			_, err = fmt.Fprintln(s.out, line.Exception)
+		}
+		return err
+	}
+
+	if err := s.writer.write(vaLogLine{VA: host, CCG: line}, append([]string{host}, line.csvRecord()...)); err != nil {
+		return err
+	}
+	return s.writer.flush()
+}
+
+// tailCommand builds the remote command printing the last lines of logs,
+// and following them when follow is set
+func tailCommand(logs []string, lines int, follow bool) (string, error) {
+	command := fmt.Sprintf("tail -q -n %d", lines)
+	if follow {
+		command += " -F"
+	}
+
+	for _, name := range logs {
+		path, ok := vaLogPaths[name]
+		if !ok {
+			return "", fmt.Errorf("unsupported log %q, use ccg or charon", name)
+		}
+		command += " " + va.ShellQuote(path)
+	}
+
+	return command, nil
+}
+
+// restoreConfiguration returns the log configuration restoring previous at
+// now. A configuration that expired, or is about to, is replaced with a
+// short lived one at the default levels.
+func restoreConfiguration(previous *beta.ClientLogConfiguration, now time.Time) beta.ClientLogConfiguration {
+	if previous != nil && previous.RootLevel != "" {
+		if expiration, ok := previous.GetExpirationOk(); ok {
+			minutes := math.Floor(expiration.Sub(now).Minutes())
+			if minutes >= va.MinLogDurationMinutes {
+				restored := *previous
+				restored.DurationMinutes = int32(math.Min(minutes, va.MaxLogDurationMinutes))
+				return restored
+			}
+		}
+	}
+
+	logLevels := make(map[string]beta.StandardLevel)
+	return beta.ClientLogConfiguration{DurationMinutes: va.MinLogDurationMinutes, RootLevel: va.DefaultRootLogLevel, LogLevels: &logLevels}
+}
+
+// raiseLogLevels applies configuration to the clusters for the session and
+// returns a function putting their previous configuration back
+func raiseLogLevels(ctx context.Context, apiClient *sailpoint.APIClient, clusterIDs []string, configuration beta.ClientLogConfiguration) (func(), error) {
+	previous := map[string]*beta.ClientLogConfiguration{}
+
+	restore := func() {
+		for clusterID, current := range previous {
+			restored := restoreConfiguration(current, time.Now())
+			_, resp, err := apiClient.Beta.ManagedClustersAPI.PutClientLogConfiguration(context.Background(), clusterID).ClientLogConfiguration(restored).Execute()
+			if err != nil {
+				log.Warn("Failed to restore the cluster log configuration", "cluster", clusterID, "err", sdk.HandleSDKError(resp, err))
+				continue
+			}
+			log.Info("Restored the cluster log configuration", "cluster", clusterID, "rootLevel", restored.RootLevel, "durationMinutes", restored.DurationMinutes)
+		}
+	}
+
+	for _, clusterID := range clusterIDs {
+		current, resp, err := apiClient.Beta.ManagedClustersAPI.GetClientLogConfiguration(ctx, clusterID).Execute()
+		if err != nil {
+			restore()
+			return nil, sdk.HandleSDKError(resp, err)
+		}
+
+		_, resp, err = apiClient.Beta.ManagedClustersAPI.PutClientLogConfiguration(ctx, clusterID).ClientLogConfiguration(configuration).Execute()
+		if err != nil {
+			restore()
+			return nil, sdk.HandleSDKError(resp, err)
+		}
+		previous[clusterID] = current
+
+		log.Info("Raised the cluster log levels for the session", "cluster", clusterID, "rootLevel", configuration.RootLevel, "durationMinutes", configuration.DurationMinutes)
+	}
+
+	return restore, nil
+}
+
+// sessionLogConfiguration builds the log configuration raised for the session
+// from a saved profile and class=LEVEL entries
+func sessionLogConfiguration(profile string, entries []string, durationInMinutes int32, durationSet bool) (beta.ClientLogConfiguration, error) {
+	configuration := beta.ClientLogConfiguration{RootLevel: va.DefaultRootLogLevel}
+	logLevels := make(map[string]beta.StandardLevel)
+
+	if profile != "" {
+		var err error
+		configuration, err = va.ProfileLogConfiguration(profile)
+		if err != nil {
+			return configuration, err
+		}
+		logLevels = configuration.GetLogLevels()
+	}
+
+	raised, err := va.ParseLogLevels(entries)
+	if err != nil {
+		return configuration, err
+	}
+	for class, level := range raised {
+		logLevels[class] = level
+	}
+	configuration.LogLevels = &logLevels
+
+	if configuration.DurationMinutes == 0 || durationSet {
+		configuration.DurationMinutes = durationInMinutes
+	}
+	if configuration.DurationMinutes < va.MinLogDurationMinutes || configuration.DurationMinutes > va.MaxLogDurationMinutes {
+		return configuration, fmt.Errorf("invalid --raise-duration: %d, must be between %d and %d", configuration.DurationMinutes, va.MinLogDurationMinutes, va.MaxLogDurationMinutes)
+	}
+
+	return configuration, nil
+}
+
+func newLogsCommand(term terminal.Terminal) *cobra.Command {
+	help := util.ParseHelp(logsHelp)
+	var credentials []string
+	var ssh sshFlags
+	var targets targetFlags
+	var follow bool
+	var lines int
+	var logs []string
+	var levels []string
+	var loggers []string
+	var classes []string
+	var errorsOnly bool
+	var format string
+	var raise []string
+	var raiseProfile string
+	var raiseDuration int32
+	var clusterIDs []string
+	cmd := &cobra.Command{
+		Use:     "logs [VA-Network-Address... | --cluster name | --tag tag]",
+		Short:   "Stream and parse the logs of SailPoint virtual appliances",
+		Long:    help.Long,
+		Example: help.Example,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "text" && format != "ndjson" && format != "csv" {
+				return fmt.Errorf("unsupported format %q, use text, ndjson or csv", format)
+			}
+
+			command, err := tailCommand(logs, lines, follow)
+			if err != nil {
+				return err
+			}
+
+			hosts, err := targets.hosts(term, args, credentials, ssh)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if len(raise) > 0 || raiseProfile != "" {
+				if len(clusterIDs) == 0 {
+					for _, host := range hosts {
+						if host.ClusterID != "" && !slices.Contains(clusterIDs, host.ClusterID) {
+							clusterIDs = append(clusterIDs, host.ClusterID)
+						}
+					}
+				}
+				if len(clusterIDs) == 0 {
+					return fmt.Errorf("raising log levels needs --cluster-id, or inventory clusters with an id")
+				}
+
+				configuration, err := sessionLogConfiguration(raiseProfile, raise, raiseDuration, cmd.Flags().Changed("raise-duration"))
+				if err != nil {
+					return err
+				}
+
+				apiClient, err := config.InitAPIClient(false)
+				if err != nil {
+					return err
+				}
+
+				restore, err := raiseLogLevels(ctx, apiClient, clusterIDs, configuration)
+				if err != nil {
+					return err
+				}
+				defer restore()
+			}
+
+			filter := logFilter{levels: levels, loggers: loggers, classes: classes, errorsOnly: errorsOnly}
+			stream := newLogStream(cmd.OutOrStdout(), format, filter)
+
+			// Followed logs never end, every VA is streamed at the same time
+			concurrency := targets.concurrency
+			if follow {
+				concurrency = len(hosts)
+			}
+
+			results := va.RunFleet(hosts, concurrency, func(host va.Host) (string, error) {
+				client, err := va.Dial(host.Address, host.Options)
+				if err != nil {
+					return "", err
+				}
+				defer client.Close()
+
+				return "", va.StreamClientCmd(ctx, client, command, func(line []byte) error {
+					return stream.handle(host.Address, line)
+				})
+			})
+
+			for _, result := range results {
+				if result.Err != nil {
+					log.Error("Failed to read VA logs", "VA", result.Host.Address, "err", result.Err)
+				}
+			}
+			if failed := failedCount(results); failed > 0 {
+				return fmt.Errorf("failed to read the logs of %d of %d VAs", failed, len(results))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&follow, "follow", false, "Keep streaming new log lines until interrupted")
+	cmd.Flags().IntVarP(&lines, "lines", "n", 100, "Number of existing lines to read from each log before following")
+	cmd.Flags().StringArrayVar(&logs, "log", []string{"ccg"}, "Log to read, ccg or charon, can be repeated")
+	cmd.Flags().StringArrayVar(&levels, "level", []string{}, "Only include ccg lines with this level, can be repeated")
+	cmd.Flags().StringArrayVar(&loggers, "logger", []string{}, "Only include ccg lines whose logger name contains this value, can be repeated")
+	cmd.Flags().StringArrayVar(&classes, "class", []string{}, "Only include ccg lines whose class contains this value, can be repeated")
+	cmd.Flags().BoolVar(&errorsOnly, "errors", false, "Only include lines that look like errors")
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "Output format, text, ndjson or csv")
+	cmd.Flags().StringArrayVar(&raise, "raise", []string{}, "Logger class and level to set on the cluster for the session, can be repeated. Example:\n--raise sailpoint.connector.ADLDAPConnector=TRACE")
+	cmd.Flags().StringVar(&raiseProfile, "raise-profile", "", "Saved log profile to apply to the cluster for the session (see sail cluster log profile)")
+	cmd.Flags().Int32Var(&raiseDuration, "raise-duration", 60, "Minutes the raised levels last if the session does not end cleanly, between 5 and 1440")
+	cmd.Flags().StringArrayVar(&clusterIDs, "cluster-id", []string{}, "Managed cluster to raise log levels on, can be repeated (default the ids of the inventory clusters)")
+	cmd.Flags().StringArrayVarP(&credentials, "passwords", "p", []string{}, "Passwords for the servers in the same order that the servers are listed as arguments")
+	addSSHFlags(cmd, &ssh)
+	addTargetFlags(cmd, &targets, 4)
+
+	return cmd
+}
@@ -0,0 +1,84 @@
+package va
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	beta "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+)
+
+func TestLogStream(t *testing.T) {
+	color.NoColor = true
+
+	lines := []string{
+		`{"@timestamp":"2024-01-01T10:00:00Z","level":"INFO","logger_name":"sailpoint.ccg","message":"started"}`,
+		`{"@timestamp":"2024-01-01T10:00:01Z","level":"ERROR","logger_name":"sailpoint.connector.ADLDAPConnector","message":"bind failed","exception":"javax.naming.AuthenticationException"}`,
+		`charon started`,
+	}
+
+	var out bytes.Buffer
+	stream := newLogStream(&out, "text", logFilter{})
+	for _, line := range lines {
+		if err := stream.handle("va1", []byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := strings.Count(out.String(), "va1 "); got != 3 {
+		t.Errorf("expected 3 lines, got %d:\n%s", got, out.String())
+	}
+	if !strings.Contains(out.String(), "ERROR sailpoint.connector.ADLDAPConnector bind failed\njavax.naming.AuthenticationException\n") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	out.Reset()
+	stream = newLogStream(&out, "ndjson", logFilter{levels: []string{"error"}})
+	for _, line := range lines {
+		if err := stream.handle("va1", []byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := strings.TrimSpace(out.String()); strings.Count(got, "\n") != 0 || !strings.Contains(got, `"va":"va1"`) || !strings.Contains(got, `"message":"bind failed"`) {
+		t.Errorf("unexpected output:\n%s", got)
+	}
+}
+
+func TestTailCommand(t *testing.T) {
+	command, err := tailCommand([]string{"ccg", "charon"}, 50, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "tail -q -n 50 -F '/home/sailpoint/log/ccg.log' '/home/sailpoint/log/charon.log'"
+	if command != want {
+		t.Errorf("expected %q, got %q", want, command)
+	}
+
+	if _, err := tailCommand([]string{"syslog"}, 50, false); err == nil {
+		t.Error("expected an error for an unknown log")
+	}
+}
+
+func TestRestoreConfiguration(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	levels := map[string]beta.StandardLevel{"sailpoint.connector.ADLDAPConnector": beta.STANDARDLEVEL_DEBUG}
+
+	previous := &beta.ClientLogConfiguration{RootLevel: beta.STANDARDLEVEL_WARN, DurationMinutes: 60, LogLevels: &levels}
+	previous.SetExpiration(beta.SailPointTime{Time: now.Add(30 * time.Minute)})
+
+	restored := restoreConfiguration(previous, now)
+	if restored.RootLevel != beta.STANDARDLEVEL_WARN || restored.DurationMinutes != 30 || len(restored.GetLogLevels()) != 1 {
+		t.Errorf("expected the previous configuration for 30 minutes, got %+v", restored)
+	}
+
+	previous.SetExpiration(beta.SailPointTime{Time: now.Add(2 * time.Minute)})
+	restored = restoreConfiguration(previous, now)
+	if restored.RootLevel != beta.STANDARDLEVEL_INFO || restored.DurationMinutes != 5 || len(restored.GetLogLevels()) != 0 {
+		t.Errorf("expected the default configuration, got %+v", restored)
+	}
+
+	if restored := restoreConfiguration(nil, now); restored.RootLevel != beta.STANDARDLEVEL_INFO {
+		t.Errorf("expected the default configuration, got %+v", restored)
+	}
+}
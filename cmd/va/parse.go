@@ -3,21 +3,20 @@ package va
 import (
 	"bufio"
 	"bytes"
+	"compress/gzip"
 	_ "embed"
+	"encoding/csv"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"io"
 	"os"
-	"path"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
 	"github.com/spf13/cobra"
-	"github.com/vbauerster/mpb/v8"
-	"github.com/vbauerster/mpb/v8/decor"
 )
 
 type CANAL struct {
@@ -62,112 +61,71 @@ type CCG struct {
 	SCIMCommon                string    `json:"SCIM Common"`
 }
 
-var cache = make(map[string]*os.File)
-var cacheLock sync.Mutex
+var ccgCSVHeader = []string{"timestamp", "level", "org", "logger_name", "class", "method", "connector-logging", "thread_name", "message", "exception"}
 
-const numWorkers = 8
-
-func saveCanalLine(bytes []byte, dir string) {
-	line := CANAL{}
+func (line CCG) csvRecord() []string {
+	return []string{line.Timestamp.Format(time.RFC3339Nano), line.Level, line.Org, line.Logger_name, line.Class, line.Method, line.ConnectorLogging, line.Thread_name, line.Message, line.Exception}
+}
 
-	lineArray := strings.Split(string(bytes), " ")
+var canalCSVHeader = []string{"month", "day", "time", "hostname", "service", "message"}
 
-	if len(lineArray) > 5 {
-		line.Month = lineArray[0]
-		line.Day = lineArray[1]
-		line.Time = lineArray[2]
-		line.HostName = lineArray[3]
-		line.Service = strings.ReplaceAll(lineArray[4], ":", "")
-		line.Message = strings.ReplaceAll(strings.Join(lineArray[5:], " "), "\n", "")
+func (line CANAL) csvRecord() []string {
+	return []string{line.Month, line.Day, line.Time, line.HostName, line.Service, line.Message}
+}
 
-		if line.HostName != "at" && line.Month != "" && line.Day != "" && line.Time != "" && line.HostName != "" && line.Service != "" && line.Message != "" {
-			folder := "Standard"
-			if strings.Contains(line.Message, "Error") || strings.Contains(line.Message, "WARNING") {
-				folder = "Errors"
-			}
-			filename := path.Join(dir, line.HostName, line.Month+"-"+line.Day, folder, "Canal.log")
-			tempdir, _ := path.Split(filename)
-
-			cacheLock.Lock()
-			defer cacheLock.Unlock()
-
-			f, exists := cache[filename]
-			if !exists {
-				if _, err := os.Stat(tempdir); errors.Is(err, os.ErrNotExist) {
-					err := os.MkdirAll(tempdir, 0700)
-					if err != nil {
-						log.Error(err)
-					}
-				}
-				f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-				if err != nil {
-					panic(err)
-				}
-				cache[filename] = f
-			}
+// logFilter selects log lines. Empty criteria match every line.
+type logFilter struct {
+	since   time.Time
+	until   time.Time
+	levels  []string
+	loggers []string
+	classes []string
+	// errorsOnly keeps only the lines that look like errors
+	errorsOnly bool
+}
 
-			fileWriter := bufio.NewWriter(f)
-			_, writeErr := fileWriter.WriteString(string(bytes))
-			if writeErr != nil {
-				panic(writeErr)
-			}
-			fileWriter.Flush()
-		}
+func (f logFilter) matchTime(t time.Time) bool {
+	if !f.since.IsZero() && t.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && t.After(f.until) {
+		return false
 	}
+	return true
 }
 
-func saveCCGLine(line CCG, dir string, isErr bool) error {
-	folder := "Standard"
-	if isErr {
-		folder = "Errors"
+// matchCCG reports whether a parsed CCG line passes the filter. Levels match
+// exactly, loggers and classes match as substrings, all case insensitively.
+func (f logFilter) matchCCG(line CCG, token []byte) bool {
+	if f.errorsOnly && !ErrorCheck(token) {
+		return false
 	}
-	filename := path.Join(dir, line.Org, folder, line.Timestamp.Format("2006-01-02"), strings.ReplaceAll(line.Logger_name, ".", "-"), "log.json")
-	jsonBytes, _ := json.MarshalIndent(line, "", " ")
-
-	cacheLock.Lock()
-	defer cacheLock.Unlock()
-
-	f, exists := cache[filename]
-	if !exists {
-		tempdir, _ := path.Split(filename)
-		if _, err := os.Stat(tempdir); errors.Is(err, os.ErrNotExist) {
-			err := os.MkdirAll(tempdir, 0700)
-			if err != nil {
-				log.Error(err)
-			}
-		}
-		f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-		if err != nil {
-			return err
-		}
-		cache[filename] = f
+	if !f.matchTime(line.Timestamp) {
+		return false
 	}
-
-	if _, writeErr := f.Write(jsonBytes); writeErr != nil {
-		return writeErr
+	if len(f.levels) > 0 && !matchAny(f.levels, line.Level, strings.EqualFold) {
+		return false
 	}
-
-	return nil
+	if len(f.loggers) > 0 && !matchAny(f.loggers, line.Logger_name, containsFold) {
+		return false
+	}
+	if len(f.classes) > 0 && !matchAny(f.classes, line.Class, containsFold) {
+		return false
+	}
+	return true
 }
 
-func CreateFolder(filepath string) error {
-	tempdir, _ := path.Split(filepath)
-	if _, err := os.Stat(tempdir); errors.Is(err, os.ErrNotExist) {
-		err := os.MkdirAll(tempdir, 0700)
-		if err != nil {
-			return err
+func matchAny(patterns []string, value string, match func(string, string) bool) bool {
+	for _, pattern := range patterns {
+		if match(value, pattern) {
+			return true
 		}
 	}
-	return nil
+	return false
 }
 
-func ParseJSON(str string) []byte {
-	var js json.RawMessage
-	json.Unmarshal([]byte(str), &js)
-	if js != nil {
-		return js
-	}
-	return nil
+func containsFold(s string, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
 }
 
 func ErrorCheck(token []byte) bool {
@@ -176,148 +134,191 @@ func ErrorCheck(token []byte) bool {
 	return bytes.Contains(token, errorString) || bytes.Contains(token, exceptionString)
 }
 
-func ParseCCGFile(p *mpb.Progress, filepath string, all bool) error {
+// parseTimeFlag accepts an RFC 3339 timestamp, a date, a date and time, or a
+// duration such as 90m, 24h or 7d counted back from now
+func parseTimeFlag(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q, use RFC 3339, YYYY-MM-DD[ HH:MM:SS] or a duration like 24h or 7d", value)
+}
+
+// readLogLines calls fn with each line of the log file at filepath,
+// decompressing gzip-rotated logs transparently
+func readLogLines(filepath string, fn func(token []byte) error) error {
 	file, err := os.Open(filepath)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
-	fileinfo, err := os.Stat(filepath)
-	if err != nil {
-		return err
-	}
-
-	dir, base := path.Split(filepath)
-
-	bar := p.AddBar(fileinfo.Size(),
-		mpb.PrependDecorators(
-			decor.Name(fmt.Sprintf("%v", base), decor.WCSyncSpaceR),
-			decor.CountersKiloByte("% .2f / % .2f", decor.WCSyncSpaceR),
-		),
-		mpb.AppendDecorators(
-			decor.Name("["),
-			decor.Percentage(),
-			decor.Name("]["),
-			decor.Elapsed(decor.ET_STYLE_GO),
-			decor.Name(" Elapsed]"),
-		),
-	)
-
-	proxyReader := bar.ProxyReader(file)
-	defer proxyReader.Close()
-
-	bufReader := bufio.NewReader(proxyReader)
-
-	type task struct {
-		line  CCG
-		token []byte
-		dir   string
-	}
 
-	taskChan := make(chan task)
-	var wg sync.WaitGroup
-	wg.Add(numWorkers)
+	lineReader := bufio.NewReaderSize(file, 1024*1024)
 
-	for i := 0; i < numWorkers; i++ {
-		go func() {
-			defer wg.Done()
-			for t := range taskChan {
-				saveCCGLine(t.line, t.dir, ErrorCheck(t.token))
-			}
-		}()
+	if magic, err := lineReader.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzReader, err := gzip.NewReader(lineReader)
+		if err != nil {
+			return fmt.Errorf("could not read gzip log %s: %w", filepath, err)
+		}
+		defer gzReader.Close()
+		lineReader = bufio.NewReaderSize(gzReader, 1024*1024)
 	}
 
 	for {
-		token, err := bufReader.ReadBytes('\n')
-		if err != nil {
-			break
-		} else {
-			if ErrorCheck(token) || all {
-				var line CCG
-				unErr := json.Unmarshal(token, &line)
-				if unErr == nil && line.Org != "" {
-					taskChan <- task{
-						line:  line,
-						token: token,
-						dir:   dir,
-					}
-				}
+		token, err := lineReader.ReadBytes('\n')
+		if len(token) > 0 {
+			if fnErr := fn(token); fnErr != nil {
+				return fnErr
 			}
 		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not read %s: %w", filepath, err)
+		}
+	}
+}
+
+// parseCanalLine splits a syslog style canal line, returning false when the
+// line does not have the expected shape
+func parseCanalLine(token []byte) (CANAL, bool) {
+	line := CANAL{}
+
+	lineArray := strings.Fields(string(token))
+	if len(lineArray) <= 5 {
+		return line, false
+	}
+
+	line.Month = lineArray[0]
+	line.Day = lineArray[1]
+	line.Time = lineArray[2]
+	line.HostName = lineArray[3]
+	line.Service = strings.ReplaceAll(lineArray[4], ":", "")
+	line.Message = strings.Join(lineArray[5:], " ")
+
+	if line.HostName == "at" || line.Service == "" || line.Message == "" {
+		return line, false
 	}
-	close(taskChan)
-	wg.Wait()
-	bar.SetTotal(-1, true)
 
-	return nil
+	return line, true
 }
 
-func ParseCanalFile(p *mpb.Progress, filepath string, all bool) error {
-	file, err := os.Open(filepath)
+// timestamp returns the time of a canal line. Canal lines carry no year, the
+// most recent year that does not place the line in the future is assumed.
+func (line CANAL) timestamp(now time.Time) (time.Time, bool) {
+	t, err := time.ParseInLocation("Jan 2 15:04:05 2006", fmt.Sprintf("%s %s %s %d", line.Month, line.Day, line.Time, now.Year()), time.Local)
 	if err != nil {
-		return err
+		return time.Time{}, false
 	}
-	defer file.Close()
-	fileinfo, err := os.Stat(filepath)
-	if err != nil {
-		return err
+	if t.After(now) {
+		t = t.AddDate(-1, 0, 0)
 	}
+	return t, true
+}
 
-	dir, base := path.Split(filepath)
-
-	bar := p.AddBar(fileinfo.Size(),
-		mpb.PrependDecorators(
-			decor.Name(fmt.Sprintf("%v", base), decor.WCSyncSpaceR),
-			decor.CountersKiloByte("% .2f / % .2f", decor.WCSyncSpaceR),
-		),
-		mpb.AppendDecorators(
-			decor.Name("["),
-			decor.Percentage(),
-			decor.Name("]["),
-			decor.Elapsed(decor.ET_STYLE_GO),
-			decor.Name(" Elapsed]"),
-		),
-	)
-
-	proxyReader := bar.ProxyReader(file)
-	defer proxyReader.Close()
-
-	bufReader := bufio.NewReader(proxyReader)
-
-	type task struct {
-		token []byte
-		dir   string
+// logWriter writes parsed lines as NDJSON or CSV
+type logWriter struct {
+	format  string
+	json    *json.Encoder
+	csv     *csv.Writer
+	header  []string
+	started bool
+}
+
+func newLogWriter(w io.Writer, format string, header []string) *logWriter {
+	lw := &logWriter{format: format, header: header}
+	if format == "csv" {
+		lw.csv = csv.NewWriter(w)
+	} else {
+		lw.json = json.NewEncoder(w)
 	}
+	return lw
+}
 
-	taskChan := make(chan task)
-	var wg sync.WaitGroup
-	wg.Add(numWorkers)
+func (lw *logWriter) write(v interface{}, record []string) error {
+	if lw.format != "csv" {
+		return lw.json.Encode(v)
+	}
 
-	for i := 0; i < numWorkers; i++ {
-		go func() {
-			defer wg.Done()
-			for t := range taskChan {
-				saveCanalLine(t.token, t.dir)
-			}
-		}()
+	if !lw.started {
+		lw.started = true
+		if err := lw.csv.Write(lw.header); err != nil {
+			return err
+		}
 	}
+	return lw.csv.Write(record)
+}
 
-	for {
-		token, err := bufReader.ReadBytes('\n')
-		if err != nil {
-			break
-		} else {
-			taskChan <- task{
-				token: token,
-				dir:   dir,
+func (lw *logWriter) flush() error {
+	if lw.csv == nil {
+		return nil
+	}
+	lw.csv.Flush()
+	return lw.csv.Error()
+}
+
+// ParseCCGFile writes the lines of a CCG log matching filter, returning the
+// number of lines read and written
+func ParseCCGFile(w *logWriter, filepath string, filter logFilter) (int, int, error) {
+	read, written := 0, 0
+	err := readLogLines(filepath, func(token []byte) error {
+		read++
+
+		var line CCG
+		if json.Unmarshal(token, &line) != nil {
+			return nil
+		}
+		if !filter.matchCCG(line, token) {
+			return nil
+		}
+
+		written++
+		return w.write(line, line.csvRecord())
+	})
+	return read, written, err
+}
+
+// ParseCanalFile writes the lines of a canal log matching filter, returning
+// the number of lines read and written
+func ParseCanalFile(w *logWriter, filepath string, filter logFilter, now time.Time) (int, int, error) {
+	read, written := 0, 0
+	err := readLogLines(filepath, func(token []byte) error {
+		read++
+
+		line, ok := parseCanalLine(token)
+		if !ok {
+			return nil
+		}
+		if filter.errorsOnly && !strings.Contains(line.Message, "Error") && !strings.Contains(line.Message, "WARNING") {
+			return nil
+		}
+		if !filter.since.IsZero() || !filter.until.IsZero() {
+			t, ok := line.timestamp(now)
+			if !ok || !filter.matchTime(t) {
+				return nil
 			}
 		}
-	}
-	close(taskChan)
-	wg.Wait()
-	bar.SetTotal(-1, true)
 
-	return nil
+		written++
+		return w.write(line, line.csvRecord())
+	})
+	return read, written, err
 }
 
 //go:embed parse.md
@@ -327,6 +328,13 @@ func newParseCommand() *cobra.Command {
 	help := util.ParseHelp(parseHelp)
 	var fileType string
 	var all bool
+	var since string
+	var until string
+	var levels []string
+	var loggers []string
+	var classes []string
+	var format string
+	var outputFile string
 	cmd := &cobra.Command{
 		Use:     "parse",
 		Short:   "Parse log files from SailPoint virtual appliances",
@@ -334,55 +342,83 @@ func newParseCommand() *cobra.Command {
 		Example: help.Example,
 		Args:    cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if fileType != "ccg" && fileType != "canal" {
+				return fmt.Errorf("unsupported log type %q, use ccg or canal", fileType)
+			}
+			if format != "ndjson" && format != "csv" {
+				return fmt.Errorf("unsupported format %q, use ndjson or csv", format)
+			}
+			if fileType == "canal" && (len(levels) > 0 || len(loggers) > 0 || len(classes) > 0) {
+				return fmt.Errorf("--level, --logger and --class only apply to ccg logs")
+			}
 
-			if fileType != "" {
-
-				var wg sync.WaitGroup
-
-				p := mpb.New(
-					mpb.PopCompletedMode(),
-					mpb.WithWidth(60),
-					mpb.WithRefreshRate(180*time.Millisecond),
-				)
-
-				log.Info("Parsing log files", "files", args)
-
-				log.SetOutput(p)
-				for _, filepath := range args {
-					wg.Add(1)
-
-					switch fileType {
-					case "ccg":
-						go func(filepath string) {
-							defer wg.Done()
-							err := ParseCCGFile(p, filepath, all)
-							if err != nil {
-								log.Error("Issue Parsing log file", "file", filepath, "error", err)
-							}
-						}(filepath)
-					case "canal":
-						go func(filepath string) {
-							defer wg.Done()
-							err := ParseCanalFile(p, filepath, all)
-							if err != nil {
-								log.Error("Issue Parsing log file", "file", filepath, "error", err)
-							}
-						}(filepath)
-					}
+			now := time.Now()
+			filter := logFilter{
+				levels:  levels,
+				loggers: loggers,
+				classes: classes,
+				// an explicit level selection replaces the default error heuristic
+				errorsOnly: !all && len(levels) == 0,
+			}
 
+			var err error
+			if filter.since, err = parseTimeFlag(since, now); err != nil {
+				return err
+			}
+			if filter.until, err = parseTimeFlag(until, now); err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if outputFile != "" {
+				file, err := os.Create(outputFile)
+				if err != nil {
+					return err
 				}
+				defer file.Close()
+				out = file
+			}
+
+			bufOut := bufio.NewWriter(out)
 
-				wg.Wait()
+			var w *logWriter
+			if fileType == "ccg" {
+				w = newLogWriter(bufOut, format, ccgCSVHeader)
 			} else {
-				cmd.Help()
+				w = newLogWriter(bufOut, format, canalCSVHeader)
 			}
-			return nil
 
+			for _, filepath := range args {
+				var read, written int
+				if fileType == "ccg" {
+					read, written, err = ParseCCGFile(w, filepath, filter)
+				} else {
+					read, written, err = ParseCanalFile(w, filepath, filter, now)
+				}
+				if err != nil {
+					return fmt.Errorf("issue parsing log file %s: %w", filepath, err)
+				}
+				log.Info("Parsed log file", "file", filepath, "lines", read, "matched", written)
+			}
+
+			if err := w.flush(); err != nil {
+				return err
+			}
+			return bufOut.Flush()
 		},
 	}
 
 	cmd.Flags().StringVarP(&fileType, "type", "t", "", "Specifies the log type to parse (ccg, canal)")
 	cmd.Flags().BoolVarP(&all, "all", "a", false, "Specifies that all log traffic should be parsed, not just errors")
+	cmd.Flags().StringVar(&since, "since", "", "Only include lines at or after this time (RFC 3339, YYYY-MM-DD[ HH:MM:SS], or a duration like 24h or 7d)")
+	cmd.Flags().StringVar(&until, "until", "", "Only include lines at or before this time, in the same formats as --since")
+	cmd.Flags().StringArrayVar(&levels, "level", []string{}, "Only include ccg lines with this level, can be repeated")
+	cmd.Flags().StringArrayVar(&loggers, "logger", []string{}, "Only include ccg lines whose logger name contains this value, can be repeated")
+	cmd.Flags().StringArrayVar(&classes, "class", []string{}, "Only include ccg lines whose class contains this value, can be repeated")
+	cmd.Flags().StringVarP(&format, "format", "f", "ndjson", "Output format, ndjson or csv")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "File to write the parsed lines to (default stdout)")
+
+	cmd.MarkFlagRequired("type")
 
 	return cmd
 }
@@ -0,0 +1,112 @@
+package va
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testCCGLog = `{"@timestamp":"2024-05-01T10:00:00Z","level":"INFO","org":"acme","logger_name":"sailpoint.ccg.Main","class":"Main","message":"started"}
+{"@timestamp":"2024-05-01T11:00:00Z","level":"ERROR","org":"acme","logger_name":"sailpoint.connector.AD","class":"ADConnector","message":"bind failed","exception":"javax.naming.CommunicationException"}
+not json
+{"@timestamp":"2024-05-02T09:00:00Z","level":"WARN","org":"acme","logger_name":"sailpoint.connector.AD","class":"ADConnector","message":"slow"}
+{"@timestamp":"2024-05-03T09:00:00Z","level":"ERROR","org":"acme","logger_name":"sailpoint.connector.JDBC","class":"JDBCConnector","message":"timeout error"}`
+
+func writeTestLog(t *testing.T, name string, content string, compress bool) string {
+	filepath := path.Join(t.TempDir(), name)
+
+	data := []byte(content)
+	if compress {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, _ = gz.Write(data)
+		_ = gz.Close()
+		data = buf.Bytes()
+	}
+
+	if err := os.WriteFile(filepath, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return filepath
+}
+
+func TestParseCCGFile(t *testing.T) {
+	since, _ := time.Parse(time.RFC3339, "2024-05-01T10:30:00Z")
+	until, _ := time.Parse(time.RFC3339, "2024-05-02T23:00:00Z")
+
+	tests := []struct {
+		name     string
+		filter   logFilter
+		format   string
+		compress bool
+		want     []string
+	}{
+		{"errors only", logFilter{errorsOnly: true}, "ndjson", false, []string{"bind failed", "timeout error"}},
+		{"gzip rotated", logFilter{errorsOnly: true}, "ndjson", true, []string{"bind failed", "timeout error"}},
+		{"time range", logFilter{since: since, until: until}, "ndjson", false, []string{"bind failed", "slow"}},
+		{"level", logFilter{levels: []string{"warn"}}, "ndjson", false, []string{"slow"}},
+		{"logger and class", logFilter{loggers: []string{"connector.ad"}, classes: []string{"ADConnector"}}, "csv", false, []string{"timestamp,level", "bind failed", "slow"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filepath := writeTestLog(t, "ccg.log.1.gz", testCCGLog, tt.compress)
+
+			var out bytes.Buffer
+			w := newLogWriter(&out, tt.format, ccgCSVHeader)
+			_, written, err := ParseCCGFile(w, filepath, tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := w.flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			if len(lines) != len(tt.want) {
+				t.Fatalf("expected %d lines, got %d:\n%s", len(tt.want), len(lines), out.String())
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(lines[i], want) {
+					t.Errorf("line %d: expected %q in %s", i, want, lines[i])
+				}
+			}
+			if tt.format == "ndjson" && written != len(tt.want) {
+				t.Errorf("expected %d written, got %d", len(tt.want), written)
+			}
+		})
+	}
+}
+
+func TestParseTimeFlag(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"", time.Time{}},
+		{"24h", now.Add(-24 * time.Hour)},
+		{"7d", now.AddDate(0, 0, -7)},
+		{"2024-05-01T08:00:00Z", time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTimeFlag(tt.value, now)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%q: expected %s, got %s", tt.value, tt.want, got)
+		}
+	}
+
+	if _, err := parseTimeFlag("yesterday", now); err == nil {
+		t.Error("expected an error for an invalid time")
+	}
+}
@@ -0,0 +1,38 @@
+package va
+
+import (
+	"github.com/sailpoint-oss/sailpoint-cli/internal/va"
+	"github.com/spf13/cobra"
+)
+
+// sshFlags holds the SSH connection flags shared by the commands that connect
+// to VAs directly
+type sshFlags struct {
+	user    string
+	port    int
+	keyFile string
+	agent   bool
+}
+
+func addSSHFlags(cmd *cobra.Command, f *sshFlags) {
+	cmd.Flags().StringVar(&f.user, "user", "", "SSH user (default sailpoint, or the User of a matching ~/.ssh/config entry)")
+	cmd.Flags().IntVar(&f.port, "port", 0, "SSH port (default 22, or the Port of a matching ~/.ssh/config entry)")
+	cmd.Flags().StringVarP(&f.keyFile, "identity-file", "i", "", "Private key used to authenticate instead of a password")
+	cmd.Flags().BoolVar(&f.agent, "agent", false, "Authenticate with the keys loaded in ssh-agent")
+}
+
+// apply overrides the connection settings of opts with the flags that were set
+func (f *sshFlags) apply(opts *va.SSHOptions) {
+	if f.user != "" {
+		opts.User = f.user
+	}
+	if f.port != 0 {
+		opts.Port = f.port
+	}
+	if f.keyFile != "" {
+		opts.KeyFile = f.keyFile
+	}
+	if f.agent {
+		opts.UseAgent = true
+	}
+}
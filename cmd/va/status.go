@@ -0,0 +1,356 @@
+package va
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	sailpoint "github.com/sailpoint-oss/golang-sdk/v2"
+	beta "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/config"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/output"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/sdk"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/terminal"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/va"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+)
+
+//go:embed status.md
+var statusHelp string
+
+type clusterStatus struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Operational bool             `json:"operational"`
+	Status      string           `json:"status,omitempty"`
+	CcgVersion  string           `json:"ccgVersion,omitempty"`
+	Appliances  []applianceState `json:"appliances"`
+}
+
+type applianceState struct {
+	ID        string        `json:"id"`
+	Name      string        `json:"name,omitempty"`
+	IP        string        `json:"ip,omitempty"`
+	Health    string        `json:"health"`
+	Version   string        `json:"version,omitempty"`
+	LastSeen  *time.Time    `json:"lastSeen,omitempty"`
+	SSH       string        `json:"ssh"`
+	SSHError  string        `json:"sshError,omitempty"`
+	OnBox     []checkResult `json:"onBox,omitempty"`
+	sshTarget *va.Host
+}
+
+// runOnBoxChecks runs the checks that are quick enough for a status overview
+func runOnBoxChecks(run commandRunner, now time.Time) []checkResult {
+	var results []checkResult
+	results = append(results, checkServices(run)...)
+	results = append(results, checkContainers(run)...)
+	results = append(results, checkDisk(run)...)
+	results = append(results, checkTimeSync(run, now))
+	return results
+}
+
+// onBoxSummary condenses on-box checks to PASS, or the checks that did not pass
+func onBoxSummary(checks []checkResult) string {
+	var problems []string
+	for _, check := range checks {
+		if check.Status != checkPass {
+			problems = append(problems, fmt.Sprintf("%s %s: %s", check.Status, check.Check, check.Detail))
+		}
+	}
+	if len(problems) == 0 {
+		return fmt.Sprintf("PASS (%d checks)", len(checks))
+	}
+	return strings.Join(problems, "\n")
+}
+
+// fetchClusterStatus reads the managed clusters selected by ID or name, all
+// of them when selection is empty, along with the state of their VAs
+func fetchClusterStatus(ctx context.Context, apiClient *sailpoint.APIClient, selection []string) ([]clusterStatus, error) {
+	clusters, resp, err := sailpoint.PaginateWithDefaults[beta.ManagedCluster](apiClient.Beta.ManagedClustersAPI.GetManagedClusters(ctx))
+	if err != nil {
+		return nil, sdk.HandleSDKError(resp, err)
+	}
+
+	var statuses []clusterStatus
+	found := map[string]bool{}
+	for _, cluster := range clusters {
+		if len(selection) > 0 {
+			key := cluster.Id
+			if !slices.Contains(selection, key) {
+				key = cluster.GetName()
+				if !slices.Contains(selection, key) {
+					continue
+				}
+			}
+			found[key] = true
+		}
+
+		status := clusterStatus{
+			ID:          cluster.Id,
+			Name:        cluster.GetName(),
+			Operational: cluster.GetOperational(),
+			Status:      cluster.GetStatus(),
+			CcgVersion:  cluster.GetCcgVersion(),
+			Appliances:  []applianceState{},
+		}
+
+		for _, id := range cluster.ClientIds {
+			client, resp, err := apiClient.V3.ManagedClientsAPI.GetManagedClient(ctx, id).Execute()
+			if err != nil {
+				return nil, sdk.HandleSDKError(resp, err)
+			}
+			clientStatus, resp, err := apiClient.Beta.ManagedClientsAPI.GetManagedClientStatus(ctx, id).Type_("VA").Execute()
+			if err != nil {
+				return nil, sdk.HandleSDKError(resp, err)
+			}
+
+			appliance := applianceState{
+				ID:      id,
+				Name:    client.GetName(),
+				IP:      client.GetIpAddress(),
+				Health:  string(clientStatus.Status),
+				Version: client.GetVaVersion(),
+				SSH:     "not configured",
+			}
+			if lastSeen := client.GetLastSeen(); !lastSeen.IsZero() {
+				appliance.LastSeen = &lastSeen.Time
+			}
+			status.Appliances = append(status.Appliances, appliance)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	for _, key := range selection {
+		if !found[key] {
+			return nil, fmt.Errorf("managed cluster %q not found", key)
+		}
+	}
+
+	return statuses, nil
+}
+
+// inventoryHosts returns the VA inventory hosts by address. A missing default
+// inventory is not an error, SSH is then only used with --ssh.
+func inventoryHosts(inventoryPath string) (map[string]va.Host, error) {
+	hosts := map[string]va.Host{}
+
+	inventory, err := va.LoadInventory(inventoryPath)
+	if err != nil {
+		if inventoryPath == "" && errors.Is(err, os.ErrNotExist) {
+			return hosts, nil
+		}
+		return nil, err
+	}
+
+	all, err := inventory.Select(nil, nil)
+	if err != nil {
+		return hosts, nil
+	}
+	for _, host := range all {
+		hosts[host.Address] = host
+	}
+
+	return hosts, nil
+}
+
+// pairSSHHosts pairs the appliances with their inventory entry, or with their
+// IP address when useSSH is set, and returns the hosts to run on-box checks
+// on. Passwords are prompted for up front.
+func pairSSHHosts(term terminal.Terminal, statuses []clusterStatus, known map[string]va.Host, useSSH bool, ssh sshFlags) ([]va.Host, error) {
+	var hosts []va.Host
+	for c := range statuses {
+		for a := range statuses[c].Appliances {
+			appliance := &statuses[c].Appliances[a]
+			if appliance.IP == "" {
+				continue
+			}
+
+			host, ok := known[appliance.IP]
+			if !ok && !useSSH {
+				continue
+			}
+			if !ok {
+				host = va.Host{Address: appliance.IP}
+			}
+			host.Cluster = statuses[c].Name
+			ssh.apply(&host.Options)
+
+			if host.Options.NeedsPassword(host.Address) {
+				password, err := term.PromptPassword("Enter password for " + host.Address + ":")
+				if err != nil {
+					return nil, err
+				}
+				host.Options.Password = password
+			}
+
+			appliance.sshTarget = &host
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts, nil
+}
+
+// mergeOnBoxResults adds the outcome of the on-box checks to the appliances
+// they ran on
+func mergeOnBoxResults(statuses []clusterStatus, results []va.HostResult, checks map[string][]checkResult) {
+	sshErrors := map[string]error{}
+	for _, result := range results {
+		sshErrors[result.Host.Address] = result.Err
+	}
+
+	for c := range statuses {
+		for a := range statuses[c].Appliances {
+			appliance := &statuses[c].Appliances[a]
+			if appliance.sshTarget == nil {
+				continue
+			}
+			if err := sshErrors[appliance.sshTarget.Address]; err != nil {
+				appliance.SSH = "unreachable"
+				appliance.SSHError = err.Error()
+			} else {
+				appliance.SSH = "ok"
+				appliance.OnBox = checks[appliance.sshTarget.Address]
+			}
+		}
+	}
+}
+
+func writeClusterStatus(w io.Writer, status clusterStatus) {
+	state := color.GreenString("operational")
+	if !status.Operational {
+		state = color.RedString("not operational")
+	}
+	_, _ = fmt.Fprintln(w, color.New(color.Bold).Sprintf("==> %s (%s) <==", status.Name, status.ID))
+	_, _ = fmt.Fprintf(w, "Status: %s, %s, CCG version %s\n", status.Status, state, status.CcgVersion)
+
+	var entries [][]string
+	for _, appliance := range status.Appliances {
+		lastSeen := ""
+		if appliance.LastSeen != nil {
+			lastSeen = fmt.Sprintf("%s (%s ago)", appliance.LastSeen.Local().Format(time.RFC3339), time.Since(*appliance.LastSeen).Round(time.Second))
+		}
+
+		health := appliance.Health
+		switch beta.ManagedClientStatusEnum(health) {
+		case beta.MANAGEDCLIENTSTATUSENUM_NORMAL:
+			health = color.GreenString(health)
+		case beta.MANAGEDCLIENTSTATUSENUM_WARNING, beta.MANAGEDCLIENTSTATUSENUM_CONFIGURING:
+			health = color.YellowString(health)
+		case beta.MANAGEDCLIENTSTATUSENUM_ERROR, beta.MANAGEDCLIENTSTATUSENUM_FAILED:
+			health = color.RedString(health)
+		}
+
+		onBox := ""
+		switch {
+		case appliance.SSHError != "":
+			onBox = color.RedString(appliance.SSHError)
+		case appliance.OnBox != nil:
+			onBox = onBoxSummary(appliance.OnBox)
+		}
+
+		entries = append(entries, []string{appliance.Name, appliance.IP, health, appliance.Version, lastSeen, appliance.SSH, onBox})
+	}
+
+	output.WriteTable(w, []string{"VA", "IP Address", "API Health", "Version", "Last Seen", "SSH", "On-box Checks"}, entries, "VA")
+	_, _ = fmt.Fprintln(w)
+}
+
+func newStatusCommand(term terminal.Terminal) *cobra.Command {
+	help := util.ParseHelp(statusHelp)
+	var clusters []string
+	var inventoryPath string
+	var useSSH bool
+	var format string
+	var concurrency int
+	var ssh sshFlags
+	cmd := &cobra.Command{
+		Use:     "status [--cluster id]",
+		Short:   "Report the health of VA clusters from the API and on the appliances",
+		Long:    help.Long,
+		Example: help.Example,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "table" && format != "json" {
+				return fmt.Errorf("unsupported format %q, use table or json", format)
+			}
+
+			apiClient, err := config.InitAPIClient(false)
+			if err != nil {
+				return err
+			}
+
+			statuses, err := fetchClusterStatus(cmd.Context(), apiClient, clusters)
+			if err != nil {
+				return err
+			}
+
+			known, err := inventoryHosts(inventoryPath)
+			if err != nil {
+				return err
+			}
+
+			hosts, err := pairSSHHosts(term, statuses, known, useSSH, ssh)
+			if err != nil {
+				return err
+			}
+
+			var lock sync.Mutex
+			checks := map[string][]checkResult{}
+
+			results := va.RunFleet(hosts, concurrency, func(host va.Host) (string, error) {
+				client, err := va.Dial(host.Address, host.Options)
+				if err != nil {
+					return "", err
+				}
+				defer client.Close()
+
+				hostChecks := runOnBoxChecks(func(command string) (string, error) {
+					return va.RunClientCmd(client, command)
+				}, time.Now())
+
+				lock.Lock()
+				checks[host.Address] = hostChecks
+				lock.Unlock()
+				return "", nil
+			})
+
+			mergeOnBoxResults(statuses, results, checks)
+
+			if format == "json" {
+				raw, err := json.MarshalIndent(statuses, "", "  ")
+				if err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), string(raw))
+				return nil
+			}
+
+			for _, status := range statuses {
+				writeClusterStatus(cmd.OutOrStdout(), status)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&clusters, "cluster", []string{}, "ID or name of a managed cluster to report, can be repeated (default all clusters)")
+	cmd.Flags().StringVar(&inventoryPath, "inventory", "", "Path to the VA inventory (default ~/.sailpoint/va-inventory.yaml)")
+	cmd.Flags().BoolVar(&useSSH, "ssh", false, "Run on-box checks on VAs missing from the inventory too, over SSH to their IP address")
+	cmd.Flags().StringVarP(&format, "format", "f", "table", "Output format, table or json")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 4, "Maximum number of VAs checked over SSH at the same time")
+	addSSHFlags(cmd, &ssh)
+
+	return cmd
+}
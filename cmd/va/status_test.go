@@ -0,0 +1,173 @@
+package va
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	sailpoint "github.com/sailpoint-oss/golang-sdk/v2"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/mocks"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/types"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/va"
+)
+
+func TestOnBoxSummary(t *testing.T) {
+	checks := []checkResult{
+		{"Service ccg", checkPass, "active"},
+		{"Disk /", checkWarn, "85% used"},
+		{"Service fluent", checkFail, "failed"},
+	}
+
+	want := "WARN Disk /: 85% used\nFAIL Service fluent: failed"
+	if got := onBoxSummary(checks); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	if got := onBoxSummary(checks[:1]); got != "PASS (1 checks)" {
+		t.Errorf("unexpected summary %q", got)
+	}
+}
+
+// newTestAPIClient returns an API client for a fake tenant serving the given
+// responses by path
+func newTestAPIClient(t *testing.T, responses map[string]string) *sailpoint.APIClient {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			t.Errorf("unexpected request %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	apiClient := sailpoint.NewAPIClient(sailpoint.NewCLIConfiguration(sailpoint.ClientConfiguration{Token: "token", BaseURL: server.URL}))
+	var devNull types.DevNull
+	apiClient.V3.GetConfig().HTTPClient.Logger = devNull
+	apiClient.Beta.GetConfig().HTTPClient.Logger = devNull
+	return apiClient
+}
+
+func TestFetchClusterStatus(t *testing.T) {
+	apiClient := newTestAPIClient(t, map[string]string{
+		"/beta/managed-clusters": `[
+			{"id": "cluster-1", "name": "Austin", "clientType": "CCG", "ccgVersion": "v01", "operational": true, "status": "NORMAL", "clientIds": ["va-1", "va-2"]},
+			{"id": "cluster-2", "name": "Dallas", "clientType": "CCG", "ccgVersion": "v01", "operational": false, "status": "FAILED", "clientIds": ["va-3"]}
+		]`,
+		"/v3/managed-clients/va-1":          `{"id": "va-1", "clientId": "va-1", "clusterId": "cluster-1", "description": "", "type": "VA", "name": "austin-1", "ipAddress": "10.0.0.1", "vaVersion": "va-1234", "lastSeen": "2024-05-01T10:00:00Z"}`,
+		"/v3/managed-clients/va-2":          `{"id": "va-2", "clientId": "va-2", "clusterId": "cluster-1", "description": "", "type": "VA", "name": "austin-2", "ipAddress": "10.0.0.2"}`,
+		"/beta/managed-clients/va-1/status": `{"body": {}, "status": "NORMAL", "type": "VA", "timestamp": "2024-05-01T10:00:00Z"}`,
+		"/beta/managed-clients/va-2/status": `{"body": {}, "status": "ERROR", "type": "VA", "timestamp": "2024-05-01T10:00:00Z"}`,
+	})
+
+	statuses, err := fetchClusterStatus(context.Background(), apiClient, []string{"Austin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 1 || statuses[0].ID != "cluster-1" || !statuses[0].Operational || len(statuses[0].Appliances) != 2 {
+		t.Fatalf("expected only the Austin cluster with its two VAs, got %+v", statuses)
+	}
+
+	first, second := statuses[0].Appliances[0], statuses[0].Appliances[1]
+	if first.Name != "austin-1" || first.IP != "10.0.0.1" || first.Health != "NORMAL" || first.Version != "va-1234" || first.SSH != "not configured" {
+		t.Errorf("unexpected first appliance %+v", first)
+	}
+	if first.LastSeen == nil || first.LastSeen.UTC().Format("2006-01-02T15:04:05Z") != "2024-05-01T10:00:00Z" {
+		t.Errorf("expected the last seen time of the first appliance, got %v", first.LastSeen)
+	}
+	if second.Health != "ERROR" || second.LastSeen != nil {
+		t.Errorf("unexpected second appliance %+v", second)
+	}
+
+	_, err = fetchClusterStatus(context.Background(), apiClient, []string{"Houston"})
+	if err == nil || !strings.Contains(err.Error(), `"Houston" not found`) {
+		t.Errorf("expected an unknown cluster error, got %v", err)
+	}
+}
+
+func TestPairAndMergeOnBoxResults(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	newStatuses := func() []clusterStatus {
+		return []clusterStatus{{ID: "cluster-1", Name: "Austin", Appliances: []applianceState{
+			{ID: "va-1", IP: "10.0.0.1", SSH: "not configured"},
+			{ID: "va-2", IP: "10.0.0.2", SSH: "not configured"},
+			{ID: "va-3", IP: "10.0.0.3", SSH: "not configured"},
+			{ID: "va-4", SSH: "not configured"},
+		}}}
+	}
+	known := map[string]va.Host{
+		"10.0.0.1": {Address: "10.0.0.1", Options: va.SSHOptions{Password: "inventory"}},
+		"10.0.0.2": {Address: "10.0.0.2"},
+	}
+
+	t.Run("Inventory only", func(t *testing.T) {
+		term := mocks.NewMockTerminal(ctrl)
+		term.EXPECT().PromptPassword("Enter password for 10.0.0.2:").Return("prompted", nil)
+
+		statuses := newStatuses()
+		hosts, err := pairSSHHosts(term, statuses, known, false, sshFlags{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hosts) != 2 || hosts[0].Options.Password != "inventory" || hosts[1].Options.Password != "prompted" || hosts[1].Cluster != "Austin" {
+			t.Fatalf("expected the two inventory VAs, got %+v", hosts)
+		}
+
+		results := []va.HostResult{
+			{Host: hosts[0]},
+			{Host: hosts[1], Err: errors.New("connection refused")},
+		}
+		checks := map[string][]checkResult{
+			"10.0.0.1": {{"Service ccg", checkPass, "active"}},
+		}
+		mergeOnBoxResults(statuses, results, checks)
+
+		appliances := statuses[0].Appliances
+		if appliances[0].SSH != "ok" || len(appliances[0].OnBox) != 1 || appliances[0].SSHError != "" {
+			t.Errorf("expected the on-box checks of the first VA, got %+v", appliances[0])
+		}
+		if appliances[1].SSH != "unreachable" || appliances[1].SSHError != "connection refused" || appliances[1].OnBox != nil {
+			t.Errorf("expected the second VA to be unreachable, got %+v", appliances[1])
+		}
+		for _, appliance := range appliances[2:] {
+			if appliance.SSH != "not configured" || appliance.OnBox != nil {
+				t.Errorf("expected the API status only for %s, got %+v", appliance.ID, appliance)
+			}
+		}
+	})
+
+	t.Run("SSH to every VA with an address", func(t *testing.T) {
+		term := mocks.NewMockTerminal(ctrl)
+
+		statuses := newStatuses()
+		hosts, err := pairSSHHosts(term, statuses, known, true, sshFlags{keyFile: "/keys/va"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hosts) != 3 || hosts[2].Address != "10.0.0.3" || hosts[2].Options.KeyFile != "/keys/va" {
+			t.Fatalf("expected the VAs with an address, got %+v", hosts)
+		}
+		if statuses[0].Appliances[3].sshTarget != nil {
+			t.Error("expected the VA without an address to be left out")
+		}
+	})
+
+	t.Run("Prompt failure", func(t *testing.T) {
+		term := mocks.NewMockTerminal(ctrl)
+		term.EXPECT().PromptPassword(gomock.Any()).Return("", errors.New("no terminal"))
+
+		if _, err := pairSSHHosts(term, newStatuses(), known, false, sshFlags{}); err == nil {
+			t.Error("expected the prompt error")
+		}
+	})
+}
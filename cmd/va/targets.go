@@ -0,0 +1,111 @@
+package va
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sailpoint-oss/sailpoint-cli/internal/output"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/terminal"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/va"
+	"github.com/spf13/cobra"
+)
+
+// targetFlags selects the VAs a command runs against, either by address or
+// by cluster and tag from the VA inventory
+type targetFlags struct {
+	inventory   string
+	clusters    []string
+	tags        []string
+	concurrency int
+}
+
+func addTargetFlags(cmd *cobra.Command, f *targetFlags, concurrency int) {
+	cmd.Flags().StringVar(&f.inventory, "inventory", "", "Path to the VA inventory (default ~/.sailpoint/va-inventory.yaml)")
+	cmd.Flags().StringArrayVar(&f.clusters, "cluster", []string{}, "Run against the appliances of an inventory cluster, can be repeated")
+	cmd.Flags().StringArrayVar(&f.tags, "tag", []string{}, "Run against the inventory appliances carrying this tag, can be repeated")
+	cmd.Flags().IntVar(&f.concurrency, "concurrency", concurrency, "Maximum number of VAs processed at the same time")
+}
+
+// hosts resolves the targeted VAs. Addresses given as arguments are paired
+// with passwords in order, inventory hosts take theirs from the inventory.
+// SSH flags override the inventory settings and passwords are prompted for
+// up front, one host at a time, when no other authentication applies.
+func (f *targetFlags) hosts(term terminal.Terminal, args []string, passwords []string, ssh sshFlags) ([]va.Host, error) {
+	var hosts []va.Host
+
+	if len(f.clusters) > 0 || len(f.tags) > 0 {
+		if len(args) > 0 {
+			return nil, fmt.Errorf("VA addresses cannot be combined with --cluster or --tag")
+		}
+
+		inventory, err := va.LoadInventory(f.inventory)
+		if err != nil {
+			return nil, err
+		}
+
+		hosts, err = inventory.Select(f.clusters, f.tags)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if len(args) == 0 {
+			return nil, fmt.Errorf("provide at least one VA address, --cluster or --tag")
+		}
+
+		for i, endpoint := range args {
+			host := va.Host{Address: endpoint}
+			if len(passwords) > i {
+				host.Options.Password = passwords[i]
+			}
+			hosts = append(hosts, host)
+		}
+	}
+
+	for i := range hosts {
+		opts := &hosts[i].Options
+		ssh.apply(opts)
+
+		if opts.NeedsPassword(hosts[i].Address) {
+			var err error
+			opts.Password, err = term.PromptPassword("Enter password for " + hosts[i].Address + ":")
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return hosts, nil
+}
+
+// writeSummary prints one row per VA with the outcome of the operation. The
+// details show the error, or the output when it fits on one line.
+func writeSummary(w io.Writer, results []va.HostResult) {
+	var entries [][]string
+	for _, result := range results {
+		status := "OK"
+		details := strings.TrimSpace(result.Output)
+		if strings.Contains(details, "\n") {
+			details = ""
+		}
+		if result.Err != nil {
+			status = "FAILED"
+			details = result.Err.Error()
+		}
+		entries = append(entries, []string{result.Host.Address, result.Host.Cluster, status, result.Duration.Round(time.Millisecond).String(), details})
+	}
+
+	output.WriteTable(w, []string{"Host", "Cluster", "Result", "Duration", "Details"}, entries, "")
+}
+
+// failedCount returns the number of results holding an error
+func failedCount(results []va.HostResult) int {
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
@@ -1,79 +1,160 @@
 package va
 
 import (
-	"errors"
+	_ "embed"
+	"encoding/json"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
-	"os"
-	"path"
-
 	"github.com/fatih/color"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/output"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/terminal"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/va"
 	"github.com/spf13/cobra"
-	"github.com/vbauerster/mpb/v8"
 )
 
-func NewTroubleshootCmd(term terminal.Terminal) *cobra.Command {
-	var output string
+//go:embed troubleshoot.md
+var troubleshootHelp string
+
+type troubleshootReport struct {
+	Host    string        `json:"host"`
+	Cluster string        `json:"cluster,omitempty"`
+	Error   string        `json:"error,omitempty"`
+	Checks  []checkResult `json:"checks"`
+}
+
+func newTroubleshootCommand(term terminal.Terminal) *cobra.Command {
+	help := util.ParseHelp(troubleshootHelp)
+	var outputDir string
+	var format string
+	var endpoints []string
+	var credentials []string
+	var ssh sshFlags
+	var targets targetFlags
 	cmd := &cobra.Command{
 		Use:     "troubleshoot",
 		Short:   "Perform troubleshooting operations against a virtual appliance",
-		Long:    "\nPerform troubleshooting operations against a virtual appliance\n\n",
-		Example: "sail va troubleshoot 10.10.10.10",
-		Args:    cobra.MinimumNArgs(1),
+		Long:    help.Long,
+		Example: help.Example,
 		RunE: func(cmd *cobra.Command, args []string) error {
-
-			if output == "" {
-				output, _ = os.Getwd()
+			if format != "table" && format != "json" {
+				return fmt.Errorf("unsupported format %q, use table or json", format)
 			}
 
-			var credentials []string
-			for credential := 0; credential < len(args); credential++ {
-				password, _ := term.PromptPassword(fmt.Sprintf("Enter Password for %v:", args[credential]))
-				credentials = append(credentials, password)
+			hosts, err := targets.hosts(term, args, credentials, ssh)
+			if err != nil {
+				return err
 			}
 
-			for index, endpoint := range args {
-				outputDir := path.Join(output, endpoint)
+			var lock sync.Mutex
+			checks := map[string][]checkResult{}
 
-				if _, err := os.Stat(outputDir); errors.Is(err, os.ErrNotExist) {
-					err := os.MkdirAll(outputDir, 0700)
-					if err != nil {
-						return err
-					}
+			results := va.RunFleet(hosts, targets.concurrency, func(host va.Host) (string, error) {
+				client, err := va.Dial(host.Address, host.Options)
+				if err != nil {
+					return "", err
 				}
+				defer client.Close()
+
+				hostChecks := runTroubleshootChecks(func(command string) (string, error) {
+					return va.RunClientCmd(client, command)
+				}, endpoints, time.Now())
 
-				password := credentials[index]
+				lock.Lock()
+				checks[host.Address] = hostChecks
+				lock.Unlock()
 
-				orgErr := va.RunVACmdLive(endpoint, password, TroubleshootingScript)
-				if orgErr != nil {
-					return orgErr
+				failed := 0
+				for _, check := range hostChecks {
+					if check.Status == checkFail {
+						failed++
+					}
+				}
+				if failed > 0 {
+					return "", fmt.Errorf("%d of %d checks failed", failed, len(hostChecks))
+				}
+				return "", nil
+			})
+
+			var reports []troubleshootReport
+			for _, result := range results {
+				report := troubleshootReport{Host: result.Host.Address, Cluster: result.Host.Cluster, Checks: checks[result.Host.Address]}
+				if report.Checks == nil {
+					report.Checks = []checkResult{}
 				}
+				if result.Err != nil {
+					report.Error = result.Err.Error()
+				}
+				reports = append(reports, report)
 
-				var wg sync.WaitGroup
-				p := mpb.New(mpb.WithWidth(60),
-					mpb.PopCompletedMode(),
-					mpb.WithRefreshRate(180*time.Millisecond),
-					mpb.WithWaitGroup(&wg))
+				if outputDir != "" {
+					raw, err := json.MarshalIndent(report, "", "  ")
+					if err != nil {
+						return err
+					}
+					if err := output.WriteFile(outputDir, result.Host.Address+"-troubleshoot.json", raw); err != nil {
+						return err
+					}
+				}
+			}
 
-				err := va.CollectVAFiles(endpoint, password, outputDir, []string{"/home/sailpoint/stuntlog.txt"}, p)
+			if format == "json" {
+				raw, err := json.MarshalIndent(reports, "", "  ")
 				if err != nil {
 					return err
 				}
-
-				color.Green("stuntlog copied to %s", outputDir)
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), string(raw))
+			} else {
+				for _, report := range reports {
+					writeCheckTable(cmd.OutOrStdout(), report)
+				}
+				writeSummary(cmd.OutOrStdout(), results)
 			}
 
+			if failed := failedCount(results); failed > 0 {
+				return fmt.Errorf("troubleshooting found problems on %d of %d VAs", failed, len(results))
+			}
 			return nil
+		},
+	}
 
-		}}
-
-	cmd.Flags().StringP("endpoint", "e", "", "Host to troubleshoot")
-	cmd.Flags().StringVarP(&output, "output", "o", "", "Path to save the log file")
+	cmd.Flags().StringVarP(&outputDir, "output", "o", "", "Folder to save a JSON report per VA")
+	cmd.Flags().StringVarP(&format, "format", "f", "table", "Report format, table or json")
+	cmd.Flags().StringArrayVar(&endpoints, "check-endpoint", []string{}, "Additional host to check DNS resolution and HTTPS connectivity for, can be repeated")
+	cmd.Flags().StringArrayVarP(&credentials, "passwords", "p", []string{}, "Passwords for the servers in the same order that the servers are listed as arguments")
+	addSSHFlags(cmd, &ssh)
+	addTargetFlags(cmd, &targets, 4)
 
 	return cmd
+}
 
+// writeCheckTable prints the checks of a VA with their status colored
+func writeCheckTable(w io.Writer, report troubleshootReport) {
+	printHostOutput(w, va.HostResult{Host: va.Host{Address: report.Host, Cluster: report.Cluster}})
+
+	if report.Error != "" && len(report.Checks) == 0 {
+		_, _ = fmt.Fprintln(w, color.RedString(report.Error))
+		_, _ = fmt.Fprintln(w)
+		return
+	}
+
+	var entries [][]string
+	for _, check := range report.Checks {
+		status := string(check.Status)
+		switch check.Status {
+		case checkPass:
+			status = color.GreenString(status)
+		case checkWarn:
+			status = color.YellowString(status)
+		case checkFail:
+			status = color.RedString(status)
+		}
+		entries = append(entries, []string{check.Check, status, check.Detail})
+	}
+
+	output.WriteTable(w, []string{"Check", "Status", "Detail"}, entries, "")
+	_, _ = fmt.Fprintln(w)
 }
@@ -1,66 +1,272 @@
 package va
 
 import (
+	"context"
 	_ "embed"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
+	sailpoint "github.com/sailpoint-oss/golang-sdk/v2"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/config"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/terminal"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/va"
 	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
 )
 
 //go:embed update.md
 var updateHelp string
 
-func updateAndRebootVA(endpoint, password string) {
-	log.Info("Attempting to update", "VA", endpoint)
-
-	update, updateErr := va.RunVACmd(endpoint, password, UpdateCommand)
-	if updateErr != nil {
-		log.Error("Problem updating", "VA", endpoint, "err", updateErr, "resp", update)
-	} else {
-		log.Info("Virtual appliance updating", "VA", endpoint)
-		reboot, rebootErr := va.RunVACmd(endpoint, password, RebootCommand)
-		if rebootErr != nil && rebootErr.Error() != "wait: remote command exited without exit status or exit signal" {
-			log.Error("Problem rebooting", "Server", endpoint, "err", rebootErr, "resp", reboot)
-		} else {
-			log.Info("Virtual appliance rebooting", "VA", endpoint)
+// update_engine operations, as reported in CURRENT_OP
+const (
+	updateStatusIdle        = "UPDATE_STATUS_IDLE"
+	updateStatusNeedReboot  = "UPDATE_STATUS_UPDATED_NEED_REBOOT"
+	updateStatusReportError = "UPDATE_STATUS_REPORTING_ERROR_EVENT"
+	updateStatusDisabled    = "UPDATE_STATUS_DISABLED"
+)
+
+type updateOptions struct {
+	pollInterval  time.Duration
+	updateTimeout time.Duration
+	rebootTimeout time.Duration
+	healthTimeout time.Duration
+	skipHealth    bool
+}
+
+// parseUpdateStatus parses the KEY=VALUE lines printed by
+// update_engine_client -status
+func parseUpdateStatus(out string) map[string]string {
+	status := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		if key, value, ok := strings.Cut(strings.TrimSpace(line), "="); ok {
+			status[key] = value
 		}
 	}
+	return status
+}
+
+func osVersion(run commandRunner) (string, error) {
+	out, err := run(OSVersionCommand)
+	if err != nil {
+		return "", fmt.Errorf("could not read OS version: %v", err)
+	}
+	version := strings.Trim(strings.TrimPrefix(strings.TrimSpace(out), "VERSION="), `"`)
+	if version == "" {
+		return "", fmt.Errorf("could not read OS version: empty output")
+	}
+	return version, nil
+}
+
+// waitForUpdateEngine triggers an update check and polls update_engine until
+// it settles, returning the final operation
+func waitForUpdateEngine(ctx context.Context, run commandRunner, host va.Host, opts updateOptions) (string, error) {
+	out, err := run(UpdateStatusCommand)
+	if err != nil {
+		return "", fmt.Errorf("could not read update status: %v", err)
+	}
+	lastChecked := parseUpdateStatus(out)["LAST_CHECKED_TIME"]
+
+	if out, err := run(UpdateCommand); err != nil {
+		return "", fmt.Errorf("update check failed: %v %s", err, strings.TrimSpace(out))
+	}
+
+	deadline := time.Now().Add(opts.updateTimeout)
+	for {
+		out, err := run(UpdateStatusCommand)
+		if err != nil {
+			return "", fmt.Errorf("could not read update status: %v", err)
+		}
+		status := parseUpdateStatus(out)
+		op := status["CURRENT_OP"]
 
-	fmt.Println()
+		switch op {
+		case updateStatusNeedReboot, updateStatusDisabled:
+			return op, nil
+		case updateStatusReportError:
+			return op, fmt.Errorf("update_engine reported an error")
+		case updateStatusIdle:
+			// The check is done once its timestamp moves, until then it may not have started
+			if status["LAST_CHECKED_TIME"] != lastChecked {
+				return op, nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return op, fmt.Errorf("update did not finish within %s, last status %s", opts.updateTimeout, op)
+		}
+		log.Debug("Waiting for update_engine", "VA", host.Address, "status", op, "progress", status["PROGRESS"])
+		if err := sleepContext(ctx, opts.pollInterval); err != nil {
+			return op, err
+		}
+	}
+}
+
+// rebootAndWait reboots the VA and waits until it accepts SSH connections
+// again with a new boot ID. It returns the time the reboot was requested.
+func rebootAndWait(ctx context.Context, run commandRunner, host va.Host, opts updateOptions) (time.Time, error) {
+	bootID, err := run(BootIDCommand)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not read boot ID: %v", err)
+	}
+
+	rebootedAt := time.Now()
+	out, err := run(RebootCommand)
+	var exitMissing *ssh.ExitMissingError
+	if err != nil && !errors.As(err, &exitMissing) {
+		return time.Time{}, fmt.Errorf("reboot failed: %v %s", err, strings.TrimSpace(out))
+	}
+	log.Info("Virtual appliance rebooting", "VA", host.Address)
+
+	deadline := time.Now().Add(opts.rebootTimeout)
+	for {
+		if err := sleepContext(ctx, opts.pollInterval); err != nil {
+			return time.Time{}, err
+		}
+
+		current, err := run(BootIDCommand)
+		if err == nil && strings.TrimSpace(current) != strings.TrimSpace(bootID) {
+			return rebootedAt, nil
+		}
+
+		if time.Now().After(deadline) {
+			return time.Time{}, fmt.Errorf("VA did not come back over SSH within %s", opts.rebootTimeout)
+		}
+	}
+}
+
+// sleepContext waits for d, returning early with the context error when ctx
+// is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// updateVA applies a pending update to a VA, rebooting only when an update
+// was staged, then waits for the VA and its cluster to be healthy. Commands
+// go through run, which opens a new connection each time as the VA
+// disconnects while it reboots.
+func updateVA(ctx context.Context, apiClient *sailpoint.APIClient, run commandRunner, host va.Host, opts updateOptions) (string, error) {
+	log.Info("Checking for updates", "VA", host.Address)
+
+	before, err := osVersion(run)
+	if err != nil {
+		return "", err
+	}
+
+	op, err := waitForUpdateEngine(ctx, run, host, opts)
+	if err != nil {
+		return "", err
+	}
+	if op != updateStatusNeedReboot {
+		log.Info("No update staged", "VA", host.Address, "version", before)
+		return "up to date " + before, nil
+	}
+
+	log.Info("Update staged", "VA", host.Address)
+	rebootedAt, err := rebootAndWait(ctx, run, host, opts)
+	if err != nil {
+		return "", err
+	}
+
+	after, err := osVersion(run)
+	if err != nil {
+		return "", err
+	}
+	log.Info("Virtual appliance back online", "VA", host.Address, "version", after)
+	if after == before {
+		return "", fmt.Errorf("VA rebooted but is still on version %s", before)
+	}
+
+	if apiClient != nil && host.ClusterID != "" {
+		log.Info("Waiting for cluster health", "VA", host.Address, "cluster", host.ClusterID)
+		if err := waitForClusterHealth(ctx, apiClient, host.ClusterID, rebootedAt, opts.pollInterval, opts.healthTimeout); err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("updated %s → %s", before, after), nil
 }
 
 func newUpdateCommand(term terminal.Terminal) *cobra.Command {
 	help := util.ParseHelp(updateHelp)
 	var credentials []string
+	var clusterID string
+	var opts updateOptions
+	var ssh sshFlags
+	var targets targetFlags
 	cmd := &cobra.Command{
 		Use:     "update",
 		Short:   "Perform update operations on a SailPoint virtual appliance",
 		Long:    help.Long,
 		Example: help.Example,
-		Args:    cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			for i, endpoint := range args {
-				var password string
+			hosts, err := targets.hosts(term, args, credentials, ssh)
+			if err != nil {
+				return err
+			}
 
-				if len(credentials) > i {
-					password = credentials[i]
+			if clusterID != "" {
+				for i := range hosts {
+					hosts[i].ClusterID = clusterID
+					if hosts[i].Cluster == "" {
+						hosts[i].Cluster = clusterID
+					}
 				}
+			}
 
-				if password == "" {
-					password, _ = term.PromptPassword("Enter password for " + endpoint + ":")
+			var apiClient *sailpoint.APIClient
+			if !opts.skipHealth {
+				for _, host := range hosts {
+					if host.ClusterID != "" {
+						apiClient, err = config.InitAPIClient(false)
+						if err != nil {
+							return err
+						}
+						break
+					}
 				}
+			}
+
+			results := va.RunRolling(hosts, targets.concurrency, func(host va.Host) (string, error) {
+				run := func(command string) (string, error) {
+					return va.RunVACmd(host.Address, host.Options, command)
+				}
+				out, err := updateVA(cmd.Context(), apiClient, run, host, opts)
+				if err != nil {
+					log.Error("Problem updating", "VA", host.Address, "err", err)
+				}
+				return out, err
+			})
+
+			fmt.Fprintln(cmd.OutOrStdout())
+			writeSummary(cmd.OutOrStdout(), results)
 
-				updateAndRebootVA(endpoint, password)
+			if failed := failedCount(results); failed > 0 {
+				return fmt.Errorf("update failed on %d of %d VAs", failed, len(results))
 			}
 			return nil
 		},
 	}
 
 	cmd.Flags().StringArrayVarP(&credentials, "Passwords", "p", []string{}, "You can enter the passwords for the servers in the same order that the servers are listed as arguments")
+	cmd.Flags().StringVar(&clusterID, "cluster-id", "", "Managed cluster ID of the VAs, used to verify cluster health after each reboot")
+	cmd.Flags().DurationVar(&opts.pollInterval, "poll-interval", 10*time.Second, "Interval between update, SSH and health checks")
+	cmd.Flags().DurationVar(&opts.updateTimeout, "update-timeout", 30*time.Minute, "Maximum time to wait for an update to download and stage")
+	cmd.Flags().DurationVar(&opts.rebootTimeout, "reboot-timeout", 15*time.Minute, "Maximum time to wait for a VA to come back over SSH after rebooting")
+	cmd.Flags().DurationVar(&opts.healthTimeout, "health-timeout", 15*time.Minute, "Maximum time to wait for the cluster to report healthy after a reboot")
+	cmd.Flags().BoolVar(&opts.skipHealth, "skip-health", false, "Do not wait for the cluster health after rebooting")
+	addSSHFlags(cmd, &ssh)
+	addTargetFlags(cmd, &targets, 1)
 
 	return cmd
 }
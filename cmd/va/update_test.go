@@ -0,0 +1,237 @@
+package va
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	beta "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/va"
+)
+
+func TestParseUpdateStatus(t *testing.T) {
+	status := parseUpdateStatus("LAST_CHECKED_TIME=1700000000\nPROGRESS=0.000000\nCURRENT_OP=UPDATE_STATUS_UPDATED_NEED_REBOOT\nNEW_VERSION=3850.1.0\n")
+
+	if status["CURRENT_OP"] != updateStatusNeedReboot || status["NEW_VERSION"] != "3850.1.0" || status["LAST_CHECKED_TIME"] != "1700000000" {
+		t.Errorf("unexpected status: %v", status)
+	}
+}
+
+// fakeUpdateVA simulates update_engine and reboots on a VA
+type fakeUpdateVA struct {
+	version       string
+	stagedVersion string
+	op            string
+	lastChecked   int
+	bootID        string
+	// settleOp is the operation update_engine ends on after a check, empty
+	// means it never settles
+	settleOp string
+	// bootIDFailures is the number of boot ID reads failing after a reboot,
+	// as the VA is not accepting SSH connections yet
+	bootIDFailures int
+	// stuck keeps the boot ID from changing on reboot
+	stuck      bool
+	versionErr error
+	commands   []string
+}
+
+func (f *fakeUpdateVA) run(command string) (string, error) {
+	f.commands = append(f.commands, command)
+	switch command {
+	case OSVersionCommand:
+		if f.versionErr != nil {
+			return "", f.versionErr
+		}
+		return `VERSION="` + f.version + `"` + "\n", nil
+	case UpdateStatusCommand:
+		return fmt.Sprintf("LAST_CHECKED_TIME=%d\nPROGRESS=0.000000\nCURRENT_OP=%s\n", f.lastChecked, f.op), nil
+	case UpdateCommand:
+		if f.settleOp == "" {
+			f.op = "UPDATE_STATUS_DOWNLOADING"
+		} else {
+			f.op = f.settleOp
+			f.lastChecked++
+		}
+		return "", nil
+	case RebootCommand:
+		if !f.stuck {
+			f.bootID = f.bootID + "-next"
+			f.version = f.stagedVersion
+			f.op = updateStatusIdle
+		}
+		return "", nil
+	case BootIDCommand:
+		if f.bootIDFailures > 0 && strings.HasSuffix(f.bootID, "-next") {
+			f.bootIDFailures--
+			return "", errors.New("connection refused")
+		}
+		return f.bootID + "\n", nil
+	}
+	return "", fmt.Errorf("unexpected command %q", command)
+}
+
+func (f *fakeUpdateVA) ran(command string) bool {
+	for _, c := range f.commands {
+		if c == command {
+			return true
+		}
+	}
+	return false
+}
+
+func testUpdateOptions() updateOptions {
+	return updateOptions{
+		pollInterval:  time.Millisecond,
+		updateTimeout: 20 * time.Millisecond,
+		rebootTimeout: 20 * time.Millisecond,
+		healthTimeout: 20 * time.Millisecond,
+	}
+}
+
+func TestUpdateVA(t *testing.T) {
+	host := va.Host{Address: "10.0.0.1"}
+
+	t.Run("Idle without reboot", func(t *testing.T) {
+		fake := &fakeUpdateVA{version: "3800.0.0", op: updateStatusIdle, settleOp: updateStatusIdle, bootID: "a"}
+
+		out, err := updateVA(context.Background(), nil, fake.run, host, testUpdateOptions())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out != "up to date 3800.0.0" {
+			t.Errorf("unexpected result %q", out)
+		}
+		if fake.ran(RebootCommand) {
+			t.Error("expected no reboot when no update is staged")
+		}
+	})
+
+	t.Run("Need reboot then reboot", func(t *testing.T) {
+		fake := &fakeUpdateVA{version: "3800.0.0", stagedVersion: "3850.1.0", op: updateStatusIdle, settleOp: updateStatusNeedReboot, bootID: "a", bootIDFailures: 2}
+
+		out, err := updateVA(context.Background(), nil, fake.run, host, testUpdateOptions())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out != "updated 3800.0.0 → 3850.1.0" {
+			t.Errorf("unexpected result %q", out)
+		}
+		if !fake.ran(RebootCommand) {
+			t.Error("expected the VA to be rebooted")
+		}
+	})
+
+	t.Run("Version unchanged after reboot", func(t *testing.T) {
+		fake := &fakeUpdateVA{version: "3800.0.0", stagedVersion: "3800.0.0", op: updateStatusIdle, settleOp: updateStatusNeedReboot, bootID: "a"}
+
+		_, err := updateVA(context.Background(), nil, fake.run, host, testUpdateOptions())
+		if err == nil || !strings.Contains(err.Error(), "still on version 3800.0.0") {
+			t.Errorf("expected unchanged version error, got %v", err)
+		}
+	})
+
+	t.Run("Version read failure", func(t *testing.T) {
+		fake := &fakeUpdateVA{versionErr: errors.New("connection reset"), op: updateStatusIdle, settleOp: updateStatusIdle, bootID: "a"}
+
+		_, err := updateVA(context.Background(), nil, fake.run, host, testUpdateOptions())
+		if err == nil || !strings.Contains(err.Error(), "could not read OS version") {
+			t.Errorf("expected OS version error, got %v", err)
+		}
+		if fake.ran(UpdateCommand) {
+			t.Error("expected no update check without a known starting version")
+		}
+	})
+
+	t.Run("Update timeout", func(t *testing.T) {
+		fake := &fakeUpdateVA{version: "3800.0.0", op: updateStatusIdle, bootID: "a"}
+
+		_, err := updateVA(context.Background(), nil, fake.run, host, testUpdateOptions())
+		if err == nil || !strings.Contains(err.Error(), "did not finish") {
+			t.Errorf("expected update timeout, got %v", err)
+		}
+	})
+
+	t.Run("Reboot timeout", func(t *testing.T) {
+		fake := &fakeUpdateVA{version: "3800.0.0", stagedVersion: "3850.1.0", op: updateStatusIdle, settleOp: updateStatusNeedReboot, bootID: "a", stuck: true}
+
+		_, err := updateVA(context.Background(), nil, fake.run, host, testUpdateOptions())
+		if err == nil || !strings.Contains(err.Error(), "did not come back") {
+			t.Errorf("expected reboot timeout, got %v", err)
+		}
+	})
+}
+
+func TestRebootAndWaitBootID(t *testing.T) {
+	fake := &fakeUpdateVA{bootID: "a", bootIDFailures: 3}
+
+	start := time.Now()
+	rebootedAt, err := rebootAndWait(context.Background(), fake.run, va.Host{Address: "10.0.0.1"}, testUpdateOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rebootedAt.Before(start) {
+		t.Errorf("expected reboot time after %s, got %s", start, rebootedAt)
+	}
+
+	reads := 0
+	for _, c := range fake.commands {
+		if c == BootIDCommand {
+			reads++
+		}
+	}
+	// One read before the reboot, three failures and the new boot ID
+	if reads != 5 {
+		t.Errorf("expected 5 boot ID reads, got %d", reads)
+	}
+}
+
+func TestClientHealth(t *testing.T) {
+	rebootedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		status   beta.ManagedClientStatusEnum
+		lastSeen time.Time
+		healthy  bool
+	}{
+		{"Seen after reboot", beta.MANAGEDCLIENTSTATUSENUM_NORMAL, rebootedAt.Add(time.Minute), true},
+		{"Stale status from before the reboot", beta.MANAGEDCLIENTSTATUSENUM_NORMAL, rebootedAt.Add(-time.Minute), false},
+		{"Never seen", beta.MANAGEDCLIENTSTATUSENUM_NORMAL, time.Time{}, false},
+		{"Unhealthy", beta.MANAGEDCLIENTSTATUSENUM_ERROR, rebootedAt.Add(time.Minute), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			healthy, reason := clientHealth("va-1", tt.status, tt.lastSeen, rebootedAt)
+			if healthy != tt.healthy {
+				t.Errorf("expected healthy %v, got %v (%s)", tt.healthy, healthy, reason)
+			}
+			if !healthy && reason == "" {
+				t.Error("expected a reason when unhealthy")
+			}
+		})
+	}
+}
+
+func TestUpdateVACancelled(t *testing.T) {
+	fake := &fakeUpdateVA{version: "3800.0.0", op: updateStatusIdle, bootID: "a"}
+	opts := testUpdateOptions()
+	opts.pollInterval = time.Hour
+	opts.updateTimeout = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := updateVA(ctx, nil, fake.run, va.Host{Address: "10.0.0.1"}, opts)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the update to be cancelled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Minute {
+		t.Errorf("expected cancellation to interrupt the poll interval, took %s", elapsed)
+	}
+}
@@ -26,11 +26,15 @@ func NewVACommand(term terminal.Terminal) *cobra.Command {
 
 	cmd.AddCommand(
 		newCollectCommand(term),
-		// newTroubleshootCommand(),
+		newExecCommand(term),
+		newTroubleshootCommand(term),
 		newGetCommand(),
 		newParseCommand(),
+		newAnalyzeCommand(),
 		newUpdateCommand(term),
 		newListCommand(),
+		newStatusCommand(term),
+		newLogsCommand(term),
 	)
 
 	return cmd
@@ -0,0 +1,186 @@
+// Copyright (c) 2023, SailPoint Technologies, Inc. All rights reserved.
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/log"
+	sailpoint "github.com/sailpoint-oss/golang-sdk/v2"
+	beta "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/sdk"
+	"github.com/spf13/cobra"
+)
+
+// applyOptions are the flags shared by create and update.
+type applyOptions struct {
+	file      bool
+	directory bool
+	paramFile string
+	params    []string
+	ownerId   string
+	// createMissing creates workflows not found in the tenant instead of
+	// failing.
+	createMissing bool
+}
+
+func (o *applyOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.paramFile, "params", "p", "", "JSON file of parameter values for connection and credential placeholders")
+	cmd.Flags().StringArrayVar(&o.params, "param", nil, "Parameter value as key=value, can be repeated and overrides --params")
+	cmd.Flags().StringVar(&o.ownerId, "owner", "", "Identity ID to own the workflows, required when creating a workflow without an owner")
+}
+
+func (o *applyOptions) owner(workflow portableWorkflow, existing *beta.Workflow) *beta.WorkflowBodyOwner {
+	if o.ownerId != "" {
+		owner := beta.NewWorkflowBodyOwner()
+		owner.SetType("IDENTITY")
+		owner.SetId(o.ownerId)
+		return owner
+	}
+	if workflow.Owner != nil {
+		return workflow.Owner
+	}
+	if existing != nil && existing.Owner != nil {
+		return existing.Owner
+	}
+	return nil
+}
+
+// applyWorkflows creates or updates each workflow file, matching the
+// workflows in the tenant by name so the same files can be applied repeatedly.
+func applyWorkflows(apiClient *sailpoint.APIClient, args []string, opts applyOptions) error {
+	workflowFiles, err := readWorkflowFiles(args, opts.directory)
+	if err != nil {
+		return err
+	}
+
+	supplied, err := loadParameters(opts.paramFile, opts.params)
+	if err != nil {
+		return err
+	}
+
+	var workflows []portableWorkflow
+	for _, filePath := range workflowFiles {
+		workflow, err := readWorkflowFile(filePath)
+		if err != nil {
+			return err
+		}
+
+		workflow, err = resolveParameters(workflow, supplied)
+		if err != nil {
+			return err
+		}
+
+		workflows = append(workflows, workflow)
+	}
+
+	tenantWorkflows, resp, sdkErr := sailpoint.PaginateWithDefaults[beta.Workflow](apiClient.Beta.WorkflowsAPI.ListWorkflows(context.TODO()))
+	if sdkErr != nil {
+		err := sdk.HandleSDKError(resp, sdkErr)
+		if err != nil {
+			return err
+		}
+	}
+
+	existing, err := workflowsByName(tenantWorkflows)
+	if err != nil {
+		return err
+	}
+
+	for _, workflow := range workflows {
+		current, found := existing[workflow.Name]
+
+		switch {
+		case found && workflowUnchanged(current, workflow):
+			log.Info("Workflow unchanged", "name", workflow.Name, "workflowID", current.GetId())
+		case found:
+			body, err := workflowBody(workflow, opts.owner(workflow, &current))
+			if err != nil {
+				return err
+			}
+
+			_, resp, sdkErr := apiClient.Beta.WorkflowsAPI.PutWorkflow(context.TODO(), current.GetId()).WorkflowBody(body).Execute()
+			if sdkErr != nil {
+				err := sdk.HandleSDKError(resp, sdkErr)
+				if err != nil {
+					return err
+				}
+			}
+			log.Info("Workflow updated", "name", workflow.Name, "workflowID", current.GetId())
+		case !opts.createMissing:
+			return fmt.Errorf("workflow %q does not exist, use create to add it", workflow.Name)
+		default:
+			created, err := createWorkflow(apiClient, workflow, opts.owner(workflow, nil))
+			if err != nil {
+				return err
+			}
+			existing[workflow.Name] = created
+			log.Info("Workflow created", "name", workflow.Name, "workflowID", created.GetId())
+		}
+	}
+
+	return nil
+}
+
+// workflowsByName indexes the workflows of the tenant by name. Names do not
+// have to be unique in the tenant, but files are matched by name, so
+// duplicates are reported instead of updating an arbitrary one of them.
+func workflowsByName(workflows []beta.Workflow) (map[string]beta.Workflow, error) {
+	byName := map[string]beta.Workflow{}
+	for _, workflow := range workflows {
+		if other, ok := byName[workflow.GetName()]; ok {
+			return nil, fmt.Errorf("workflows %s and %s are both named %q, rename one of them", other.GetId(), workflow.GetId(), workflow.GetName())
+		}
+		byName[workflow.GetName()] = workflow
+	}
+	return byName, nil
+}
+
+// createWorkflow creates the workflow disabled, as required by the API, and
+// enables it afterwards when the file asks for it.
+func createWorkflow(apiClient *sailpoint.APIClient, workflow portableWorkflow, owner *beta.WorkflowBodyOwner) (beta.Workflow, error) {
+	if owner == nil {
+		return beta.Workflow{}, fmt.Errorf("workflow %q has no owner, use --owner to set one", workflow.Name)
+	}
+
+	disabled := workflow
+	disabled.Enabled = false
+
+	body, err := workflowBody(disabled, owner)
+	if err != nil {
+		return beta.Workflow{}, err
+	}
+
+	createReq := beta.NewCreateWorkflowRequest(body.GetName(), *owner)
+	createReq.Description = body.Description
+	createReq.Definition = body.Definition
+	createReq.Trigger = body.Trigger
+	createReq.Enabled = body.Enabled
+
+	created, resp, sdkErr := apiClient.Beta.WorkflowsAPI.CreateWorkflow(context.TODO()).CreateWorkflowRequest(*createReq).Execute()
+	if sdkErr != nil {
+		err := sdk.HandleSDKError(resp, sdkErr)
+		if err != nil {
+			return beta.Workflow{}, err
+		}
+	}
+
+	if !workflow.Enabled {
+		return *created, nil
+	}
+
+	body, err = workflowBody(workflow, owner)
+	if err != nil {
+		return beta.Workflow{}, err
+	}
+
+	enabled, resp, sdkErr := apiClient.Beta.WorkflowsAPI.PutWorkflow(context.TODO(), created.GetId()).WorkflowBody(body).Execute()
+	if sdkErr != nil {
+		err := sdk.HandleSDKError(resp, sdkErr)
+		if err != nil {
+			return beta.Workflow{}, err
+		}
+	}
+
+	return *enabled, nil
+}
@@ -20,6 +20,7 @@ var downloadHelp string
 func newDownloadCommand() *cobra.Command {
 	help := util.ParseHelp(downloadHelp)
 	var folderPath string
+	var raw bool
 	cmd := &cobra.Command{
 		Use:     "download",
 		Short:   "Download workflows from Identity Security Cloud",
@@ -59,7 +60,15 @@ func newDownloadCommand() *cobra.Command {
 
 				defer file.Close()
 
-				_, err = file.WriteString(util.PrettyPrint(v))
+				var out interface{} = v
+				if !raw {
+					out, err = makePortable(v)
+					if err != nil {
+						return err
+					}
+				}
+
+				_, err = file.WriteString(util.PrettyPrint(out))
 				if err != nil {
 					return err
 				}
@@ -76,6 +85,7 @@ func newDownloadCommand() *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&folderPath, "folder", "f", "workflows", "Folder to save the workflows to.")
+	cmd.Flags().BoolVar(&raw, "raw", false, "Save the workflows as returned by the API instead of the portable form.")
 
 	return cmd
 
@@ -0,0 +1,348 @@
+// Copyright (c) 2023, SailPoint Technologies, Inc. All rights reserved.
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	beta "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+)
+
+// portableWorkflow is the tenant independent form of a workflow written by
+// download and accepted by create and update. Server managed fields such as
+// the id, owner, creator and execution counts are left out, and connection or
+// credential values used by steps are replaced with {{name}} placeholders that
+// are listed in Parameters along with their default value.
+type portableWorkflow struct {
+	Name        string                  `json:"name"`
+	Description string                  `json:"description,omitempty"`
+	Enabled     bool                    `json:"enabled"`
+	Definition  map[string]interface{}  `json:"definition,omitempty"`
+	Trigger     map[string]interface{}  `json:"trigger,omitempty"`
+	Parameters  map[string]string       `json:"parameters,omitempty"`
+	Owner       *beta.WorkflowBodyOwner `json:"owner,omitempty"`
+}
+
+// referenceKeys are the step attribute name fragments treated as connection or
+// credential references when a workflow is made portable.
+var referenceKeys = []string{"connection", "credential", "secret", "password", "token", "apikey", "privatekey"}
+
+// secretKeys are the reference fragments whose values are never written to
+// disk; their parameters have to be supplied when the workflow is created.
+var secretKeys = []string{"secret", "password", "token", "apikey", "privatekey"}
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.\-]+)\s*\}\}`)
+
+var parameterNameReplacer = regexp.MustCompile(`[^A-Za-z0-9_\-]+`)
+
+func matchesAny(key string, fragments []string) bool {
+	key = strings.ToLower(key)
+	for _, fragment := range fragments {
+		if strings.Contains(key, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+func parameterName(step string, attribute string) string {
+	return strings.Trim(parameterNameReplacer.ReplaceAllString(step, "_"), "_") + "." + attribute
+}
+
+func toMap(v interface{}) (map[string]interface{}, error) {
+	if v == nil || reflect.ValueOf(v).IsNil() {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	err = json.Unmarshal(raw, &m)
+	return m, err
+}
+
+// makePortable converts a workflow returned by the API into its portable form.
+func makePortable(workflow beta.Workflow) (portableWorkflow, error) {
+	definition, err := toMap(workflow.Definition)
+	if err != nil {
+		return portableWorkflow{}, err
+	}
+	trigger, err := toMap(workflow.Trigger)
+	if err != nil {
+		return portableWorkflow{}, err
+	}
+
+	parameters, err := parameterizeSteps(definition)
+	if err != nil {
+		return portableWorkflow{}, fmt.Errorf("workflow %q: %v", workflow.GetName(), err)
+	}
+
+	portable := portableWorkflow{
+		Name:        workflow.GetName(),
+		Description: workflow.GetDescription(),
+		Enabled:     workflow.GetEnabled(),
+		Definition:  definition,
+		Trigger:     trigger,
+		Parameters:  parameters,
+	}
+
+	return portable, nil
+}
+
+// parameterizeSteps replaces the connection and credential attributes of each
+// step with placeholders and returns the extracted parameters. Step names that
+// only differ in punctuation map to the same parameter and are rejected.
+func parameterizeSteps(definition map[string]interface{}) (map[string]string, error) {
+	steps, ok := definition["steps"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	parameters := map[string]string{}
+	parameterSteps := map[string]string{}
+	for _, stepName := range sortedKeys(steps) {
+		step := steps[stepName]
+		stepMap, ok := step.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		attributes, ok := stepMap["attributes"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, key := range sortedKeys(attributes) {
+			str, ok := attributes[key].(string)
+			// Keys ending in ".$" hold JSONPath expressions that are evaluated at
+			// runtime and do not depend on the tenant.
+			if !ok || str == "" || strings.HasSuffix(key, ".$") || !matchesAny(key, referenceKeys) {
+				continue
+			}
+			if placeholderPattern.MatchString(str) {
+				continue
+			}
+
+			name := parameterName(stepName, key)
+			if other, ok := parameterSteps[name]; ok && other != stepName {
+				return nil, fmt.Errorf("steps %q and %q both use the parameter %s, rename one of them", other, stepName, name)
+			}
+			parameterSteps[name] = stepName
+			attributes[key] = "{{" + name + "}}"
+			if matchesAny(key, secretKeys) {
+				parameters[name] = ""
+			} else {
+				parameters[name] = str
+			}
+		}
+	}
+
+	if len(parameters) == 0 {
+		return nil, nil
+	}
+	return parameters, nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// readWorkflowFile reads either a portable workflow or a raw workflow as
+// previously written by download. Fields managed by the server are ignored.
+func readWorkflowFile(filePath string) (portableWorkflow, error) {
+	var workflow portableWorkflow
+
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return workflow, err
+	}
+
+	err = json.Unmarshal(contents, &workflow)
+	if err != nil {
+		return workflow, fmt.Errorf("failed to parse workflow file %s: %v", filePath, err)
+	}
+
+	if workflow.Name == "" {
+		return workflow, fmt.Errorf("workflow file %s does not have a name", filePath)
+	}
+
+	return workflow, nil
+}
+
+// readWorkflowFiles collects workflow files from the arguments, reading every
+// JSON file in each argument when directory is set.
+func readWorkflowFiles(args []string, directory bool) ([]string, error) {
+	if !directory {
+		return args, nil
+	}
+
+	var workflowFiles []string
+	for _, workflowDirectory := range args {
+		files, err := os.ReadDir(workflowDirectory)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			if !file.IsDir() && strings.HasSuffix(file.Name(), ".json") {
+				workflowFiles = append(workflowFiles, filepath.Join(workflowDirectory, file.Name()))
+			}
+		}
+	}
+
+	return workflowFiles, nil
+}
+
+// loadParameters merges a JSON parameter file with key=value pairs, the pairs
+// taking precedence.
+func loadParameters(paramFile string, pairs []string) (map[string]string, error) {
+	parameters := map[string]string{}
+
+	if paramFile != "" {
+		contents, err := os.ReadFile(paramFile)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(contents, &parameters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse parameter file %s: %v", paramFile, err)
+		}
+	}
+
+	for _, pair := range pairs {
+		key, value, found := strings.Cut(pair, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid parameter %q, expected key=value", pair)
+		}
+		parameters[key] = value
+	}
+
+	return parameters, nil
+}
+
+// resolveParameters substitutes every placeholder in the workflow definition
+// and trigger. Supplied values take precedence over the defaults stored in the
+// file, and placeholders without a value are reported together.
+func resolveParameters(workflow portableWorkflow, supplied map[string]string) (portableWorkflow, error) {
+	missing := map[string]bool{}
+
+	lookup := func(name string) (string, bool) {
+		if value, ok := supplied[name]; ok {
+			return value, true
+		}
+		if value := workflow.Parameters[name]; value != "" {
+			return value, true
+		}
+		missing[name] = true
+		return "", false
+	}
+
+	resolved := workflow
+	resolved.Parameters = nil
+	resolved.Definition, _ = substitute(workflow.Definition, lookup).(map[string]interface{})
+	resolved.Trigger, _ = substitute(workflow.Trigger, lookup).(map[string]interface{})
+
+	if len(missing) > 0 {
+		var names []string
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return resolved, fmt.Errorf("workflow %q is missing values for parameters: %s", workflow.Name, strings.Join(names, ", "))
+	}
+
+	return resolved, nil
+}
+
+func substitute(value interface{}, lookup func(string) (string, bool)) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = substitute(item, lookup)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = substitute(item, lookup)
+		}
+		return out
+	case string:
+		return placeholderPattern.ReplaceAllStringFunc(v, func(match string) string {
+			name := placeholderPattern.FindStringSubmatch(match)[1]
+			if resolved, ok := lookup(name); ok {
+				return resolved
+			}
+			return match
+		})
+	default:
+		return v
+	}
+}
+
+// workflowBody builds the request body for a resolved workflow.
+func workflowBody(workflow portableWorkflow, owner *beta.WorkflowBodyOwner) (beta.WorkflowBody, error) {
+	body := map[string]interface{}{
+		"name":    workflow.Name,
+		"enabled": workflow.Enabled,
+	}
+	if workflow.Description != "" {
+		body["description"] = workflow.Description
+	}
+	if workflow.Definition != nil {
+		body["definition"] = workflow.Definition
+	}
+	if workflow.Trigger != nil {
+		body["trigger"] = workflow.Trigger
+	}
+	if owner != nil {
+		body["owner"] = owner
+	}
+
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return beta.WorkflowBody{}, err
+	}
+
+	var workflowBody beta.WorkflowBody
+	err = json.Unmarshal(raw, &workflowBody)
+	return workflowBody, err
+}
+
+// workflowUnchanged reports whether the existing workflow already matches the
+// resolved portable form.
+func workflowUnchanged(existing beta.Workflow, desired portableWorkflow) bool {
+	current, err := makePortable(existing)
+	if err != nil {
+		return false
+	}
+	// Compare the tenant values rather than the placeholders.
+	current.Definition, _ = toMap(existing.Definition)
+
+	return current.Name == desired.Name &&
+		current.Description == desired.Description &&
+		current.Enabled == desired.Enabled &&
+		jsonEqual(current.Definition, desired.Definition) &&
+		jsonEqual(current.Trigger, desired.Trigger)
+}
+
+func jsonEqual(a, b interface{}) bool {
+	rawA, errA := json.Marshal(a)
+	rawB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(rawA) == string(rawB)
+}
@@ -0,0 +1,230 @@
+// Copyright (c) 2023, SailPoint Technologies, Inc. All rights reserved.
+package workflow
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	beta "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+)
+
+const rawWorkflow = `{
+	"id": "d201c5e9-d37b-4aff-af14-66414f39d569",
+	"name": "Send Email",
+	"description": "Send an email to the identity",
+	"enabled": true,
+	"executionCount": 12,
+	"failureCount": 1,
+	"created": "2022-01-10T16:06:16.636381447Z",
+	"creator": {"type": "IDENTITY", "id": "2c9180844d7a5b36014d7c7d9a8b0004", "name": "Jane"},
+	"owner": {"type": "IDENTITY", "id": "2c9180844d7a5b36014d7c7d9a8b0004", "name": "Jane"},
+	"definition": {
+		"start": "HTTP Request",
+		"steps": {
+			"HTTP Request": {
+				"actionId": "sp:http",
+				"attributes": {
+					"url": "https://example.com",
+					"connectionId": "c1a4b8e2",
+					"basicAuthPassword": "****",
+					"body.$": "$.trigger.identity"
+				},
+				"nextStep": "End Step",
+				"type": "action"
+			},
+			"End Step": {"type": "success"}
+		}
+	},
+	"trigger": {"type": "EVENT", "attributes": {"id": "idn:identity-created"}}
+}`
+
+func TestMakePortable(t *testing.T) {
+	var workflow beta.Workflow
+	if err := json.Unmarshal([]byte(rawWorkflow), &workflow); err != nil {
+		t.Fatal(err)
+	}
+
+	portable, err := makePortable(workflow)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := json.Marshal(portable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"id":"d201`, "executionCount", "failureCount", "creator", "owner", `"created"`, "****"} {
+		if strings.Contains(string(out), field) {
+			t.Errorf("portable workflow should not contain %s: %s", field, out)
+		}
+	}
+
+	attributes := portable.Definition["steps"].(map[string]interface{})["HTTP Request"].(map[string]interface{})["attributes"].(map[string]interface{})
+	if attributes["connectionId"] != "{{HTTP_Request.connectionId}}" {
+		t.Errorf("connectionId was not parameterized: %v", attributes["connectionId"])
+	}
+	if attributes["basicAuthPassword"] != "{{HTTP_Request.basicAuthPassword}}" {
+		t.Errorf("basicAuthPassword was not parameterized: %v", attributes["basicAuthPassword"])
+	}
+	if attributes["url"] != "https://example.com" || attributes["body.$"] != "$.trigger.identity" {
+		t.Errorf("unexpected attributes: %v", attributes)
+	}
+
+	if portable.Parameters["HTTP_Request.connectionId"] != "c1a4b8e2" {
+		t.Errorf("expected connection default, got %v", portable.Parameters)
+	}
+	if value, ok := portable.Parameters["HTTP_Request.basicAuthPassword"]; !ok || value != "" {
+		t.Errorf("expected empty secret parameter, got %v", portable.Parameters)
+	}
+}
+
+func TestMakePortableParameterCollision(t *testing.T) {
+	var workflow beta.Workflow
+	if err := json.Unmarshal([]byte(rawWorkflow), &workflow); err != nil {
+		t.Fatal(err)
+	}
+	steps := workflow.Definition.GetSteps()
+	steps["HTTP.Request"] = steps["HTTP Request"]
+	workflow.Definition.SetSteps(steps)
+
+	_, err := makePortable(workflow)
+	if err == nil || !strings.Contains(err.Error(), `steps "HTTP Request" and "HTTP.Request" both use the parameter HTTP_Request.basicAuthPassword`) {
+		t.Fatalf("expected a parameter collision error, got %v", err)
+	}
+}
+
+func TestResolveParameters(t *testing.T) {
+	var workflow beta.Workflow
+	if err := json.Unmarshal([]byte(rawWorkflow), &workflow); err != nil {
+		t.Fatal(err)
+	}
+	portable, err := makePortable(workflow)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = resolveParameters(portable, nil)
+	if err == nil || !strings.Contains(err.Error(), "HTTP_Request.basicAuthPassword") {
+		t.Fatalf("expected missing parameter error, got %v", err)
+	}
+
+	resolved, err := resolveParameters(portable, map[string]string{"HTTP_Request.basicAuthPassword": "hunter2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attributes := resolved.Definition["steps"].(map[string]interface{})["HTTP Request"].(map[string]interface{})["attributes"].(map[string]interface{})
+	if attributes["connectionId"] != "c1a4b8e2" || attributes["basicAuthPassword"] != "hunter2" {
+		t.Errorf("unexpected resolved attributes: %v", attributes)
+	}
+	if resolved.Parameters != nil {
+		t.Errorf("resolved workflow should not keep parameters")
+	}
+
+	// The portable form is left untouched so it can be resolved again.
+	original := portable.Definition["steps"].(map[string]interface{})["HTTP Request"].(map[string]interface{})["attributes"].(map[string]interface{})
+	if original["connectionId"] != "{{HTTP_Request.connectionId}}" {
+		t.Errorf("resolve modified the portable workflow: %v", original)
+	}
+
+	body, err := workflowBody(resolved, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body.GetName() != "Send Email" || body.Definition.GetStart() != "HTTP Request" || body.Trigger.GetType() != "EVENT" {
+		t.Errorf("unexpected workflow body: %+v", body)
+	}
+}
+
+func TestWorkflowUnchanged(t *testing.T) {
+	var workflow beta.Workflow
+	if err := json.Unmarshal([]byte(rawWorkflow), &workflow); err != nil {
+		t.Fatal(err)
+	}
+	portable, err := makePortable(workflow)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolved, err := resolveParameters(portable, map[string]string{"HTTP_Request.basicAuthPassword": "****"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !workflowUnchanged(workflow, resolved) {
+		t.Error("expected workflow to be unchanged")
+	}
+
+	resolved.Description = "Something else"
+	if workflowUnchanged(workflow, resolved) {
+		t.Error("expected changed description to be detected")
+	}
+}
+
+func TestReadWorkflowFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "send-email.json"), []byte(rawWorkflow), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := readWorkflowFiles([]string{dir}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0] != filepath.Join(dir, "send-email.json") {
+		t.Fatalf("unexpected files: %v", files)
+	}
+
+	// Raw downloads are still accepted, with server managed fields ignored.
+	workflow, err := readWorkflowFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if workflow.Name != "Send Email" || workflow.Owner.GetId() != "2c9180844d7a5b36014d7c7d9a8b0004" {
+		t.Errorf("unexpected workflow: %+v", workflow)
+	}
+}
+
+func TestLoadParameters(t *testing.T) {
+	paramFile := filepath.Join(t.TempDir(), "params.json")
+	if err := os.WriteFile(paramFile, []byte(`{"a": "file", "b": "file"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	params, err := loadParameters(paramFile, []string{"b=flag", "c=x=y"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["a"] != "file" || params["b"] != "flag" || params["c"] != "x=y" {
+		t.Errorf("unexpected parameters: %v", params)
+	}
+
+	if _, err := loadParameters("", []string{"novalue"}); err == nil {
+		t.Error("expected error for parameter without a value")
+	}
+}
+
+func TestWorkflowsByName(t *testing.T) {
+	first := beta.Workflow{Id: beta.PtrString("wf-1"), Name: beta.PtrString("Send Email")}
+	second := beta.Workflow{Id: beta.PtrString("wf-2"), Name: beta.PtrString("Send Email")}
+	other := beta.Workflow{Id: beta.PtrString("wf-3"), Name: beta.PtrString("Notify")}
+
+	byName, err := workflowsByName([]beta.Workflow{first, other})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sendEmail, notify := byName["Send Email"], byName["Notify"]
+	if sendEmail.GetId() != "wf-1" || notify.GetId() != "wf-3" {
+		t.Errorf("unexpected workflows by name: %v", byName)
+	}
+
+	_, err = workflowsByName([]beta.Workflow{first, other, second})
+	if err == nil || !strings.Contains(err.Error(), "wf-1") || !strings.Contains(err.Error(), "wf-2") {
+		t.Errorf("expected a duplicate name error with both IDs, got %v", err)
+	}
+}
@@ -187,6 +187,21 @@ func InitAPIClient(experimental bool) (*sailpoint.APIClient, error) {
 	return apiClient, nil
 }
 
+// InitEnvAPIClient returns an API client for a configured environment other
+// than the active one. The active environment is left unchanged.
+func InitEnvAPIClient(env string) (*sailpoint.APIClient, error) {
+	env = strings.ToLower(env)
+	if GetEnvironments()[env] == nil {
+		return nil, fmt.Errorf("environment %q is not configured", env)
+	}
+
+	activeEnv := GetActiveEnvironment()
+	SetActiveEnvironment(env)
+	defer SetActiveEnvironment(activeEnv)
+
+	return InitAPIClient(false)
+}
+
 func CheckToken(tokenString string) error {
 	var claims map[string]interface{}
 
@@ -0,0 +1,37 @@
+package config
+
+import (
+	keyring "github.com/zalando/go-keyring"
+)
+
+// connectorSecretsService is the keyring service under which named connector
+// secrets are stored. Secrets are referenced from source configs as
+// ${keyring:name}.
+const connectorSecretsService = "connectors.secrets"
+
+// GetConnectorSecret reads the secret stored as name under the connectors.secrets keyring service
+func GetConnectorSecret(name string) (string, error) {
+	value, err := keyring.Get(connectorSecretsService, name)
+	if err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// SetConnectorSecret stores value as name under the connectors.secrets keyring service
+func SetConnectorSecret(name string, value string) error {
+	err := keyring.Set(connectorSecretsService, name, value)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteConnectorSecret removes name from the connectors.secrets keyring service
+func DeleteConnectorSecret(name string) error {
+	err := keyring.Delete(connectorSecretsService, name)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package config
+
+import "github.com/spf13/viper"
+
+// VACollectProfile is a user defined set of files for sail va collect,
+// configured under vacollectprofiles in config.yaml
+type VACollectProfile struct {
+	Description string   `mapstructure:"description"`
+	Files       []string `mapstructure:"files"`
+	// MaxFileSizeMB caps the size collected from each file, 0 uses the default
+	MaxFileSizeMB int `mapstructure:"maxfilesizemb"`
+}
+
+func GetVACollectProfiles() (map[string]VACollectProfile, error) {
+	profiles := map[string]VACollectProfile{}
+
+	err := viper.UnmarshalKey("vacollectprofiles", &profiles)
+	if err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// ClusterLogProfile is a named set of VA log levels for sail cluster log,
+// configured under clusterlogprofiles in config.yaml. Loggers are kept as
+// class=LEVEL entries since config keys are not case sensitive.
+type ClusterLogProfile struct {
+	Description     string   `mapstructure:"description" yaml:"description,omitempty"`
+	RootLevel       string   `mapstructure:"rootlevel" yaml:"rootlevel"`
+	DurationMinutes int32    `mapstructure:"durationminutes" yaml:"durationminutes,omitempty"`
+	Loggers         []string `mapstructure:"loggers" yaml:"loggers,omitempty"`
+}
+
+func GetClusterLogProfiles() (map[string]ClusterLogProfile, error) {
+	profiles := map[string]ClusterLogProfile{}
+
+	err := viper.UnmarshalKey("clusterlogprofiles", &profiles)
+	if err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+func SetClusterLogProfiles(profiles map[string]ClusterLogProfile) {
+	viper.Set("clusterlogprofiles", profiles)
+}
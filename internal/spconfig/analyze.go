@@ -0,0 +1,322 @@
+package spconfig
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	sailpointbetasdk "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+)
+
+// ExportableTypes are the object types sp-config can export, the only ones
+// whose existence in a tenant can be checked from an export
+var ExportableTypes = []string{
+	"ACCESS_PROFILE", "ACCESS_REQUEST_CONFIG", "ATTR_SYNC_SOURCE_CONFIG", "AUTH_ORG", "CAMPAIGN_FILTER",
+	"FORM_DEFINITION", "GOVERNANCE_GROUP", "IDENTITY_OBJECT_CONFIG", "IDENTITY_PROFILE", "LIFECYCLE_STATE",
+	"NOTIFICATION_TEMPLATE", "PASSWORD_POLICY", "PASSWORD_SYNC_GROUP", "PUBLIC_IDENTITIES_CONFIG", "ROLE",
+	"RULE", "SERVICE_DESK_INTEGRATION", "SOD_POLICY", "SOURCE", "TRANSFORM", "TRIGGER_SUBSCRIPTION", "WORKFLOW",
+}
+
+// Import plan actions
+const (
+	ActionCreate    = "create"
+	ActionOverwrite = "overwrite"
+	ActionUnchanged = "unchanged"
+)
+
+// Reference resolution statuses
+const (
+	// ReferenceInPayload references an object imported along with it
+	ReferenceInPayload = "in payload"
+	// ReferenceMatched references an object with the same ID in the tenant
+	ReferenceMatched = "matched"
+	// ReferenceRemap references an object the tenant has under another ID
+	ReferenceRemap = "needs remapping"
+	// ReferenceUnresolved references an object missing from the tenant
+	ReferenceUnresolved = "unresolved"
+	// ReferenceUnchecked references a type that cannot be exported to check it
+	ReferenceUnchecked = "unchecked"
+)
+
+// ObjectPlan is what an import would do with an object
+type ObjectPlan struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Action  string `json:"action"`
+	Changes int    `json:"changedFields,omitempty"`
+}
+
+// ReferenceCheck is the resolution of a reference from an imported object
+type ReferenceCheck struct {
+	FromType string `json:"fromType"`
+	FromName string `json:"fromName"`
+	Path     string `json:"path"`
+	Type     string `json:"type"`
+	Id       string `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Status   string `json:"status"`
+	// TenantId is the ID of the tenant object matched by name
+	TenantId string `json:"tenantId,omitempty"`
+	// Detail explains an unresolved or unchecked reference looked up in the
+	// tenant
+	Detail string `json:"detail,omitempty"`
+}
+
+// ImportAnalysis is the local prediction of an import against a tenant
+type ImportAnalysis struct {
+	Objects    []ObjectPlan     `json:"objects"`
+	References []ReferenceCheck `json:"references"`
+	// Warnings flag import options that match nothing in the payload
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Counts returns the number of objects per action and references per status
+func (a ImportAnalysis) Counts() (map[string]int, map[string]int) {
+	actions, statuses := map[string]int{}, map[string]int{}
+	for _, object := range a.Objects {
+		actions[object.Action]++
+	}
+	for _, reference := range a.References {
+		statuses[reference.Status]++
+	}
+	return actions, statuses
+}
+
+// FilterObjectOptions keeps the objects selected by the includedIds and
+// includedNames of their type in options, types without options are kept
+// whole. It returns a warning for each selection that matches no object.
+func FilterObjectOptions(export sailpointbetasdk.SpConfigExportResults, options map[string]sailpointbetasdk.ObjectExportImportOptions) (sailpointbetasdk.SpConfigExportResults, []string) {
+	if len(options) == 0 {
+		return export, nil
+	}
+
+	matched := map[string]bool{}
+	objects := []sailpointbetasdk.ConfigObject{}
+	for _, object := range export.Objects {
+		self := object.GetSelf()
+		option, ok := options[self.GetType()]
+		if !ok || (len(option.IncludedIds) == 0 && len(option.IncludedNames) == 0) {
+			objects = append(objects, object)
+			continue
+		}
+
+		idKey := self.GetType() + " id " + self.GetId()
+		nameKey := self.GetType() + " name " + self.GetName()
+		if containsString(option.IncludedIds, self.GetId()) || containsString(option.IncludedNames, self.GetName()) {
+			matched[idKey], matched[nameKey] = true, true
+			objects = append(objects, object)
+		}
+	}
+	export.Objects = objects
+
+	var warnings []string
+	types := make([]string, 0, len(options))
+	for objectType := range options {
+		types = append(types, objectType)
+	}
+	sort.Strings(types)
+	for _, objectType := range types {
+		for _, id := range options[objectType].IncludedIds {
+			if !matched[objectType+" id "+id] {
+				warnings = append(warnings, fmt.Sprintf("objectOptions %s includedIds %s matches no object in the payload", objectType, id))
+			}
+		}
+		for _, name := range options[objectType].IncludedNames {
+			if !matched[objectType+" name "+name] {
+				warnings = append(warnings, fmt.Sprintf("objectOptions %s includedNames %q matches no object in the payload", objectType, name))
+			}
+		}
+	}
+
+	return export, warnings
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ReferencedTypes returns the sorted types referenced by the payload objects,
+// along with the types of the objects themselves, that sp-config can export
+func ReferencedTypes(payload sailpointbetasdk.SpConfigExportResults) []string {
+	types := map[string]bool{}
+	for _, object := range payload.Objects {
+		self := object.GetSelf()
+		types[self.GetType()] = true
+		walkReferences("", object.Object, func(path string, reference map[string]interface{}) {
+			if t, ok := reference["type"].(string); ok {
+				types[t] = true
+			}
+		})
+	}
+
+	sorted := make([]string, 0, len(types))
+	for t := range types {
+		if containsString(ExportableTypes, t) {
+			sorted = append(sorted, t)
+		}
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// walkReferences calls fn with the path of each nested reference to another
+// object, a map with a type, an id and a name
+func walkReferences(path string, value interface{}, fn func(path string, reference map[string]interface{})) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			if reference, ok := v[key].(map[string]interface{}); ok && isTypedReference(reference) {
+				fn(childPath, reference)
+				continue
+			}
+			walkReferences(childPath, v[key], fn)
+		}
+	case []interface{}:
+		for i, child := range v {
+			childPath := path + "[" + strconv.Itoa(i) + "]"
+			if reference, ok := child.(map[string]interface{}); ok && isTypedReference(reference) {
+				fn(childPath, reference)
+				continue
+			}
+			walkReferences(childPath, child, fn)
+		}
+	}
+}
+
+func isTypedReference(v map[string]interface{}) bool {
+	_, hasType := v["type"].(string)
+	return hasType && isReference(v)
+}
+
+// lookupReference checks a reference missing from the tenant export against
+// the tenant itself, which is how references to types sp-config cannot
+// export, like the IDENTITY owners of most objects, get checked
+func lookupReference(check ReferenceCheck, resolve ReferenceResolver) ReferenceCheck {
+	ids, err := resolve(check.Type, ReferenceTarget{Name: check.Name})
+	switch {
+	case errors.Is(err, ErrUnsupportedReference):
+		check.Status = ReferenceUnchecked
+	case err != nil:
+		check.Status = ReferenceUnchecked
+		check.Detail = err.Error()
+	case containsString(ids, check.Id):
+		check.Status = ReferenceMatched
+	case len(ids) == 1:
+		check.Status = ReferenceRemap
+		check.TenantId = ids[0]
+	case len(ids) > 1:
+		check.Status = ReferenceUnresolved
+		check.Detail = fmt.Sprintf("%d objects named %q", len(ids), check.Name)
+	default:
+		check.Status = ReferenceUnresolved
+	}
+	return check
+}
+
+// AnalyzeImport predicts what importing payload does to the tenant whose
+// objects of exportedTypes are in tenant. Objects are matched by type and
+// name. References missing from the tenant export are looked up with resolve
+// when it is set, otherwise references to types that were not exported are
+// left unchecked.
+func AnalyzeImport(payload sailpointbetasdk.SpConfigExportResults, tenant sailpointbetasdk.SpConfigExportResults, exportedTypes []string, resolve ReferenceResolver) (ImportAnalysis, error) {
+	analysis := ImportAnalysis{Objects: []ObjectPlan{}, References: []ReferenceCheck{}}
+
+	tenantObjects, err := comparableObjects(tenant)
+	if err != nil {
+		return analysis, err
+	}
+
+	tenantIds := map[[2]string]bool{}
+	tenantByName := map[[2]string]string{}
+	for _, object := range tenant.Objects {
+		self := object.GetSelf()
+		tenantIds[[2]string{self.GetType(), self.GetId()}] = true
+		tenantByName[[2]string{self.GetType(), self.GetName()}] = self.GetId()
+	}
+
+	type lookupKey struct{ referenceType, id, name string }
+	lookups := map[lookupKey]ReferenceCheck{}
+
+	payloadIds := map[[2]string]bool{}
+	payloadNames := map[[2]string]bool{}
+	for _, object := range payload.Objects {
+		self := object.GetSelf()
+		payloadIds[[2]string{self.GetType(), self.GetId()}] = true
+		payloadNames[[2]string{self.GetType(), self.GetName()}] = true
+	}
+
+	for _, object := range payload.Objects {
+		self := object.GetSelf()
+		key := [2]string{self.GetType(), self.GetName()}
+
+		plan := ObjectPlan{Type: key[0], Name: key[1], Action: ActionCreate}
+		if existing, ok := tenantObjects[key]; ok {
+			normalized, err := normalizeObject(object.Object)
+			if err != nil {
+				return analysis, err
+			}
+			plan.Changes = len(DiffValues("", existing, normalized))
+			plan.Action = ActionOverwrite
+			if plan.Changes == 0 {
+				plan.Action = ActionUnchanged
+			}
+		}
+		analysis.Objects = append(analysis.Objects, plan)
+
+		walkReferences("", object.Object, func(path string, reference map[string]interface{}) {
+			check := ReferenceCheck{FromType: key[0], FromName: key[1], Path: path}
+			check.Type, _ = reference["type"].(string)
+			check.Id, _ = reference["id"].(string)
+			check.Name, _ = reference["name"].(string)
+
+			switch {
+			case payloadIds[[2]string{check.Type, check.Id}] || payloadNames[[2]string{check.Type, check.Name}]:
+				check.Status = ReferenceInPayload
+			case tenantIds[[2]string{check.Type, check.Id}]:
+				check.Status = ReferenceMatched
+			case tenantByName[[2]string{check.Type, check.Name}] != "":
+				check.Status = ReferenceRemap
+				check.TenantId = tenantByName[[2]string{check.Type, check.Name}]
+			case resolve != nil && check.Name != "":
+				key := lookupKey{check.Type, check.Id, check.Name}
+				resolved, ok := lookups[key]
+				if !ok {
+					resolved = lookupReference(check, resolve)
+					lookups[key] = resolved
+				}
+				check.Status, check.TenantId, check.Detail = resolved.Status, resolved.TenantId, resolved.Detail
+			case !containsString(exportedTypes, check.Type):
+				check.Status = ReferenceUnchecked
+			default:
+				check.Status = ReferenceUnresolved
+			}
+
+			analysis.References = append(analysis.References, check)
+		})
+	}
+
+	sort.SliceStable(analysis.Objects, func(i, j int) bool {
+		if analysis.Objects[i].Type != analysis.Objects[j].Type {
+			return analysis.Objects[i].Type < analysis.Objects[j].Type
+		}
+		return analysis.Objects[i].Name < analysis.Objects[j].Name
+	})
+
+	return analysis, nil
+}
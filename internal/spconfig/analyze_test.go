@@ -0,0 +1,186 @@
+package spconfig
+
+import (
+	"testing"
+
+	sailpointbetasdk "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+)
+
+func TestAnalyzeImport(t *testing.T) {
+	payload := sailpointbetasdk.SpConfigExportResults{Objects: []sailpointbetasdk.ConfigObject{
+		testObject("ACCESS_PROFILE", "ap-1", "Admins", map[string]interface{}{
+			"id":          "ap-1",
+			"description": "Admin access",
+			"source":      map[string]interface{}{"type": "SOURCE", "id": "src-1", "name": "Active Directory"},
+			"owner":       map[string]interface{}{"type": "IDENTITY", "id": "owner-1", "name": "jane.doe"},
+			"entitlements": []interface{}{
+				map[string]interface{}{"type": "ENTITLEMENT", "id": "ent-1", "name": "Domain Admins"},
+			},
+		}),
+		testObject("ACCESS_PROFILE", "ap-2", "Readers", map[string]interface{}{
+			"source": map[string]interface{}{"type": "SOURCE", "id": "src-2", "name": "Workday"},
+		}),
+		testObject("ROLE", "role-1", "Helpdesk", map[string]interface{}{
+			"accessProfiles": []interface{}{
+				map[string]interface{}{"type": "ACCESS_PROFILE", "id": "ap-1", "name": "Admins"},
+			},
+		}),
+		testObject("TRANSFORM", "tr-1", "Lower", map[string]interface{}{"type": "lower"}),
+	}}
+
+	tenant := sailpointbetasdk.SpConfigExportResults{Objects: []sailpointbetasdk.ConfigObject{
+		testObject("ACCESS_PROFILE", "ap-9", "Admins", map[string]interface{}{
+			"id":          "ap-9",
+			"description": "Old description",
+			"source":      map[string]interface{}{"type": "SOURCE", "id": "src-9", "name": "Active Directory"},
+			"owner":       map[string]interface{}{"type": "IDENTITY", "id": "owner-1", "name": "jane.doe"},
+			"entitlements": []interface{}{
+				map[string]interface{}{"type": "ENTITLEMENT", "id": "ent-1", "name": "Domain Admins"},
+			},
+		}),
+		testObject("SOURCE", "src-9", "Active Directory", map[string]interface{}{}),
+		testObject("TRANSFORM", "tr-9", "Lower", map[string]interface{}{"type": "lower"}),
+	}}
+
+	types := ReferencedTypes(payload)
+	expectedTypes := []string{"ACCESS_PROFILE", "ROLE", "SOURCE", "TRANSFORM"}
+	if len(types) != len(expectedTypes) {
+		t.Fatalf("expected types %v, got %v", expectedTypes, types)
+	}
+	for i := range types {
+		if types[i] != expectedTypes[i] {
+			t.Fatalf("expected types %v, got %v", expectedTypes, types)
+		}
+	}
+
+	analysis, err := AnalyzeImport(payload, tenant, types, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actions := map[string]string{}
+	for _, object := range analysis.Objects {
+		actions[object.Type+" "+object.Name] = object.Action
+	}
+	expectedActions := map[string]string{
+		"ACCESS_PROFILE Admins":  ActionOverwrite,
+		"ACCESS_PROFILE Readers": ActionCreate,
+		"ROLE Helpdesk":          ActionCreate,
+		"TRANSFORM Lower":        ActionUnchanged,
+	}
+	for key, action := range expectedActions {
+		if actions[key] != action {
+			t.Errorf("expected %s to be %s, got %q", key, action, actions[key])
+		}
+	}
+
+	statuses := map[string]ReferenceCheck{}
+	for _, reference := range analysis.References {
+		statuses[reference.FromName+" "+reference.Path] = reference
+	}
+	expectedStatuses := map[string]string{
+		"Admins source":              ReferenceRemap,
+		"Admins owner":               ReferenceUnchecked,
+		"Admins entitlements[0]":     ReferenceUnchecked,
+		"Readers source":             ReferenceUnresolved,
+		"Helpdesk accessProfiles[0]": ReferenceInPayload,
+	}
+	for key, status := range expectedStatuses {
+		if statuses[key].Status != status {
+			t.Errorf("expected reference %s to be %s, got %q", key, status, statuses[key].Status)
+		}
+	}
+	if statuses["Admins source"].TenantId != "src-9" {
+		t.Errorf("expected the remapped source to point to src-9, got %q", statuses["Admins source"].TenantId)
+	}
+
+	t.Run("Looks up owners in the tenant", func(t *testing.T) {
+		calls := 0
+		resolve := func(referenceType string, target ReferenceTarget) ([]string, error) {
+			calls++
+			switch referenceType + " " + target.Name {
+			case "IDENTITY jane.doe":
+				return []string{"owner-1"}, nil
+			case "IDENTITY john.doe":
+				return []string{"owner-9"}, nil
+			case "IDENTITY sam":
+				return []string{"sam-1", "sam-2"}, nil
+			case "GOVERNANCE_GROUP Auditors":
+				return nil, nil
+			case "ENTITLEMENT Domain Admins":
+				return nil, ErrUnsupportedReference
+			}
+			return nil, nil
+		}
+
+		payload := sailpointbetasdk.SpConfigExportResults{Objects: []sailpointbetasdk.ConfigObject{
+			testObject("ROLE", "role-1", "Helpdesk", map[string]interface{}{
+				"owner":        map[string]interface{}{"type": "IDENTITY", "id": "owner-1", "name": "jane.doe"},
+				"reviewer":     map[string]interface{}{"type": "IDENTITY", "id": "john-src", "name": "john.doe"},
+				"approver":     map[string]interface{}{"type": "IDENTITY", "id": "sam-src", "name": "sam"},
+				"group":        map[string]interface{}{"type": "GOVERNANCE_GROUP", "id": "gg-1", "name": "Auditors"},
+				"entitlements": []interface{}{map[string]interface{}{"type": "ENTITLEMENT", "id": "ent-1", "name": "Domain Admins"}},
+			}),
+			testObject("ROLE", "role-2", "Support", map[string]interface{}{
+				"owner": map[string]interface{}{"type": "IDENTITY", "id": "owner-1", "name": "jane.doe"},
+			}),
+		}}
+
+		analysis, err := AnalyzeImport(payload, sailpointbetasdk.SpConfigExportResults{}, []string{"GOVERNANCE_GROUP", "ROLE"}, resolve)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		statuses := map[string]ReferenceCheck{}
+		for _, reference := range analysis.References {
+			statuses[reference.FromName+" "+reference.Path] = reference
+		}
+		expected := map[string]string{
+			"Helpdesk owner":           ReferenceMatched,
+			"Helpdesk reviewer":        ReferenceRemap,
+			"Helpdesk approver":        ReferenceUnresolved,
+			"Helpdesk group":           ReferenceUnresolved,
+			"Helpdesk entitlements[0]": ReferenceUnchecked,
+			"Support owner":            ReferenceMatched,
+		}
+		for key, status := range expected {
+			if statuses[key].Status != status {
+				t.Errorf("expected reference %s to be %s, got %q", key, status, statuses[key].Status)
+			}
+		}
+		if statuses["Helpdesk reviewer"].TenantId != "owner-9" {
+			t.Errorf("expected the reviewer to be remapped to owner-9, got %q", statuses["Helpdesk reviewer"].TenantId)
+		}
+		if statuses["Helpdesk approver"].Detail == "" {
+			t.Error("expected ambiguous owners to be explained")
+		}
+		if calls != 5 {
+			t.Errorf("expected each reference to be looked up once, got %d lookups", calls)
+		}
+	})
+}
+
+func TestFilterObjectOptions(t *testing.T) {
+	export := sailpointbetasdk.SpConfigExportResults{Objects: []sailpointbetasdk.ConfigObject{
+		testObject("SOURCE", "src-1", "Active Directory", map[string]interface{}{}),
+		testObject("SOURCE", "src-2", "Workday", map[string]interface{}{}),
+		testObject("TRANSFORM", "tr-1", "Lower", map[string]interface{}{}),
+	}}
+
+	filtered, warnings := FilterObjectOptions(export, map[string]sailpointbetasdk.ObjectExportImportOptions{
+		"SOURCE": {IncludedNames: []string{"Workday", "ServiceNow"}},
+	})
+
+	if len(filtered.Objects) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(filtered.Objects))
+	}
+	for _, object := range filtered.Objects {
+		self := object.GetSelf()
+		if self.GetName() == "Active Directory" {
+			t.Error("expected Active Directory to be filtered out")
+		}
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("expected 1 warning, got %v", warnings)
+	}
+}
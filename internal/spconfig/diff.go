@@ -0,0 +1,366 @@
+package spconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	sailpointbetasdk "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+)
+
+// Object diff statuses
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// FieldChange is a field whose value differs between two versions of an
+// object. Old is nil for added fields and New is nil for removed fields.
+type FieldChange struct {
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// ObjectDiff describes an object that was added, removed or changed
+type ObjectDiff struct {
+	Type    string        `json:"type"`
+	Name    string        `json:"name"`
+	Status  string        `json:"status"`
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// ExportDiff is the comparison of two exports, objects being matched by type
+// and name
+type ExportDiff struct {
+	Source    string       `json:"source"`
+	Target    string       `json:"target"`
+	Added     int          `json:"added"`
+	Removed   int          `json:"removed"`
+	Changed   int          `json:"changed"`
+	Unchanged int          `json:"unchanged"`
+	Objects   []ObjectDiff `json:"objects"`
+}
+
+// LoadExport reads an export from a file saved by sail spconfig export, or
+// from the folder of a split export
+func LoadExport(path string) (sailpointbetasdk.SpConfigExportResults, error) {
+	var export sailpointbetasdk.SpConfigExportResults
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return export, err
+	}
+	if info.IsDir() {
+		return ReadSplitExport(path)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return export, err
+	}
+	if err := json.Unmarshal(raw, &export); err != nil {
+		return export, fmt.Errorf("invalid export file %s: %v", path, err)
+	}
+
+	return export, nil
+}
+
+// comparableObjects returns the objects of an export by type and name,
+// normalized for comparison. Objects sharing a type and name cannot be
+// matched and are an error.
+func comparableObjects(export sailpointbetasdk.SpConfigExportResults) (map[[2]string]interface{}, error) {
+	objects := map[[2]string]interface{}{}
+
+	for _, object := range export.Objects {
+		self := object.GetSelf()
+		key := [2]string{self.GetType(), self.GetName()}
+		if _, ok := objects[key]; ok {
+			return nil, fmt.Errorf("duplicate %s objects named %q, objects are matched by type and name", key[0], key[1])
+		}
+
+		normalized, err := normalizeObject(object.Object)
+		if err != nil {
+			return nil, err
+		}
+		objects[key] = normalized
+	}
+
+	return objects, nil
+}
+
+// normalizeObject prepares an object for comparison between tenants. Its
+// values are decoded the same way whatever their source, the id, created
+// and modified fields are dropped, and the id of nested references is dropped
+// too since references are matched by name.
+func normalizeObject(object map[string]interface{}) (interface{}, error) {
+	raw, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(raw, &normalized); err != nil {
+		return nil, err
+	}
+
+	for _, field := range volatileFields {
+		delete(normalized, field)
+	}
+	dropReferenceIds(normalized)
+
+	return normalized, nil
+}
+
+func dropReferenceIds(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, child := range v {
+			if reference, ok := child.(map[string]interface{}); ok && isReference(reference) {
+				delete(reference, "id")
+			}
+			dropReferenceIds(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			if reference, ok := child.(map[string]interface{}); ok && isReference(reference) {
+				delete(reference, "id")
+			}
+			dropReferenceIds(child)
+		}
+	}
+}
+
+// isReference tells if a map looks like a reference to another object, with
+// an id and a name
+func isReference(v map[string]interface{}) bool {
+	_, hasID := v["id"]
+	_, hasName := v["name"]
+	return hasID && hasName
+}
+
+// DiffExports compares a source export with a target export. Objects only in
+// the target are added, objects only in the source are removed.
+func DiffExports(source sailpointbetasdk.SpConfigExportResults, target sailpointbetasdk.SpConfigExportResults) (ExportDiff, error) {
+	diff := ExportDiff{Objects: []ObjectDiff{}}
+
+	sourceObjects, err := comparableObjects(source)
+	if err != nil {
+		return diff, err
+	}
+	targetObjects, err := comparableObjects(target)
+	if err != nil {
+		return diff, err
+	}
+
+	keys := map[[2]string]bool{}
+	for key := range sourceObjects {
+		keys[key] = true
+	}
+	for key := range targetObjects {
+		keys[key] = true
+	}
+	sorted := make([][2]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i][0] != sorted[j][0] {
+			return sorted[i][0] < sorted[j][0]
+		}
+		return sorted[i][1] < sorted[j][1]
+	})
+
+	for _, key := range sorted {
+		before, inSource := sourceObjects[key]
+		after, inTarget := targetObjects[key]
+
+		switch {
+		case !inSource:
+			diff.Added++
+			diff.Objects = append(diff.Objects, ObjectDiff{Type: key[0], Name: key[1], Status: DiffAdded})
+		case !inTarget:
+			diff.Removed++
+			diff.Objects = append(diff.Objects, ObjectDiff{Type: key[0], Name: key[1], Status: DiffRemoved})
+		default:
+			changes := DiffValues("", before, after)
+			if len(changes) == 0 {
+				diff.Unchanged++
+				continue
+			}
+			diff.Changed++
+			diff.Objects = append(diff.Objects, ObjectDiff{Type: key[0], Name: key[1], Status: DiffChanged, Changes: changes})
+		}
+	}
+
+	return diff, nil
+}
+
+// referenceKeys returns the type and name of each element of a list of
+// references, such as the entitlements of an access profile. It returns false
+// when an element is not a reference or two elements share a key, the list is
+// then compared by index.
+func referenceKeys(list []interface{}) ([]string, bool) {
+	keys := make([]string, len(list))
+	seen := map[string]bool{}
+	for i, element := range list {
+		reference, ok := element.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		referenceType, typeOk := reference["type"].(string)
+		name, nameOk := reference["name"].(string)
+		if !typeOk || !nameOk {
+			return nil, false
+		}
+		key := referenceType + ":" + name
+		if seen[key] {
+			return nil, false
+		}
+		seen[key] = true
+		keys[i] = key
+	}
+	return keys, true
+}
+
+// diffReferenceLists compares two lists of references matched by type and
+// name, so the order of the elements does not matter
+func diffReferenceLists(path string, before []interface{}, beforeKeys []string, after []interface{}, afterKeys []string) []FieldChange {
+	afterIndex := map[string]int{}
+	for i, key := range afterKeys {
+		afterIndex[key] = i
+	}
+	inBefore := map[string]bool{}
+
+	var changes []FieldChange
+	for i, key := range beforeKeys {
+		inBefore[key] = true
+		childPath := path + "[" + key + "]"
+		j, ok := afterIndex[key]
+		if !ok {
+			changes = append(changes, FieldChange{Path: childPath, Old: before[i]})
+			continue
+		}
+		changes = append(changes, DiffValues(childPath, before[i], after[j])...)
+	}
+	for j, key := range afterKeys {
+		if !inBefore[key] {
+			changes = append(changes, FieldChange{Path: path + "[" + key + "]", New: after[j]})
+		}
+	}
+	return changes
+}
+
+// DiffValues returns the fields that differ between two decoded JSON values,
+// with paths like attributes.values[2].name. Lists of references are matched
+// by type and name, with paths like entitlements[ENTITLEMENT:Admins].
+func DiffValues(path string, before interface{}, after interface{}) []FieldChange {
+	switch b := before.(type) {
+	case map[string]interface{}:
+		a, ok := after.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		keys := map[string]bool{}
+		for key := range b {
+			keys[key] = true
+		}
+		for key := range a {
+			keys[key] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+
+		var changes []FieldChange
+		for _, key := range sorted {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+
+			beforeValue, inBefore := b[key]
+			afterValue, inAfter := a[key]
+			switch {
+			case !inBefore:
+				changes = append(changes, FieldChange{Path: childPath, New: afterValue})
+			case !inAfter:
+				changes = append(changes, FieldChange{Path: childPath, Old: beforeValue})
+			default:
+				changes = append(changes, DiffValues(childPath, beforeValue, afterValue)...)
+			}
+		}
+		return changes
+
+	case []interface{}:
+		a, ok := after.([]interface{})
+		if !ok {
+			break
+		}
+
+		beforeKeys, beforeOk := referenceKeys(b)
+		afterKeys, afterOk := referenceKeys(a)
+		if beforeOk && afterOk && len(b)+len(a) > 0 {
+			return diffReferenceLists(path, b, beforeKeys, a, afterKeys)
+		}
+
+		var changes []FieldChange
+		for i := 0; i < len(b) || i < len(a); i++ {
+			childPath := path + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= len(b):
+				changes = append(changes, FieldChange{Path: childPath, New: a[i]})
+			case i >= len(a):
+				changes = append(changes, FieldChange{Path: childPath, Old: b[i]})
+			default:
+				changes = append(changes, DiffValues(childPath, b[i], a[i])...)
+			}
+		}
+		return changes
+	}
+
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+	return []FieldChange{{Path: path, Old: before, New: after}}
+}
+
+// FilterExportTypes keeps the objects of an export whose type is included,
+// or every type when include is empty, and not excluded
+func FilterExportTypes(export sailpointbetasdk.SpConfigExportResults, include []string, exclude []string) sailpointbetasdk.SpConfigExportResults {
+	if len(include) == 0 && len(exclude) == 0 {
+		return export
+	}
+
+	objects := []sailpointbetasdk.ConfigObject{}
+	for _, object := range export.Objects {
+		self := object.GetSelf()
+		if len(include) > 0 && !containsType(include, self.GetType()) {
+			continue
+		}
+		if containsType(exclude, self.GetType()) {
+			continue
+		}
+		objects = append(objects, object)
+	}
+	export.Objects = objects
+
+	return export
+}
+
+func containsType(types []string, objectType string) bool {
+	for _, t := range types {
+		if strings.EqualFold(t, objectType) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package spconfig
+
+import (
+	"reflect"
+	"testing"
+
+	sailpointbetasdk "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+)
+
+func TestDiffExports(t *testing.T) {
+	source := sailpointbetasdk.SpConfigExportResults{Objects: []sailpointbetasdk.ConfigObject{
+		testObject("SOURCE", "s1", "Active Directory", map[string]interface{}{"id": "s1", "name": "Active Directory", "owner": map[string]interface{}{"type": "IDENTITY", "id": "o1", "name": "admin"}}),
+		testObject("TRANSFORM", "t1", "Lower", map[string]interface{}{"id": "t1", "type": "lower", "attributes": map[string]interface{}{"input": "a", "values": []interface{}{"x", "y"}}}),
+		testObject("TRANSFORM", "t2", "Removed", map[string]interface{}{"id": "t2", "type": "upper"}),
+	}}
+	target := sailpointbetasdk.SpConfigExportResults{Objects: []sailpointbetasdk.ConfigObject{
+		testObject("SOURCE", "s9", "Active Directory", map[string]interface{}{"id": "s9", "name": "Active Directory", "owner": map[string]interface{}{"type": "IDENTITY", "id": "o9", "name": "admin"}}),
+		testObject("TRANSFORM", "t8", "Lower", map[string]interface{}{"id": "t8", "type": "lower", "attributes": map[string]interface{}{"values": []interface{}{"x", "z", "w"}}, "modified": "2024-01-01T00:00:00Z"}),
+		testObject("ROLE", "r1", "Added", map[string]interface{}{"id": "r1"}),
+	}}
+
+	diff, err := DiffExports(source, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff.Added != 1 || diff.Removed != 1 || diff.Changed != 1 || diff.Unchanged != 1 {
+		t.Errorf("unexpected counts: %+v", diff)
+	}
+
+	wantChanges := []FieldChange{
+		{Path: "attributes.input", Old: "a"},
+		{Path: "attributes.values[1]", Old: "y", New: "z"},
+		{Path: "attributes.values[2]", New: "w"},
+	}
+	for _, object := range diff.Objects {
+		if object.Status == DiffChanged && !reflect.DeepEqual(object.Changes, wantChanges) {
+			t.Errorf("expected changes %v, got %v", wantChanges, object.Changes)
+		}
+	}
+
+	filtered := FilterExportTypes(target, []string{"transform"}, nil)
+	if len(filtered.Objects) != 1 {
+		t.Errorf("expected 1 object after filtering, got %d", len(filtered.Objects))
+	}
+}
+
+func TestDiffValuesReferenceLists(t *testing.T) {
+	entitlement := func(name string) map[string]interface{} {
+		return map[string]interface{}{"type": "ENTITLEMENT", "name": name}
+	}
+
+	before := map[string]interface{}{"entitlements": []interface{}{entitlement("Admins"), entitlement("Users"), entitlement("Readers")}}
+	after := map[string]interface{}{"entitlements": []interface{}{entitlement("Readers"), entitlement("Admins"), entitlement("Writers")}}
+
+	want := []FieldChange{
+		{Path: "entitlements[ENTITLEMENT:Users]", Old: entitlement("Users")},
+		{Path: "entitlements[ENTITLEMENT:Writers]", New: entitlement("Writers")},
+	}
+	if got := DiffValues("", before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected changes %v, got %v", want, got)
+	}
+
+	reordered := map[string]interface{}{"entitlements": []interface{}{entitlement("Readers"), entitlement("Users"), entitlement("Admins")}}
+	if got := DiffValues("", before, reordered); len(got) != 0 {
+		t.Errorf("expected reordered references to be unchanged, got %v", got)
+	}
+}
+
+func TestDiffExportsDuplicateObjects(t *testing.T) {
+	source := sailpointbetasdk.SpConfigExportResults{Objects: []sailpointbetasdk.ConfigObject{
+		testObject("ROLE", "r1", "Auditor", map[string]interface{}{"id": "r1"}),
+		testObject("ROLE", "r2", "Auditor", map[string]interface{}{"id": "r2"}),
+	}}
+
+	if _, err := DiffExports(source, sailpointbetasdk.SpConfigExportResults{}); err == nil {
+		t.Error("expected duplicate objects to be an error")
+	}
+}
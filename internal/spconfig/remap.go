@@ -0,0 +1,271 @@
+package spconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	sailpointbetasdk "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+	"gopkg.in/yaml.v2"
+)
+
+// Reference remapping statuses
+const (
+	// RemapMapped references were rewritten from the mapping file
+	RemapMapped = "mapped"
+	// RemapDiscovered references were rewritten from a search of the target
+	RemapDiscovered = "discovered"
+	// RemapUnchanged references already point to the target object
+	RemapUnchanged = "unchanged"
+	// RemapAmbiguous references match more than one object in the target
+	RemapAmbiguous = "ambiguous"
+	// RemapNotFound references match no object in the target
+	RemapNotFound = "not found"
+	// RemapSkipped references are of a type that cannot be looked up, or are
+	// mapped by name or email while lookups are disabled
+	RemapSkipped = "skipped"
+)
+
+// ErrUnsupportedReference is returned by a ReferenceResolver for the types
+// of references it cannot look up
+var ErrUnsupportedReference = errors.New("reference type cannot be looked up")
+
+// ReferenceTarget identifies the object of the target tenant a reference
+// points to, by ID or by the name or email to look it up with
+type ReferenceTarget struct {
+	Id    string `yaml:"id,omitempty" json:"id,omitempty"`
+	Name  string `yaml:"name,omitempty" json:"name,omitempty"`
+	Email string `yaml:"email,omitempty" json:"email,omitempty"`
+}
+
+// UnmarshalYAML accepts a target ID as a plain string, or a map with an id,
+// a name or an email
+func (t *ReferenceTarget) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var id string
+	if err := unmarshal(&id); err == nil {
+		*t = ReferenceTarget{Id: id}
+		return nil
+	}
+
+	type plain ReferenceTarget
+	return unmarshal((*plain)(t))
+}
+
+// ReferenceMapping maps, per reference type, the name or ID of an object in
+// the source tenant to its target
+type ReferenceMapping map[string]map[string]ReferenceTarget
+
+// Lookup returns the target of a reference, by ID first and then by name
+func (m ReferenceMapping) Lookup(referenceType string, id string, name string) (ReferenceTarget, bool) {
+	targets := m[referenceType]
+	if target, ok := targets[id]; ok && id != "" {
+		return target, true
+	}
+	if target, ok := targets[name]; ok && name != "" {
+		return target, true
+	}
+	return ReferenceTarget{}, false
+}
+
+func (m ReferenceMapping) set(referenceType string, key string, target ReferenceTarget) {
+	if m[referenceType] == nil {
+		m[referenceType] = map[string]ReferenceTarget{}
+	}
+	m[referenceType][key] = target
+}
+
+// LoadReferenceMapping reads a YAML or JSON mapping file
+func LoadReferenceMapping(path string) (ReferenceMapping, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// A saved remap report holds its mapping next to the entries
+	var report struct {
+		Entries []interface{}    `yaml:"entries"`
+		Mapping ReferenceMapping `yaml:"mapping"`
+	}
+	mapping := ReferenceMapping{}
+	if err := yaml.Unmarshal(raw, &report); err == nil && report.Entries != nil {
+		mapping = report.Mapping
+	} else if err := yaml.Unmarshal(raw, &mapping); err != nil {
+		return nil, fmt.Errorf("invalid mapping file %s: %v", path, err)
+	}
+
+	for referenceType, targets := range mapping {
+		for key, target := range targets {
+			if target.Id == "" && target.Name == "" && target.Email == "" {
+				return nil, fmt.Errorf("mapping %s %q has no id, name or email", referenceType, key)
+			}
+		}
+	}
+
+	return mapping, nil
+}
+
+// ReferenceResolver returns the IDs of the objects of the target tenant
+// matching a reference target. It returns ErrUnsupportedReference for the
+// types it cannot look up.
+type ReferenceResolver func(referenceType string, target ReferenceTarget) ([]string, error)
+
+// RemapEntry is the outcome of remapping a reference
+type RemapEntry struct {
+	FromType string `json:"fromType"`
+	FromName string `json:"fromName"`
+	Path     string `json:"path"`
+	Type     string `json:"type"`
+	Name     string `json:"name,omitempty"`
+	OldId    string `json:"oldId,omitempty"`
+	NewId    string `json:"newId,omitempty"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	// Mapped is set when the mapping file names a target for the reference
+	Mapped bool `json:"mapped,omitempty"`
+}
+
+// RemapReport lists the remapped references, and the mapping that was
+// applied, discovered targets included. The mapping can be saved on its own
+// and reused as a mapping file.
+type RemapReport struct {
+	Entries []RemapEntry     `json:"entries"`
+	Mapping ReferenceMapping `json:"mapping"`
+}
+
+// Unresolved returns the references that still hold an ID of the source
+// tenant, as they were ambiguous, not found or failed to be looked up, or were
+// skipped although the mapping file names a target for them
+func (r RemapReport) Unresolved() []RemapEntry {
+	var unresolved []RemapEntry
+	for _, entry := range r.Entries {
+		if entry.Status == RemapAmbiguous || entry.Status == RemapNotFound || (entry.Status == RemapSkipped && entry.Mapped) {
+			unresolved = append(unresolved, entry)
+		}
+	}
+	return unresolved
+}
+
+// Counts returns the number of references per status
+func (r RemapReport) Counts() map[string]int {
+	counts := map[string]int{}
+	for _, entry := range r.Entries {
+		counts[entry.Status]++
+	}
+	return counts
+}
+
+type resolved struct {
+	ids []string
+	err error
+}
+
+// RemapReferences rewrites the ID of the references of the payload objects to
+// objects of the target tenant. References to objects imported along with
+// them are left alone. Targets come from mapping, then from resolve when it
+// is not nil, looking objects up by the name of the reference. The payload
+// objects are modified in place.
+func RemapReferences(payload sailpointbetasdk.SpConfigExportResults, mapping ReferenceMapping, resolve ReferenceResolver) (sailpointbetasdk.SpConfigExportResults, RemapReport) {
+	report := RemapReport{Entries: []RemapEntry{}, Mapping: ReferenceMapping{}}
+	for referenceType, targets := range mapping {
+		for key, target := range targets {
+			report.Mapping.set(referenceType, key, target)
+		}
+	}
+
+	payloadIds := map[[2]string]bool{}
+	payloadNames := map[[2]string]bool{}
+	for _, object := range payload.Objects {
+		self := object.GetSelf()
+		payloadIds[[2]string{self.GetType(), self.GetId()}] = true
+		payloadNames[[2]string{self.GetType(), self.GetName()}] = true
+	}
+
+	cache := map[[2]string]resolved{}
+	lookup := func(referenceType string, target ReferenceTarget) ([]string, error) {
+		if target.Id != "" {
+			return []string{target.Id}, nil
+		}
+		key := [2]string{referenceType, target.Name + "\x00" + target.Email}
+		if result, ok := cache[key]; ok {
+			return result.ids, result.err
+		}
+		ids, err := resolve(referenceType, target)
+		cache[key] = resolved{ids, err}
+		return ids, err
+	}
+
+	for _, object := range payload.Objects {
+		self := object.GetSelf()
+		walkReferences("", object.Object, func(path string, reference map[string]interface{}) {
+			entry := RemapEntry{FromType: self.GetType(), FromName: self.GetName(), Path: path}
+			entry.Type, _ = reference["type"].(string)
+			entry.OldId, _ = reference["id"].(string)
+			entry.Name, _ = reference["name"].(string)
+
+			if payloadIds[[2]string{entry.Type, entry.OldId}] || payloadNames[[2]string{entry.Type, entry.Name}] {
+				return
+			}
+
+			target, mapped := mapping.Lookup(entry.Type, entry.OldId, entry.Name)
+			entry.Mapped = mapped
+			if !mapped {
+				if resolve == nil {
+					return
+				}
+				target = ReferenceTarget{Name: entry.Name}
+			}
+			if target.Id == "" && resolve == nil {
+				entry.Status = RemapSkipped
+				entry.Error = "the mapping has no id and lookups are disabled"
+				report.Entries = append(report.Entries, entry)
+				return
+			}
+
+			ids, err := lookup(entry.Type, target)
+			switch {
+			case errors.Is(err, ErrUnsupportedReference):
+				entry.Status = RemapSkipped
+			case err != nil:
+				entry.Status = RemapNotFound
+				entry.Error = err.Error()
+			case len(ids) == 0:
+				entry.Status = RemapNotFound
+			case len(ids) > 1:
+				entry.Status = RemapAmbiguous
+				entry.Error = "matches " + strings.Join(ids, ", ")
+			default:
+				entry.NewId = ids[0]
+				switch {
+				case entry.NewId == entry.OldId:
+					entry.Status = RemapUnchanged
+				case mapped:
+					entry.Status = RemapMapped
+				default:
+					entry.Status = RemapDiscovered
+				}
+				reference["id"] = entry.NewId
+
+				key := entry.Name
+				if mapped {
+					if _, byId := mapping[entry.Type][entry.OldId]; byId && entry.OldId != "" {
+						key = entry.OldId
+					}
+				}
+				report.Mapping.set(entry.Type, key, ReferenceTarget{Id: entry.NewId})
+			}
+
+			report.Entries = append(report.Entries, entry)
+		})
+	}
+
+	sort.SliceStable(report.Entries, func(i, j int) bool {
+		if report.Entries[i].Type != report.Entries[j].Type {
+			return report.Entries[i].Type < report.Entries[j].Type
+		}
+		return report.Entries[i].Name < report.Entries[j].Name
+	})
+
+	return payload, report
+}
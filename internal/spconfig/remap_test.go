@@ -0,0 +1,164 @@
+package spconfig
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sailpointbetasdk "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+)
+
+func TestLoadReferenceMapping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mapping.yaml")
+	content := "SOURCE:\n  Active Directory: src-target\nIDENTITY:\n  jane.doe:\n    email: jane.doe@acme.com\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mapping, err := LoadReferenceMapping(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if target, ok := mapping.Lookup("SOURCE", "src-1", "Active Directory"); !ok || target.Id != "src-target" {
+		t.Errorf("expected the source to map to src-target, got %+v", target)
+	}
+	if target, ok := mapping.Lookup("IDENTITY", "owner-1", "jane.doe"); !ok || target.Email != "jane.doe@acme.com" {
+		t.Errorf("expected the identity to map to an email, got %+v", target)
+	}
+}
+
+func TestRemapReferencesWithoutLookups(t *testing.T) {
+	payload := sailpointbetasdk.SpConfigExportResults{Objects: []sailpointbetasdk.ConfigObject{
+		testObject("ACCESS_PROFILE", "ap-1", "Admins", map[string]interface{}{
+			"source": map[string]interface{}{"type": "SOURCE", "id": "src-1", "name": "Active Directory"},
+			"owner":  map[string]interface{}{"type": "IDENTITY", "id": "owner-1", "name": "jane.doe"},
+		}),
+	}}
+
+	mapping := ReferenceMapping{
+		"SOURCE":   {"Active Directory": {Id: "src-target"}},
+		"IDENTITY": {"owner-1": {Email: "jane.doe@acme.com"}},
+	}
+
+	payload, report := RemapReferences(payload, mapping, nil)
+
+	if id := payload.Objects[0].Object["owner"].(map[string]interface{})["id"]; id != "owner-1" {
+		t.Errorf("expected the owner to keep its ID, got %v", id)
+	}
+
+	// The owner is mapped by email, which needs a lookup, so it is skipped and
+	// must stop the import rather than keep the source tenant ID
+	unresolved := report.Unresolved()
+	if len(unresolved) != 1 || unresolved[0].Type != "IDENTITY" || unresolved[0].Status != RemapSkipped {
+		t.Errorf("expected the owner mapped by email to be unresolved, got %+v", unresolved)
+	}
+}
+
+func TestRemapReferences(t *testing.T) {
+	payload := sailpointbetasdk.SpConfigExportResults{Objects: []sailpointbetasdk.ConfigObject{
+		testObject("ACCESS_PROFILE", "ap-1", "Admins", map[string]interface{}{
+			"source": map[string]interface{}{"type": "SOURCE", "id": "src-1", "name": "Active Directory"},
+			"owner":  map[string]interface{}{"type": "IDENTITY", "id": "owner-1", "name": "jane.doe"},
+			"entitlements": []interface{}{
+				map[string]interface{}{"type": "ENTITLEMENT", "id": "ent-1", "name": "Domain Admins"},
+				map[string]interface{}{"type": "ENTITLEMENT", "id": "ent-2", "name": "Backup Operators"},
+			},
+		}),
+		testObject("ROLE", "role-1", "Helpdesk", map[string]interface{}{
+			"accessProfiles": []interface{}{
+				map[string]interface{}{"type": "ACCESS_PROFILE", "id": "ap-1", "name": "Admins"},
+			},
+			"owner": map[string]interface{}{"type": "IDENTITY", "id": "owner-1", "name": "jane.doe"},
+			"rule":  map[string]interface{}{"type": "RULE", "id": "rule-1", "name": "Assignment"},
+		}),
+	}}
+
+	mapping := ReferenceMapping{
+		"SOURCE":   {"Active Directory": {Id: "src-target"}},
+		"IDENTITY": {"owner-1": {Email: "jane.doe@acme.com"}},
+	}
+
+	lookups := 0
+	resolve := func(referenceType string, target ReferenceTarget) ([]string, error) {
+		lookups++
+		switch {
+		case referenceType == "IDENTITY" && target.Email == "jane.doe@acme.com":
+			return []string{"owner-target"}, nil
+		case referenceType == "ENTITLEMENT" && target.Name == "Domain Admins":
+			return []string{"ent-a", "ent-b"}, nil
+		case referenceType == "ENTITLEMENT":
+			return nil, nil
+		}
+		return nil, ErrUnsupportedReference
+	}
+
+	payload, report := RemapReferences(payload, mapping, resolve)
+
+	accessProfile := payload.Objects[0].Object
+	if id := accessProfile["source"].(map[string]interface{})["id"]; id != "src-target" {
+		t.Errorf("expected the source to be remapped to src-target, got %v", id)
+	}
+	if id := accessProfile["owner"].(map[string]interface{})["id"]; id != "owner-target" {
+		t.Errorf("expected the owner to be remapped to owner-target, got %v", id)
+	}
+	if id := accessProfile["entitlements"].([]interface{})[0].(map[string]interface{})["id"]; id != "ent-1" {
+		t.Errorf("expected the ambiguous entitlement to be left alone, got %v", id)
+	}
+	role := payload.Objects[1].Object
+	if id := role["accessProfiles"].([]interface{})[0].(map[string]interface{})["id"]; id != "ap-1" {
+		t.Errorf("expected the access profile in the payload to be left alone, got %v", id)
+	}
+
+	// The owner is looked up once for both objects
+	if lookups != 4 {
+		t.Errorf("expected 4 lookups, got %d", lookups)
+	}
+
+	counts := report.Counts()
+	expected := map[string]int{RemapMapped: 3, RemapAmbiguous: 1, RemapNotFound: 1, RemapSkipped: 1}
+	for status, count := range expected {
+		if counts[status] != count {
+			t.Errorf("expected %d %s references, got %d", count, status, counts[status])
+		}
+	}
+
+	if target := report.Mapping["IDENTITY"]["owner-1"]; target.Id != "owner-target" {
+		t.Errorf("expected the report mapping to resolve the owner to owner-target, got %+v", target)
+	}
+
+	unresolved := report.Unresolved()
+	if len(unresolved) != 2 {
+		t.Fatalf("expected the ambiguous and missing entitlements to be unresolved, got %+v", unresolved)
+	}
+	for _, entry := range unresolved {
+		if entry.Type != "ENTITLEMENT" {
+			t.Errorf("unexpected unresolved reference %+v", entry)
+		}
+	}
+
+	// The saved mapping and the saved report can both be used as mapping files
+	dir := t.TempDir()
+	for name, value := range map[string]interface{}{"mapping.json": report.Mapping, "report.json": report} {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, raw, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		reloaded, err := LoadReferenceMapping(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if target, ok := reloaded.Lookup("IDENTITY", "owner-1", "jane.doe"); !ok || target.Id != "owner-target" {
+			t.Errorf("%s: expected the owner to map to owner-target, got %+v", name, target)
+		}
+		if target, ok := reloaded.Lookup("SOURCE", "src-1", "Active Directory"); !ok || target.Id != "src-target" {
+			t.Errorf("%s: expected the source to map to src-target, got %+v", name, target)
+		}
+	}
+}
@@ -2,15 +2,20 @@ package spconfig
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"path"
-	"time"
+	"sort"
 
 	"github.com/charmbracelet/log"
 	"github.com/fatih/color"
 	sailpoint "github.com/sailpoint-oss/golang-sdk/v2"
 	sailpointbetasdk "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
 	"github.com/sailpoint-oss/sailpoint-cli/internal/output"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/sdk"
 )
 
 func PrintJob(x interface{}) {
@@ -26,72 +31,139 @@ func PrintJob(x interface{}) {
 	}
 }
 
-func DownloadExport(apiClient sailpoint.APIClient, jobId string, fileName string, folderPath string) error {
+// WaitForExport waits for an export job to end and returns its results
+func WaitForExport(apiClient sailpoint.APIClient, jobId string, opts WaitOptions) (*sailpointbetasdk.SpConfigExportResults, error) {
+	_, err := WaitForJob("export", jobId, func(ctx context.Context) (JobStatus, error) {
+		response, resp, err := apiClient.Beta.SPConfigAPI.GetSpConfigExportStatus(ctx, jobId).Execute()
+		if err != nil {
+			return JobStatus{}, sdk.HandleSDKError(resp, err)
+		}
+		return JobStatus{Status: response.Status}, nil
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	exportData, resp, err := apiClient.Beta.SPConfigAPI.GetSpConfigExport(context.TODO(), jobId).Execute()
+	if err != nil {
+		return nil, sdk.HandleSDKError(resp, err)
+	}
+	return exportData, nil
+}
+
+func DownloadExport(apiClient sailpoint.APIClient, jobId string, fileName string, folderPath string, opts WaitOptions) error {
+	exportData, err := WaitForExport(apiClient, jobId, opts)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Saving export data", "filePath", path.Join(folderPath, fileName+".json"))
+	return output.SaveJSONFile(exportData, fileName, folderPath)
+}
 
-	for {
-		response, _, err := apiClient.Beta.SPConfigAPI.GetSpConfigExportStatus(context.TODO(), jobId).Execute()
+// WaitForImport waits for an import job to end and returns its results. When
+// the job fails, the errors of its results are added to the JobFailedError.
+func WaitForImport(apiClient sailpoint.APIClient, jobId string, opts WaitOptions) (*sailpointbetasdk.SpConfigImportResults, error) {
+	_, err := WaitForJob("import", jobId, func(ctx context.Context) (JobStatus, error) {
+		response, resp, err := apiClient.Beta.SPConfigAPI.GetSpConfigImportStatus(ctx, jobId).Execute()
 		if err != nil {
-			return err
+			return JobStatus{}, sdk.HandleSDKError(resp, err)
 		}
-		if response.Status == "NOT_STARTED" || response.Status == "IN_PROGRESS" {
-			color.Yellow("Status: %s. checking again in 5 seconds", response.Status)
-			time.Sleep(5 * time.Second)
-		} else {
-			switch response.Status {
-			case "COMPLETE":
-				log.Info("Job Complete")
-				exportData, _, err := apiClient.Beta.SPConfigAPI.GetSpConfigExport(context.TODO(), jobId).Execute()
-				if err != nil {
-					return err
-				}
-				log.Info("Saving export data", "filePath", path.Join(folderPath, fileName+".json"))
-				err = output.SaveJSONFile(exportData, fileName, folderPath)
-				if err != nil {
-					return err
-				}
-			case "CANCELLED":
-				return fmt.Errorf("export task cancelled")
-			case "FAILED":
-				return fmt.Errorf("export task failed")
-			}
-			break
+		return JobStatus{Status: response.Status, Message: response.GetMessage()}, nil
+	}, opts)
+
+	var failed *JobFailedError
+	if errors.As(err, &failed) {
+		if importData, _, resultsErr := apiClient.Beta.SPConfigAPI.GetSpConfigImport(context.TODO(), jobId).Execute(); resultsErr == nil {
+			failed.Messages = append(failed.Messages, ImportErrors(*importData)...)
 		}
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	importData, resp, err := apiClient.Beta.SPConfigAPI.GetSpConfigImport(context.TODO(), jobId).Execute()
+	if err != nil {
+		return nil, sdk.HandleSDKError(resp, err)
+	}
+	return importData, nil
+}
+
+// DownloadImport waits for an import job, saves its results and prints their
+// summary. Imports that complete with object errors return an error too.
+func DownloadImport(apiClient sailpoint.APIClient, jobId string, fileName string, folderPath string, opts WaitOptions) error {
+	importData, err := WaitForImport(apiClient, jobId, opts)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Saving import data", "filePath", path.Join(folderPath, fileName+".json"))
+	if err := output.SaveJSONFile(importData, fileName, folderPath); err != nil {
+		return err
+	}
+
+	PrintImportResults(os.Stdout, *importData)
 
+	if errs := ImportErrors(*importData); len(errs) > 0 {
+		return fmt.Errorf("import job %s completed with %d errors", jobId, len(errs))
+	}
 	return nil
 }
 
-func DownloadImport(apiClient sailpoint.APIClient, jobId string, fileName string, folderPath string) error {
+// ImportErrors returns the error messages of the import results, by type
+func ImportErrors(results sailpointbetasdk.SpConfigImportResults) []string {
+	types := make([]string, 0, len(results.Results))
+	for objectType := range results.Results {
+		types = append(types, objectType)
+	}
+	sort.Strings(types)
 
-	for {
-		response, _, err := apiClient.Beta.SPConfigAPI.GetSpConfigImportStatus(context.TODO(), jobId).Execute()
-		if err != nil {
-			return err
+	var messages []string
+	for _, objectType := range types {
+		for _, message := range results.Results[objectType].Errors {
+			messages = append(messages, fmt.Sprintf("%s %s: %s", objectType, message.Key, message.Text))
+		}
+	}
+	return messages
+}
+
+// PrintImportResults writes, for each type, the number of imported objects
+// and messages followed by the messages, then the totals of the import
+func PrintImportResults(w io.Writer, results sailpointbetasdk.SpConfigImportResults) {
+	types := make([]string, 0, len(results.Results))
+	for objectType := range results.Results {
+		types = append(types, objectType)
+	}
+	sort.Strings(types)
+
+	var objects, infos, warnings, errs int
+	for _, objectType := range types {
+		result := results.Results[objectType]
+		objects, infos, warnings, errs = objects+len(result.ImportedObjects), infos+len(result.Infos), warnings+len(result.Warnings), errs+len(result.Errors)
+
+		fmt.Fprintf(w, "%s: %d objects, %d infos, %d warnings, %d errors\n", objectType, len(result.ImportedObjects), len(result.Infos), len(result.Warnings), len(result.Errors))
+		for _, message := range result.Errors {
+			fmt.Fprintln(w, color.RedString("  ERROR %s: %s%s", message.Key, message.Text, formatMessageDetails(message)))
 		}
-		if response.Status == "NOT_STARTED" || response.Status == "IN_PROGRESS" {
-			color.Yellow("Status: %s. checking again in 5 seconds", response.Status)
-			time.Sleep(5 * time.Second)
-		} else {
-			switch response.Status {
-			case "COMPLETE":
-				log.Info("Job Complete")
-				importData, _, err := apiClient.Beta.SPConfigAPI.GetSpConfigImport(context.TODO(), jobId).Execute()
-				if err != nil {
-					return err
-				}
-				log.Info("Saving import data", "filePath", path.Join(folderPath, fileName+".json"))
-				err = output.SaveJSONFile(importData, fileName, folderPath)
-				if err != nil {
-					return err
-				}
-			case "CANCELLED":
-				return fmt.Errorf("import task cancelled")
-			case "FAILED":
-				return fmt.Errorf("import task failed")
-			}
-			break
+		for _, message := range result.Warnings {
+			fmt.Fprintln(w, color.YellowString("  WARN  %s: %s%s", message.Key, message.Text, formatMessageDetails(message)))
+		}
+		for _, message := range result.Infos {
+			fmt.Fprintf(w, "  INFO  %s: %s%s\n", message.Key, message.Text, formatMessageDetails(message))
 		}
 	}
 
-	return nil
+	fmt.Fprintf(w, "Total: %d objects, %d infos, %d warnings, %d errors\n", objects, infos, warnings, errs)
+}
+
+// formatMessageDetails renders the details of a message as compact JSON
+func formatMessageDetails(message sailpointbetasdk.SpConfigMessage) string {
+	if len(message.Details) == 0 {
+		return ""
+	}
+	raw, err := json.Marshal(message.Details)
+	if err != nil {
+		return ""
+	}
+	return " " + string(raw)
 }
@@ -0,0 +1,286 @@
+package spconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	sailpointbetasdk "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+)
+
+const (
+	// SplitMetadataFile holds the export details of a split export
+	SplitMetadataFile = "export.json"
+	// SplitIdsFile holds the volatile fields stripped from the object files
+	SplitIdsFile = "ids.json"
+)
+
+// volatileFields are the object fields that change between tenants, or on
+// every save, without the configuration changing
+var volatileFields = []string{"id", "created", "modified"}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9 ._-]+`)
+
+// splitMetadata is the export level content of a split export
+type splitMetadata struct {
+	Version     *int32                          `json:"version,omitempty"`
+	Tenant      string                          `json:"tenant,omitempty"`
+	Description string                          `json:"description,omitempty"`
+	Options     *sailpointbetasdk.ExportOptions `json:"options,omitempty"`
+}
+
+// splitObject is the content of an object file
+type splitObject struct {
+	Version *int32                 `json:"version,omitempty"`
+	Self    splitSelf              `json:"self"`
+	Object  map[string]interface{} `json:"object"`
+}
+
+type splitSelf struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// ObjectFileName returns the file name of an object, made safe for every
+// file system
+func ObjectFileName(name string) string {
+	fileName := strings.Trim(unsafeFileChars.ReplaceAllString(name, "_"), " .")
+	if fileName == "" {
+		fileName = "_"
+	}
+	return fileName
+}
+
+// MarshalNormalized encodes v as indented JSON with sorted keys, without
+// escaping HTML characters, and with a trailing newline so files diff cleanly
+func MarshalNormalized(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteSplitExport writes an export as one normalized file per object under
+// <dir>/<type>/<name>.json. The id, created and modified fields are moved to
+// ids.json, by file path, so object files only change along with their
+// configuration. The folders of the exported types are emptied first, so
+// objects removed from the tenant disappear from dir.
+func WriteSplitExport(export sailpointbetasdk.SpConfigExportResults, dir string) (int, error) {
+	metadata := splitMetadata{Version: export.Version, Tenant: export.GetTenant(), Description: export.GetDescription(), Options: export.Options}
+
+	objects := append([]sailpointbetasdk.ConfigObject{}, export.Objects...)
+	sort.SliceStable(objects, func(i, j int) bool {
+		a, b := objects[i].GetSelf(), objects[j].GetSelf()
+		if a.GetType() != b.GetType() {
+			return a.GetType() < b.GetType()
+		}
+		if a.GetName() != b.GetName() {
+			return a.GetName() < b.GetName()
+		}
+		return a.GetId() < b.GetId()
+	})
+
+	for _, object := range objects {
+		self := object.GetSelf()
+		if self.GetType() == "" || ObjectFileName(self.GetType()) != self.GetType() {
+			return 0, fmt.Errorf("export object %q has an invalid type %q", self.GetName(), self.GetType())
+		}
+		typeDir := filepath.Join(dir, self.GetType())
+		if err := os.RemoveAll(typeDir); err != nil {
+			return 0, err
+		}
+	}
+
+	ids := map[string]map[string]interface{}{}
+	used := map[string]bool{}
+
+	for _, object := range objects {
+		self := object.GetSelf()
+		objectType, name := self.GetType(), self.GetName()
+
+		// Objects sharing a file name get a numbered suffix, in a stable order
+		fileName := ObjectFileName(name)
+		relPath := filepath.Join(objectType, fileName+".json")
+		for i := 2; used[strings.ToLower(relPath)]; i++ {
+			relPath = filepath.Join(objectType, fmt.Sprintf("%s-%d.json", fileName, i))
+		}
+		used[strings.ToLower(relPath)] = true
+
+		content := map[string]interface{}{}
+		volatile := map[string]interface{}{}
+		for key, value := range object.Object {
+			content[key] = value
+		}
+		for _, field := range volatileFields {
+			if value, ok := content[field]; ok {
+				volatile[field] = value
+				delete(content, field)
+			}
+		}
+		if self.GetId() != "" {
+			volatile["selfId"] = self.GetId()
+		}
+		if len(volatile) > 0 {
+			ids[filepath.ToSlash(relPath)] = volatile
+		}
+
+		data, err := MarshalNormalized(splitObject{Version: object.Version, Self: splitSelf{Type: objectType, Name: name}, Object: content})
+		if err != nil {
+			return 0, err
+		}
+		if err := writeSplitFile(filepath.Join(dir, relPath), data); err != nil {
+			return 0, err
+		}
+	}
+
+	data, err := MarshalNormalized(metadata)
+	if err != nil {
+		return 0, err
+	}
+	if err := writeSplitFile(filepath.Join(dir, SplitMetadataFile), data); err != nil {
+		return 0, err
+	}
+
+	data, err = MarshalNormalized(ids)
+	if err != nil {
+		return 0, err
+	}
+	if err := writeSplitFile(filepath.Join(dir, SplitIdsFile), data); err != nil {
+		return 0, err
+	}
+
+	return len(objects), nil
+}
+
+func writeSplitFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// readJSONFile decodes a JSON file, keeping numbers as written
+func readJSONFile(path string, v interface{}) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid JSON in %s: %v", path, err)
+	}
+	return nil
+}
+
+// ReadSplitExport reassembles the export payload of a folder written by
+// WriteSplitExport. The fields stripped from the object files are restored
+// from ids.json when it has them, so references between the objects resolve.
+func ReadSplitExport(dir string) (sailpointbetasdk.SpConfigExportResults, error) {
+	var export sailpointbetasdk.SpConfigExportResults
+
+	var metadata splitMetadata
+	if err := readJSONFile(filepath.Join(dir, SplitMetadataFile), &metadata); err != nil && !os.IsNotExist(err) {
+		return export, err
+	}
+
+	ids := map[string]map[string]interface{}{}
+	if err := readJSONFile(filepath.Join(dir, SplitIdsFile), &ids); err != nil && !os.IsNotExist(err) {
+		return export, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	if err != nil {
+		return export, err
+	}
+	sort.Strings(files)
+
+	objects := []sailpointbetasdk.ConfigObject{}
+	for _, file := range files {
+		var object splitObject
+		if err := readJSONFile(file, &object); err != nil {
+			return export, err
+		}
+		if object.Self.Type == "" || object.Self.Name == "" {
+			return export, fmt.Errorf("%s is missing self.type or self.name", file)
+		}
+		if object.Object == nil {
+			object.Object = map[string]interface{}{}
+		}
+
+		self := sailpointbetasdk.SelfImportExportDto{}
+		self.SetType(object.Self.Type)
+		self.SetName(object.Self.Name)
+
+		relPath, err := filepath.Rel(dir, file)
+		if err != nil {
+			return export, err
+		}
+		if volatile, ok := ids[filepath.ToSlash(relPath)]; ok {
+			for key, value := range volatile {
+				if key == "selfId" {
+					if id, ok := value.(string); ok {
+						self.SetId(id)
+					}
+					continue
+				}
+				if _, set := object.Object[key]; !set {
+					object.Object[key] = value
+				}
+			}
+		}
+
+		objects = append(objects, sailpointbetasdk.ConfigObject{Version: object.Version, Self: &self, Object: object.Object})
+	}
+
+	if len(objects) == 0 {
+		return export, fmt.Errorf("no object files found under %s", dir)
+	}
+
+	export.Version = metadata.Version
+	if metadata.Tenant != "" {
+		export.SetTenant(metadata.Tenant)
+	}
+	if metadata.Description != "" {
+		export.SetDescription(metadata.Description)
+	}
+	export.Options = metadata.Options
+	export.Objects = objects
+
+	return export, nil
+}
+
+// WriteImportPayload writes an export payload to a temporary file, ready to
+// be sent to the import API, and returns the open file
+func WriteImportPayload(export sailpointbetasdk.SpConfigExportResults) (*os.File, error) {
+	data, err := json.Marshal(export)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.CreateTemp("", "spconfig-import-*.json")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+	if _, err := file.Seek(0, 0); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+
+	return file, nil
+}
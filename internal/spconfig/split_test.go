@@ -0,0 +1,85 @@
+package spconfig
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sailpointbetasdk "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+)
+
+func testObject(objectType string, id string, name string, object map[string]interface{}) sailpointbetasdk.ConfigObject {
+	self := sailpointbetasdk.SelfImportExportDto{}
+	self.SetType(objectType)
+	self.SetId(id)
+	self.SetName(name)
+	return sailpointbetasdk.ConfigObject{Self: &self, Object: object}
+}
+
+func TestSplitExportRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	export := sailpointbetasdk.SpConfigExportResults{}
+	export.SetTenant("acme")
+	export.Objects = []sailpointbetasdk.ConfigObject{
+		testObject("TRANSFORM", "2", "Lower/Case", map[string]interface{}{"id": "2", "name": "Lower/Case", "type": "lower", "created": "2024-01-01T00:00:00Z"}),
+		testObject("TRANSFORM", "1", "Lower:Case", map[string]interface{}{"id": "1", "name": "Lower:Case", "type": "lower"}),
+		testObject("ROLE", "3", "Engineering", map[string]interface{}{"name": "Engineering", "description": "R&D <team>"}),
+	}
+
+	// A stale object from an earlier export is removed
+	if err := writeSplitFile(filepath.Join(dir, "TRANSFORM", "Deleted.json"), []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := WriteSplitExport(export, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 objects, got %d", count)
+	}
+
+	for _, file := range []string{"ROLE/Engineering.json", "TRANSFORM/Lower_Case.json", "TRANSFORM/Lower_Case-2.json", SplitMetadataFile, SplitIdsFile} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Errorf("expected %s to be written: %v", file, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "TRANSFORM", "Deleted.json")); !os.IsNotExist(err) {
+		t.Error("expected the stale object file to be removed")
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, "ROLE", "Engineering.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(raw)
+	if strings.Contains(content, `"id"`) || !strings.Contains(content, "R&D <team>") || !strings.HasSuffix(content, "}\n") {
+		t.Errorf("unexpected object file:\n%s", content)
+	}
+
+	payload, err := ReadSplitExport(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload.GetTenant() != "acme" || len(payload.Objects) != 3 {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+
+	byName := map[string]sailpointbetasdk.ConfigObject{}
+	for _, object := range payload.Objects {
+		self := object.GetSelf()
+		byName[self.GetName()] = object
+	}
+	transform := byName["Lower/Case"]
+	self := transform.GetSelf()
+	if self.GetId() != "2" || transform.Object["id"] != "2" || transform.Object["created"] != "2024-01-01T00:00:00Z" {
+		t.Errorf("expected the volatile fields to be restored, got %+v %v", self, transform.Object)
+	}
+
+	if _, err := json.Marshal(payload); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,239 @@
+package spconfig
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"golang.org/x/term"
+)
+
+// Default polling intervals of WaitForJob
+const (
+	DefaultPollInterval    = 2 * time.Second
+	DefaultMaxPollInterval = 30 * time.Second
+)
+
+// WaitOptions controls how WaitForJob waits for a job
+type WaitOptions struct {
+	// Timeout stops waiting after this long, 0 waits until the job ends
+	Timeout time.Duration
+	// Interval is the delay before the second poll, doubled after every poll
+	// up to MaxInterval
+	Interval    time.Duration
+	MaxInterval time.Duration
+}
+
+func (o WaitOptions) withDefaults() WaitOptions {
+	if o.Interval <= 0 {
+		o.Interval = DefaultPollInterval
+	}
+	if o.MaxInterval < o.Interval {
+		o.MaxInterval = DefaultMaxPollInterval
+		if o.MaxInterval < o.Interval {
+			o.MaxInterval = o.Interval
+		}
+	}
+	return o
+}
+
+// JobStatus is the state of a job when it was polled
+type JobStatus struct {
+	Status  string
+	Message string
+}
+
+// Running tells if the job has not ended yet
+func (s JobStatus) Running() bool {
+	return s.Status == "NOT_STARTED" || s.Status == "IN_PROGRESS"
+}
+
+// JobPoller returns the current status of a job
+type JobPoller func(ctx context.Context) (JobStatus, error)
+
+// JobFailedError is returned for jobs that ended without completing
+type JobFailedError struct {
+	JobType  string
+	JobId    string
+	Status   string
+	Messages []string
+}
+
+func (e *JobFailedError) Error() string {
+	message := fmt.Sprintf("%s job %s ended with status %s", e.JobType, e.JobId, e.Status)
+	if len(e.Messages) > 0 {
+		message += ": " + strings.Join(e.Messages, "; ")
+	}
+	return message
+}
+
+// JobStoppedError is returned when waiting stops before the job ends, the job
+// itself keeps running
+type JobStoppedError struct {
+	JobType string
+	JobId   string
+	Reason  string
+}
+
+func (e *JobStoppedError) Error() string {
+	return fmt.Sprintf("%s, %s job %s is still running, download its results later with: sail spconfig download --%s %s", e.Reason, e.JobType, e.JobId, e.JobType, e.JobId)
+}
+
+// WaitForJob polls a job until it ends, backing off exponentially between
+// polls. A spinner shows the job status on terminals. On Ctrl-C the user can
+// stop waiting and leave the job running, or keep waiting. It returns a
+// JobFailedError when the job does not complete, and a JobStoppedError when
+// waiting stops first.
+func WaitForJob(jobType string, jobId string, poll JobPoller, opts WaitOptions) (JobStatus, error) {
+	opts = opts.withDefaults()
+
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var deadline <-chan time.Time
+	if opts.Timeout > 0 {
+		timer := time.NewTimer(opts.Timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	spin := newSpinner(os.Stderr)
+	defer spin.stop()
+
+	start := time.Now()
+	interval := opts.Interval
+	for {
+		status, err := poll(ctx)
+		if err != nil {
+			return status, err
+		}
+		if !status.Running() {
+			spin.clear()
+			if status.Status == "COMPLETE" {
+				log.Info("Job complete", "type", jobType, "JobID", jobId, "elapsed", time.Since(start).Round(time.Second))
+				return status, nil
+			}
+			failed := &JobFailedError{JobType: jobType, JobId: jobId, Status: status.Status}
+			if status.Message != "" {
+				failed.Messages = append(failed.Messages, status.Message)
+			}
+			return status, failed
+		}
+
+		spin.status(fmt.Sprintf("%s job %s: %s", jobType, jobId, status.Status), interval)
+
+		next := time.NewTimer(interval)
+		for waiting := true; waiting; {
+			select {
+			case <-next.C:
+				waiting = false
+			case <-spin.ticks():
+				spin.draw(time.Since(start))
+			case <-deadline:
+				next.Stop()
+				return status, &JobStoppedError{JobType: jobType, JobId: jobId, Reason: "timed out after " + opts.Timeout.String()}
+			case <-interrupts:
+				spin.clear()
+				if leaveRunning(interrupts, jobType, jobId) {
+					next.Stop()
+					return status, &JobStoppedError{JobType: jobType, JobId: jobId, Reason: "interrupted"}
+				}
+			}
+		}
+
+		interval *= 2
+		if interval > opts.MaxInterval {
+			interval = opts.MaxInterval
+		}
+	}
+}
+
+// leaveRunning asks whether to stop waiting for a job. Without a terminal, or
+// on a second Ctrl-C, it stops.
+func leaveRunning(interrupts <-chan os.Signal, jobType string, jobId string) bool {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return true
+	}
+
+	fmt.Fprintf(os.Stderr, "Stop waiting and leave %s job %s running in Identity Security Cloud? [y/N] ", jobType, jobId)
+
+	answers := make(chan string, 1)
+	go func() {
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		answers <- strings.ToLower(strings.TrimSpace(answer))
+	}()
+
+	select {
+	case answer := <-answers:
+		return answer == "y" || answer == "yes"
+	case <-interrupts:
+		fmt.Fprintln(os.Stderr)
+		return true
+	}
+}
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// spinner shows the status of a job on a terminal, and logs status changes
+// otherwise
+type spinner struct {
+	w        io.Writer
+	ticker   *time.Ticker
+	frame    int
+	text     string
+	drawn    bool
+	previous string
+}
+
+func newSpinner(w *os.File) *spinner {
+	s := &spinner{w: w}
+	if term.IsTerminal(int(w.Fd())) {
+		s.ticker = time.NewTicker(120 * time.Millisecond)
+	}
+	return s
+}
+
+func (s *spinner) ticks() <-chan time.Time {
+	if s.ticker == nil {
+		return nil
+	}
+	return s.ticker.C
+}
+
+func (s *spinner) status(text string, next time.Duration) {
+	s.text = text
+	if s.ticker == nil && text != s.previous {
+		log.Info(text, "next check in", next)
+	}
+	s.previous = text
+}
+
+func (s *spinner) draw(elapsed time.Duration) {
+	s.frame = (s.frame + 1) % len(spinnerFrames)
+	fmt.Fprintf(s.w, "\r%s %s (%s)\033[K", spinnerFrames[s.frame], s.text, elapsed.Round(time.Second))
+	s.drawn = true
+}
+
+func (s *spinner) stop() {
+	if s.ticker != nil {
+		s.ticker.Stop()
+	}
+	s.clear()
+}
+
+func (s *spinner) clear() {
+	if s.drawn {
+		fmt.Fprint(s.w, "\r\033[K")
+		s.drawn = false
+	}
+}
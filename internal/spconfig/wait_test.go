@@ -0,0 +1,45 @@
+package spconfig
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitForJob(t *testing.T) {
+	opts := WaitOptions{Interval: time.Millisecond, MaxInterval: 4 * time.Millisecond}
+
+	var polls []time.Time
+	statuses := []string{"NOT_STARTED", "IN_PROGRESS", "IN_PROGRESS", "IN_PROGRESS", "COMPLETE"}
+	status, err := WaitForJob("export", "job-1", func(ctx context.Context) (JobStatus, error) {
+		polls = append(polls, time.Now())
+		return JobStatus{Status: statuses[len(polls)-1]}, nil
+	}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != "COMPLETE" || len(polls) != len(statuses) {
+		t.Errorf("expected %d polls ending with COMPLETE, got %d ending with %s", len(statuses), len(polls), status.Status)
+	}
+	if gap := polls[4].Sub(polls[3]); gap < 4*time.Millisecond {
+		t.Errorf("expected the polls to back off to 4ms, got %s", gap)
+	}
+
+	_, err = WaitForJob("import", "job-2", func(ctx context.Context) (JobStatus, error) {
+		return JobStatus{Status: "FAILED", Message: "invalid payload"}, nil
+	}, opts)
+	var failed *JobFailedError
+	if !errors.As(err, &failed) || failed.Status != "FAILED" || len(failed.Messages) != 1 || failed.Messages[0] != "invalid payload" {
+		t.Errorf("expected a failed job error with its message, got %v", err)
+	}
+
+	opts.Timeout = 10 * time.Millisecond
+	_, err = WaitForJob("import", "job-3", func(ctx context.Context) (JobStatus, error) {
+		return JobStatus{Status: "IN_PROGRESS"}, nil
+	}, opts)
+	var stopped *JobStoppedError
+	if !errors.As(err, &stopped) || stopped.JobId != "job-3" {
+		t.Errorf("expected a stopped job error after the timeout, got %v", err)
+	}
+}
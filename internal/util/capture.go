@@ -0,0 +1,34 @@
+package util
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// CaptureStdout runs fn with os.Stdout redirected to a pipe and returns what
+// was written to it. The pipe is drained while fn runs, so output larger than
+// the pipe buffer does not block.
+func CaptureStdout(fn func() error) (string, error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	captured := new(bytes.Buffer)
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(captured, reader)
+		close(done)
+	}()
+
+	realStdout := os.Stdout
+	os.Stdout = writer
+	err = fn()
+	os.Stdout = realStdout
+	writer.Close()
+	<-done
+
+	return captured.String(), err
+}
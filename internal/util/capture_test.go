@@ -0,0 +1,30 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCaptureStdout(t *testing.T) {
+	// Larger than the pipe buffer, which would block if the pipe was only read
+	// once fn returned
+	large := strings.Repeat("x", 1<<20)
+	realStdout := os.Stdout
+
+	out, err := CaptureStdout(func() error {
+		fmt.Fprint(os.Stdout, large)
+		return errors.New("failed")
+	})
+	if err == nil || err.Error() != "failed" {
+		t.Errorf("expected the error of fn, got %v", err)
+	}
+	if out != large {
+		t.Errorf("expected %d bytes captured, got %d", len(large), len(out))
+	}
+	if os.Stdout != realStdout {
+		t.Error("expected stdout to be restored")
+	}
+}
@@ -0,0 +1,29 @@
+package util
+
+import "regexp"
+
+var (
+	accessTokenPattern = regexp.MustCompile(`(ey[A-Za-z0-9-_=]+).(ey[A-Za-z0-9-_=]+).[A-Za-z0-9-_.+=]+`)
+	originPattern      = regexp.MustCompile(`\{[\s]+"name": "origin",[\s]+"value":[\s]+"[A-Za-z0-9:\/\-.]+"[\s]+\},`)
+	// secretPattern matches the value of secret looking keys in YAML, JSON and
+	// key=value formats
+	secretPattern = regexp.MustCompile(`(?i)("?[A-Za-z0-9_.-]*(password|passwd|secret|apikey|api_key|token|privatekey|private_key)[A-Za-z0-9_.-]*"?\s*[:=]\s*)("[^"]*"|'[^']*'|[^\s,}&]+)`)
+)
+
+// RedactTokens replaces access tokens and HAR origin headers with REDACTED
+func RedactTokens(s string) string {
+	return originPattern.ReplaceAllString(accessTokenPattern.ReplaceAllString(s, "REDACTED"), `{"name":"origin", "value":"REDACTED"},`)
+}
+
+// RedactSecrets replaces the values of password, secret, API key and token
+// settings with REDACTED, keeping the setting names and any quoting
+func RedactSecrets(s string) string {
+	return secretPattern.ReplaceAllStringFunc(s, func(match string) string {
+		groups := secretPattern.FindStringSubmatch(match)
+		value := groups[3]
+		if quote := value[0]; quote == '"' || quote == '\'' {
+			return groups[1] + string(quote) + "REDACTED" + string(quote)
+		}
+		return groups[1] + "REDACTED"
+	})
+}
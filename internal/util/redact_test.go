@@ -0,0 +1,22 @@
+package util
+
+import "testing"
+
+func TestRedactSecrets(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"password: S@ilp0int", "password: REDACTED"},
+		{"keyPassword: 'abc def'", "keyPassword: 'REDACTED'"},
+		{`{"apiKey": "abc", "user": "x"}`, `{"apiKey": "REDACTED", "user": "x"}`},
+		{"client_secret=abc&other=1", "client_secret=REDACTED&other=1"},
+		{"host: proxy.example.com", "host: proxy.example.com"},
+	}
+
+	for _, tt := range tests {
+		if got := RedactSecrets(tt.in); got != tt.want {
+			t.Errorf("RedactSecrets(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+// Copyright (c) 2025, SailPoint Technologies, Inc. All rights reserved.
+package va
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/pkg/sftp"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/util"
+	"github.com/vbauerster/mpb/v8"
+	"github.com/vbauerster/mpb/v8/decor"
+	"golang.org/x/crypto/ssh"
+)
+
+// CollectProfile names a set of remote files, given as globs, to collect
+// from a VA
+type CollectProfile struct {
+	Name        string
+	Description string
+	Files       []string
+	// MaxFileSize caps the bytes collected from each file, 0 uses the default
+	MaxFileSize int64
+}
+
+var logFiles = []string{"/home/sailpoint/log/ccg.log", "/home/sailpoint/log/charon.log"}
+var configFiles = []string{"/home/sailpoint/proxy.yaml", "/etc/systemd/network/static.network", "/etc/resolv.conf"}
+
+// BuiltinProfiles are the collection profiles available without configuration
+var BuiltinProfiles = map[string]CollectProfile{
+	"default": {
+		Name:        "default",
+		Description: "Current CCG and charon logs with the network and proxy config",
+		Files:       append(append([]string{}, logFiles...), configFiles...),
+	},
+	"logs": {
+		Name:        "logs",
+		Description: "CCG and charon logs, including rotated logs",
+		Files:       []string{"/home/sailpoint/log/ccg.log*", "/home/sailpoint/log/charon.log*"},
+	},
+	"config": {
+		Name:        "config",
+		Description: "Network and proxy config",
+		Files:       configFiles,
+	},
+	"full": {
+		Name:        "full",
+		Description: "Every VA log, including rotated logs, with the network and proxy config",
+		Files:       append([]string{"/home/sailpoint/log/*"}, configFiles...),
+	},
+}
+
+// BundleEntry records what was collected from a remote file
+type BundleEntry struct {
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	Truncated bool   `json:"truncated,omitempty"`
+	Skipped   string `json:"skipped,omitempty"`
+}
+
+type bundleManifest struct {
+	VA          string        `json:"va"`
+	Profile     string        `json:"profile"`
+	CollectedAt time.Time     `json:"collectedAt"`
+	MaxFileSize int64         `json:"maxFileSize"`
+	Files       []BundleEntry `json:"files"`
+}
+
+// CollectVABundle collects the files of profile from the VA at endpoint into
+// a single redacted tar.gz under output and returns its path. Files are
+// compressed on the VA before transfer, and files over the profile size cap
+// are truncated to their last MaxFileSize bytes.
+func CollectVABundle(endpoint string, opts SSHOptions, output string, profile CollectProfile, p *mpb.Progress) (string, error) {
+	log.Info("Starting File Collection", "VA", endpoint, "profile", profile.Name)
+
+	client, err := Dial(endpoint, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to dial: %v", err)
+	}
+	defer client.Close()
+
+	sftpClient, err := sftp.NewClient(client)
+	if err != nil {
+		return "", fmt.Errorf("failed to create SFTP client: %v", err)
+	}
+	defer sftpClient.Close()
+
+	files, err := expandRemoteGlobs(sftpClient, profile.Files)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("no files on the VA match profile %s", profile.Name)
+	}
+
+	out, err := RunClientCmd(client, "mktemp -d /tmp/sail-collect.XXXXXX")
+	if err != nil {
+		return "", fmt.Errorf("failed to create a remote staging folder: %v", err)
+	}
+	stagingDir := strings.TrimSpace(out)
+	defer func() {
+		_, _ = RunClientCmd(client, "rm -rf "+ShellQuote(stagingDir))
+	}()
+
+	if err := os.MkdirAll(output, 0700); err != nil {
+		return "", fmt.Errorf("failed to create output folder: %v", err)
+	}
+
+	bundleName := fmt.Sprintf("%s-%s.tar.gz", strings.NewReplacer(":", "_", "/", "_").Replace(endpoint), time.Now().Format("20060102-150405"))
+	bundlePath := path.Join(output, bundleName)
+
+	bundleFile, err := os.Create(bundlePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create bundle: %v", err)
+	}
+	defer bundleFile.Close()
+
+	gzWriter := gzip.NewWriter(bundleFile)
+	tarWriter := tar.NewWriter(gzWriter)
+
+	bar := p.AddBar(int64(len(files)),
+		mpb.BarFillerClearOnComplete(),
+		mpb.PrependDecorators(
+			decor.Name(endpoint, decor.WCSyncWidthR),
+			decor.Name(" : ", decor.WCSyncWidthR),
+		),
+		mpb.AppendDecorators(
+			decor.OnComplete(decor.CountersNoUnit("%d / %d files", decor.WCSyncWidth), "Complete")),
+	)
+
+	root := strings.TrimSuffix(bundleName, ".tar.gz")
+	manifest := bundleManifest{VA: endpoint, Profile: profile.Name, CollectedAt: time.Now().UTC(), MaxFileSize: profile.MaxFileSize, Files: []BundleEntry{}}
+
+	for i, file := range files {
+		entry, err := collectBundleFile(client, sftpClient, tarWriter, root, stagingDir, i, file, profile.MaxFileSize)
+		if err != nil {
+			log.Warn("Skipping file", "file", file.path, "VA", endpoint)
+			log.Debug("Error collecting file", "file", file.path, "VA", endpoint, "err", err)
+			entry = BundleEntry{Path: file.path, Size: file.size, Skipped: err.Error()}
+		}
+		manifest.Files = append(manifest.Files, entry)
+		bar.Increment()
+	}
+
+	raw, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := writeTarFile(tarWriter, path.Join(root, "manifest.json"), raw, manifest.CollectedAt); err != nil {
+		return "", err
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return "", err
+	}
+	if err := gzWriter.Close(); err != nil {
+		return "", err
+	}
+
+	return bundlePath, bundleFile.Close()
+}
+
+type remoteFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// expandRemoteGlobs returns the regular files matching the globs, in order
+// and without duplicates
+func expandRemoteGlobs(sftpClient *sftp.Client, globs []string) ([]remoteFile, error) {
+	var files []remoteFile
+	seen := map[string]bool{}
+
+	for _, glob := range globs {
+		matches, err := sftpClient.Glob(glob)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file pattern %q: %v", glob, err)
+		}
+		for _, match := range matches {
+			if seen[match] {
+				continue
+			}
+			seen[match] = true
+
+			info, err := sftpClient.Stat(match)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			files = append(files, remoteFile{path: match, size: info.Size(), modTime: info.ModTime()})
+		}
+	}
+
+	return files, nil
+}
+
+// collectBundleFile compresses a remote file into the staging folder,
+// transfers it and writes it redacted into the bundle. Rotated logs that are
+// already gzipped are transferred as they are.
+func collectBundleFile(client *ssh.Client, sftpClient *sftp.Client, tarWriter *tar.Writer, root string, stagingDir string, index int, file remoteFile, maxSize int64) (BundleEntry, error) {
+	entry := BundleEntry{Path: file.path, Size: file.size}
+	oversized := maxSize > 0 && file.size > maxSize
+
+	staged := file.path
+	if strings.HasSuffix(file.path, ".gz") {
+		if oversized {
+			return entry, fmt.Errorf("compressed file is larger than the %d byte limit", maxSize)
+		}
+	} else {
+		staged = path.Join(stagingDir, fmt.Sprintf("%d.gz", index))
+		command := fmt.Sprintf("gzip -c %s > %s", ShellQuote(file.path), ShellQuote(staged))
+		if oversized {
+			command = fmt.Sprintf("tail -c %d %s | gzip -c > %s", maxSize, ShellQuote(file.path), ShellQuote(staged))
+			entry.Truncated = true
+		}
+		if out, err := RunClientCmd(client, command); err != nil {
+			return entry, fmt.Errorf("failed to compress remote file: %v %s", err, strings.TrimSpace(out))
+		}
+	}
+
+	srcFile, err := sftpClient.Open(staged)
+	if err != nil {
+		return entry, fmt.Errorf("failed to open remote file: %v", err)
+	}
+	defer srcFile.Close()
+
+	gzReader, err := gzip.NewReader(srcFile)
+	if err != nil {
+		return entry, fmt.Errorf("failed to decompress remote file: %v", err)
+	}
+	defer gzReader.Close()
+
+	// Redact into a local temporary file first, the tar header needs the size
+	tmpFile, err := os.CreateTemp("", "sail-collect-*")
+	if err != nil {
+		return entry, err
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	if err := redactStream(gzReader, tmpFile); err != nil {
+		return entry, fmt.Errorf("failed to copy file contents: %v", err)
+	}
+
+	info, err := tmpFile.Stat()
+	if err != nil {
+		return entry, err
+	}
+	if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
+		return entry, err
+	}
+
+	name := path.Join(root, strings.TrimSuffix(strings.TrimPrefix(file.path, "/"), ".gz"))
+	err = tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: info.Size(), ModTime: file.modTime})
+	if err != nil {
+		return entry, err
+	}
+	if _, err := io.Copy(tarWriter, tmpFile); err != nil {
+		return entry, err
+	}
+
+	return entry, nil
+}
+
+// redactStream copies r to w line by line, redacting tokens and secrets
+func redactStream(r io.Reader, w io.Writer) error {
+	reader := bufio.NewReaderSize(r, 1024*1024)
+	writer := bufio.NewWriter(w)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			if _, writeErr := writer.WriteString(util.RedactSecrets(util.RedactTokens(line))); writeErr != nil {
+				return writeErr
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+func writeTarFile(tarWriter *tar.Writer, name string, data []byte, modTime time.Time) error {
+	err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: modTime})
+	if err != nil {
+		return err
+	}
+	_, err = tarWriter.Write(data)
+	return err
+}
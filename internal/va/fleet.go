@@ -0,0 +1,102 @@
+// Copyright (c) 2025, SailPoint Technologies, Inc. All rights reserved.
+package va
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HostResult is the outcome of running an operation against a single VA
+type HostResult struct {
+	Host     Host
+	Output   string
+	Err      error
+	Duration time.Duration
+}
+
+// RunFleet runs fn against every host with at most concurrency operations in
+// flight. Results are returned in the order of hosts.
+func RunFleet(hosts []Host, concurrency int, fn func(host Host) (string, error)) []HostResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]HostResult, len(hosts))
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, host Host) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			start := time.Now()
+			out, err := fn(host)
+			results[i] = HostResult{Host: host, Output: out, Err: err, Duration: time.Since(start)}
+		}(i, host)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// RunRolling runs fn against the hosts of each cluster one at a time, with
+// at most concurrency clusters in flight. Hosts without a cluster run on
+// their own. After a failure the remaining hosts of that cluster are skipped
+// so the cluster keeps its healthy nodes. Results are returned in the order of
+// hosts.
+func RunRolling(hosts []Host, concurrency int, fn func(host Host) (string, error)) []HostResult {
+	var groups [][]int
+	clusterGroup := map[string]int{}
+	for i, host := range hosts {
+		if host.Cluster == "" {
+			groups = append(groups, []int{i})
+			continue
+		}
+		g, ok := clusterGroup[host.Cluster]
+		if !ok {
+			g = len(groups)
+			clusterGroup[host.Cluster] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]HostResult, len(hosts))
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for _, group := range groups {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(group []int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			var failed string
+			for _, i := range group {
+				if failed != "" {
+					results[i] = HostResult{Host: hosts[i], Err: fmt.Errorf("skipped after a failure on %s", failed)}
+					continue
+				}
+
+				start := time.Now()
+				out, err := fn(hosts[i])
+				results[i] = HostResult{Host: hosts[i], Output: out, Err: err, Duration: time.Since(start)}
+				if err != nil {
+					failed = hosts[i].Address
+				}
+			}
+		}(group)
+	}
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,53 @@
+package va
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestRunRolling(t *testing.T) {
+	hosts := []Host{
+		{Address: "a1", Cluster: "a"},
+		{Address: "b1", Cluster: "b"},
+		{Address: "a2", Cluster: "a"},
+		{Address: "a3", Cluster: "a"},
+		{Address: "solo"},
+	}
+
+	var lock sync.Mutex
+	running := map[string]bool{}
+
+	results := RunRolling(hosts, 3, func(host Host) (string, error) {
+		lock.Lock()
+		if running[host.Cluster] && host.Cluster != "" {
+			t.Errorf("two hosts of cluster %s ran at the same time", host.Cluster)
+		}
+		running[host.Cluster] = true
+		lock.Unlock()
+
+		defer func() {
+			lock.Lock()
+			running[host.Cluster] = false
+			lock.Unlock()
+		}()
+
+		if host.Address == "a2" {
+			return "", errors.New("update failed")
+		}
+		return "done", nil
+	})
+
+	if len(results) != len(hosts) {
+		t.Fatalf("expected %d results, got %d", len(hosts), len(results))
+	}
+	for i, want := range []string{"", "", "update failed", "skipped after a failure on a2", ""} {
+		got := ""
+		if results[i].Err != nil {
+			got = results[i].Err.Error()
+		}
+		if results[i].Host.Address != hosts[i].Address || got != want {
+			t.Errorf("result %d: expected host %s error %q, got host %s error %q", i, hosts[i].Address, want, results[i].Host.Address, got)
+		}
+	}
+}
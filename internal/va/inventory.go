@@ -0,0 +1,195 @@
+// Copyright (c) 2025, SailPoint Technologies, Inc. All rights reserved.
+package va
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v2"
+)
+
+const inventoryFileName = "va-inventory.yaml"
+
+// Inventory maps VA clusters to their appliances. Connection settings set on a
+// cluster apply to each of its appliances unless the appliance overrides them.
+//
+//	clusters:
+//	  prod:
+//	    identityFile: ~/.ssh/va_ed25519
+//	    tags: [production]
+//	    appliances:
+//	      - address: 10.10.10.25
+//	        tags: [east]
+//	      - address: 10.10.10.26
+//	        passwordEnv: VA_PROD_26_PASSWORD
+type Inventory struct {
+	Clusters map[string]InventoryCluster `yaml:"clusters"`
+}
+
+// InventoryAuth holds the connection settings of a cluster or appliance
+type InventoryAuth struct {
+	User         string `yaml:"user,omitempty"`
+	Port         int    `yaml:"port,omitempty"`
+	IdentityFile string `yaml:"identityFile,omitempty"`
+	Agent        bool   `yaml:"agent,omitempty"`
+	// PasswordEnv names an environment variable holding the VA password
+	PasswordEnv string `yaml:"passwordEnv,omitempty"`
+}
+
+type InventoryCluster struct {
+	InventoryAuth `yaml:",inline"`
+	// ID is the managed cluster ID in Identity Security Cloud
+	ID         string               `yaml:"id,omitempty"`
+	Tags       []string             `yaml:"tags,omitempty"`
+	Appliances []InventoryAppliance `yaml:"appliances"`
+}
+
+type InventoryAppliance struct {
+	InventoryAuth `yaml:",inline"`
+	Address       string   `yaml:"address"`
+	Tags          []string `yaml:"tags,omitempty"`
+}
+
+// Host is an appliance selected from the inventory along with its resolved
+// connection settings
+type Host struct {
+	Address string
+	Cluster string
+	// ClusterID is the managed cluster ID of the host, when known
+	ClusterID string
+	Tags      []string
+	Options   SSHOptions
+}
+
+// DefaultInventoryPath returns ~/.sailpoint/va-inventory.yaml
+func DefaultInventoryPath() (string, error) {
+	userHome, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine user home directory: %w", err)
+	}
+	return path.Join(userHome, ".sailpoint", inventoryFileName), nil
+}
+
+// LoadInventory reads the inventory at inventoryPath, or the default
+// inventory when inventoryPath is empty
+func LoadInventory(inventoryPath string) (*Inventory, error) {
+	if inventoryPath == "" {
+		var err error
+		inventoryPath, err = DefaultInventoryPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	raw, err := os.ReadFile(inventoryPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read VA inventory: %w", err)
+	}
+
+	var inventory Inventory
+	err = yaml.Unmarshal(raw, &inventory)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse VA inventory %s: %w", inventoryPath, err)
+	}
+
+	return &inventory, nil
+}
+
+// Select returns the appliances of the given clusters that carry every one of
+// tags. An empty cluster list selects all clusters. Passwords are read from
+// the PasswordEnv variables; hosts still needing a password are returned with
+// an empty one.
+func (inv *Inventory) Select(clusters []string, tags []string) ([]Host, error) {
+	for _, name := range clusters {
+		if _, ok := inv.Clusters[name]; !ok {
+			return nil, fmt.Errorf("cluster %q not found in VA inventory", name)
+		}
+	}
+
+	names := make([]string, 0, len(inv.Clusters))
+	for name := range inv.Clusters {
+		if len(clusters) == 0 || slices.Contains(clusters, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var hosts []Host
+	for _, name := range names {
+		cluster := inv.Clusters[name]
+		for _, appliance := range cluster.Appliances {
+			applianceTags := append(append([]string{}, cluster.Tags...), appliance.Tags...)
+			if !hasAllTags(applianceTags, tags) {
+				continue
+			}
+
+			auth := mergeAuth(cluster.InventoryAuth, appliance.InventoryAuth)
+			opts := SSHOptions{
+				User:     auth.User,
+				Port:     auth.Port,
+				KeyFile:  expandHomeDir(auth.IdentityFile),
+				UseAgent: auth.Agent,
+			}
+			if auth.PasswordEnv != "" {
+				opts.Password = os.Getenv(auth.PasswordEnv)
+			}
+
+			hosts = append(hosts, Host{
+				Address:   appliance.Address,
+				Cluster:   name,
+				ClusterID: cluster.ID,
+				Tags:      applianceTags,
+				Options:   opts,
+			})
+		}
+	}
+
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("no appliances in the VA inventory match the selection")
+	}
+
+	return hosts, nil
+}
+
+func mergeAuth(base InventoryAuth, override InventoryAuth) InventoryAuth {
+	merged := base
+	if override.User != "" {
+		merged.User = override.User
+	}
+	if override.Port != 0 {
+		merged.Port = override.Port
+	}
+	if override.IdentityFile != "" {
+		merged.IdentityFile = override.IdentityFile
+	}
+	if override.Agent {
+		merged.Agent = true
+	}
+	if override.PasswordEnv != "" {
+		merged.PasswordEnv = override.PasswordEnv
+	}
+	return merged
+}
+
+func hasAllTags(have []string, want []string) bool {
+	for _, tag := range want {
+		if !slices.Contains(have, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func expandHomeDir(p string) string {
+	if p == "" {
+		return p
+	}
+	userHome, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return expandHome(p, userHome)
+}
@@ -0,0 +1,67 @@
+package va
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+const testInventory = `
+clusters:
+  prod:
+    user: admin
+    identityFile: /keys/prod
+    tags: [production]
+    appliances:
+      - address: 10.0.0.1
+        tags: [east]
+      - address: 10.0.0.2
+        port: 2222
+        passwordEnv: TEST_VA_PASSWORD
+  dev:
+    appliances:
+      - address: 10.0.1.1
+        tags: [east]
+`
+
+func TestInventorySelect(t *testing.T) {
+	inventoryPath := path.Join(t.TempDir(), "inventory.yaml")
+	if err := os.WriteFile(inventoryPath, []byte(testInventory), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_VA_PASSWORD", "secret")
+
+	inventory, err := LoadInventory(inventoryPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hosts, err := inventory.Select([]string{"prod"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(hosts) != 2 {
+		t.Fatalf("expected 2 hosts, got %d", len(hosts))
+	}
+	if hosts[0].Options.User != "admin" || hosts[0].Options.KeyFile != "/keys/prod" {
+		t.Errorf("cluster settings not inherited: %+v", hosts[0].Options)
+	}
+	if hosts[1].Options.Port != 2222 || hosts[1].Options.Password != "secret" {
+		t.Errorf("appliance settings not applied: %+v", hosts[1].Options)
+	}
+
+	hosts, err = inventory.Select(nil, []string{"east"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(hosts) != 2 || hosts[0].Cluster != "dev" || hosts[1].Address != "10.0.0.1" {
+		t.Errorf("unexpected tag selection: %+v", hosts)
+	}
+
+	if _, err := inventory.Select([]string{"missing"}, nil); err == nil {
+		t.Error("expected an error for an unknown cluster")
+	}
+	if _, err := inventory.Select(nil, []string{"west"}); err == nil {
+		t.Error("expected an error when no appliance matches")
+	}
+}
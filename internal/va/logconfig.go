@@ -0,0 +1,70 @@
+package va
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	beta "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+	"github.com/sailpoint-oss/sailpoint-cli/internal/config"
+)
+
+const (
+	// MinLogDurationMinutes and MaxLogDurationMinutes bound the duration of a
+	// cluster log configuration
+	MinLogDurationMinutes = 5
+	MaxLogDurationMinutes = 1440
+	// DefaultRootLogLevel is the root level of a cluster without a log configuration
+	DefaultRootLogLevel = beta.STANDARDLEVEL_INFO
+)
+
+// ParseLogLevels parses class=LEVEL entries, skipping the entries with an
+// invalid level
+func ParseLogLevels(entries []string) (map[string]beta.StandardLevel, error) {
+	logLevels := make(map[string]beta.StandardLevel)
+
+	for _, entry := range entries {
+		class, level, ok := strings.Cut(entry, "=")
+		if !ok || class == "" {
+			return nil, fmt.Errorf("invalid logger %q, use class=LEVEL", entry)
+		}
+
+		conLevel := beta.StandardLevel(strings.ToUpper(level))
+		if conLevel.IsValid() {
+			logLevels[class] = conLevel
+		} else {
+			log.Warn("Log Level Invalid", "Connector", class, "LogLevel", level)
+		}
+	}
+
+	return logLevels, nil
+}
+
+// ProfileLogConfiguration builds the cluster log configuration of a saved
+// log profile. The duration is 0 when the profile does not set one.
+func ProfileLogConfiguration(name string) (beta.ClientLogConfiguration, error) {
+	var configuration beta.ClientLogConfiguration
+
+	profiles, err := config.GetClusterLogProfiles()
+	if err != nil {
+		return configuration, err
+	}
+	profile, ok := profiles[strings.ToLower(name)]
+	if !ok {
+		return configuration, fmt.Errorf("log profile %q not found, list the saved profiles with sail cluster log profile list", name)
+	}
+
+	logLevels, err := ParseLogLevels(profile.Loggers)
+	if err != nil {
+		return configuration, fmt.Errorf("log profile %s: %v", name, err)
+	}
+
+	configuration.RootLevel = beta.StandardLevel(strings.ToUpper(profile.RootLevel))
+	if configuration.RootLevel == "" {
+		configuration.RootLevel = DefaultRootLogLevel
+	}
+	configuration.DurationMinutes = profile.DurationMinutes
+	configuration.LogLevels = &logLevels
+
+	return configuration, nil
+}
@@ -0,0 +1,24 @@
+package va
+
+import (
+	"reflect"
+	"testing"
+
+	beta "github.com/sailpoint-oss/golang-sdk/v2/api_beta"
+)
+
+func TestParseLogLevels(t *testing.T) {
+	levels, err := ParseLogLevels([]string{"sailpoint.connector.ADLDAPConnector=trace", "sailpoint.Other=LOUD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]beta.StandardLevel{"sailpoint.connector.ADLDAPConnector": beta.STANDARDLEVEL_TRACE}
+	if !reflect.DeepEqual(levels, want) {
+		t.Errorf("expected %v, got %v", want, levels)
+	}
+
+	if _, err := ParseLogLevels([]string{"sailpoint.connector.ADLDAPConnector"}); err == nil {
+		t.Error("expected an error for a logger without a level")
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2025, SailPoint Technologies, Inc. All rights reserved.
+package va
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sshHostConfig holds the subset of ~/.ssh/config settings used to reach a VA
+type sshHostConfig struct {
+	HostName     string
+	User         string
+	Port         int
+	IdentityFile string
+}
+
+// lookupSSHConfig returns the settings of ~/.ssh/config that apply to alias.
+// A missing or unreadable config yields empty settings.
+func lookupSSHConfig(alias string) sshHostConfig {
+	userHome, err := os.UserHomeDir()
+	if err != nil {
+		return sshHostConfig{}
+	}
+
+	f, err := os.Open(path.Join(userHome, ".ssh", "config"))
+	if err != nil {
+		return sshHostConfig{}
+	}
+	defer f.Close()
+
+	return parseSSHConfig(f, alias, userHome)
+}
+
+// parseSSHConfig applies the first value of each supported keyword from the
+// Host blocks matching alias, the same precedence ssh(1) uses.
+func parseSSHConfig(r io.Reader, alias string, userHome string) sshHostConfig {
+	var cfg sshHostConfig
+	matching := true
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		keyword, value := splitSSHConfigLine(line)
+		if keyword == "" {
+			continue
+		}
+
+		switch keyword {
+		case "host":
+			matching = matchSSHHost(strings.Fields(value), alias)
+		case "match":
+			// Match blocks are not supported, ignore their settings
+			matching = false
+		case "hostname":
+			if matching && cfg.HostName == "" {
+				cfg.HostName = strings.ReplaceAll(value, "%h", alias)
+			}
+		case "user":
+			if matching && cfg.User == "" {
+				cfg.User = value
+			}
+		case "port":
+			if matching && cfg.Port == 0 {
+				if port, err := strconv.Atoi(value); err == nil {
+					cfg.Port = port
+				}
+			}
+		case "identityfile":
+			if matching && cfg.IdentityFile == "" {
+				cfg.IdentityFile = expandHome(value, userHome)
+			}
+		}
+	}
+
+	return cfg
+}
+
+func splitSSHConfigLine(line string) (string, string) {
+	idx := strings.IndexAny(line, " \t=")
+	if idx < 0 {
+		return strings.ToLower(line), ""
+	}
+
+	keyword := strings.ToLower(line[:idx])
+	value := strings.TrimSpace(strings.TrimLeft(line[idx:], " \t="))
+	value = strings.Trim(value, "\"")
+
+	return keyword, value
+}
+
+// matchSSHHost reports whether alias matches the Host patterns. Negated
+// patterns take precedence over positive ones.
+func matchSSHHost(patterns []string, alias string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		negate := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+
+		ok, err := filepath.Match(pattern, alias)
+		if err != nil || !ok {
+			continue
+		}
+		if negate {
+			return false
+		}
+		matched = true
+	}
+	return matched
+}
+
+func expandHome(p string, userHome string) string {
+	if p == "~" {
+		return userHome
+	}
+	if strings.HasPrefix(p, "~/") {
+		return path.Join(userHome, p[2:])
+	}
+	return p
+}
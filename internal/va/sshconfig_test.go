@@ -0,0 +1,49 @@
+// Copyright (c) 2025, SailPoint Technologies, Inc. All rights reserved.
+package va
+
+import (
+	"strings"
+	"testing"
+)
+
+const testSSHConfig = `
+# VA fleet
+Host va-prod-* !va-prod-9
+	HostName %h.example.com
+	User sailpoint
+	IdentityFile ~/.ssh/va_ed25519
+
+Host va-prod-1
+	Port 2222
+	User admin
+
+Host *
+	Port 22
+`
+
+func TestParseSSHConfig(t *testing.T) {
+	tests := []struct {
+		alias    string
+		expected sshHostConfig
+	}{
+		{
+			alias:    "va-prod-1",
+			expected: sshHostConfig{HostName: "va-prod-1.example.com", User: "sailpoint", Port: 2222, IdentityFile: "/home/test/.ssh/va_ed25519"},
+		},
+		{
+			alias:    "va-prod-9",
+			expected: sshHostConfig{Port: 22},
+		},
+		{
+			alias:    "10.10.10.25",
+			expected: sshHostConfig{Port: 22},
+		},
+	}
+
+	for _, tt := range tests {
+		actual := parseSSHConfig(strings.NewReader(testSSHConfig), tt.alias, "/home/test")
+		if actual != tt.expected {
+			t.Errorf("%s: expected %+v, actual %+v", tt.alias, tt.expected, actual)
+		}
+	}
+}
@@ -4,21 +4,20 @@ package va
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
-	"io"
 	"net"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"sync"
 
-	"github.com/charmbracelet/log"
-	"github.com/pkg/sftp"
-	"github.com/vbauerster/mpb/v8"
-	"github.com/vbauerster/mpb/v8/decor"
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
 	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/term"
 )
 
 const sshDirMode = 0700
@@ -37,6 +36,58 @@ func ensureKnownHostsFile(knownHostsPath string) error {
 	return nil
 }
 
+// knownHostsLock serializes first-time host prompts and known_hosts updates,
+// as fleet runs connect to several VAs concurrently.
+var knownHostsLock sync.Mutex
+
+// declinedHostKeys remembers keys the user refused, so a host is asked about
+// once per run.
+var declinedHostKeys = map[string]bool{}
+
+// confirmHostKey asks whether to trust the key of an unknown host and appends
+// it to known_hosts when accepted. unknownErr is returned when it is not.
+func confirmHostKey(knownHostsPath string, hostname string, remote net.Addr, key ssh.PublicKey, unknownErr error) error {
+	knownHostsLock.Lock()
+	defer knownHostsLock.Unlock()
+
+	// Another connection may have added the host while this one waited
+	if current, err := knownhosts.New(knownHostsPath); err == nil {
+		err = current(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if err == nil || !errors.As(err, &keyErr) || len(keyErr.Want) > 0 {
+			return err
+		}
+	}
+
+	addrStr := hostname
+	if remote != nil {
+		addrStr = remote.String()
+	}
+	fingerprint := ssh.FingerprintSHA256(key)
+	if declinedHostKeys[addrStr+" "+fingerprint] {
+		return unknownErr
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("host %s is not in %s and its %s key %s cannot be confirmed without a terminal", addrStr, knownHostsPath, key.Type(), fingerprint)
+	}
+
+	fmt.Fprintf(os.Stderr, "The authenticity of host %q can't be established.\n%s key fingerprint is %s.\nAre you sure you want to continue connecting (yes/no)? ", addrStr, key.Type(), fingerprint)
+	if !promptYesNo() {
+		declinedHostKeys[addrStr+" "+fingerprint] = true
+		return unknownErr
+	}
+
+	line := knownhosts.Line([]string{knownhosts.Normalize(addrStr)}, key) + "\n"
+	f, err := os.OpenFile(knownHostsPath, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("could not append to known_hosts: %w", err)
+	}
+	_, _ = f.WriteString(line)
+	_ = f.Close()
+	return nil
+}
+
 // promptYesNo reads a line from stdin and returns true for yes, false for no or invalid input.
 func promptYesNo() bool {
 	scanner := bufio.NewScanner(os.Stdin)
@@ -47,21 +98,123 @@ func promptYesNo() bool {
 	return line == "yes" || line == "y"
 }
 
+// SSHOptions describes how to reach and authenticate against a VA. Settings
+// left empty are taken from the matching ~/.ssh/config Host entry, then from
+// the VA defaults (user sailpoint, port 22).
+type SSHOptions struct {
+	User     string
+	Port     int
+	Password string
+	// KeyFile is a private key used for public key authentication
+	KeyFile string
+	// UseAgent authenticates with the keys held by the agent at SSH_AUTH_SOCK
+	UseAgent bool
+}
+
+// sshTarget is a VA address with every connection setting resolved
+type sshTarget struct {
+	Host string
+	SSHOptions
+}
+
+func (o SSHOptions) resolve(addr string) sshTarget {
+	target := sshTarget{Host: addr, SSHOptions: o}
+
+	hostCfg := lookupSSHConfig(addr)
+	if hostCfg.HostName != "" {
+		target.Host = hostCfg.HostName
+	}
+	if target.User == "" {
+		target.User = hostCfg.User
+	}
+	if target.Port == 0 {
+		target.Port = hostCfg.Port
+	}
+	if target.KeyFile == "" {
+		target.KeyFile = hostCfg.IdentityFile
+	}
+
+	if target.User == "" {
+		target.User = "sailpoint"
+	}
+	if target.Port == 0 {
+		target.Port = 22
+	}
+
+	return target
+}
+
+// NeedsPassword reports whether connecting to addr requires a password, that
+// is when no key file or agent is configured for it.
+func (o SSHOptions) NeedsPassword(addr string) bool {
+	target := o.resolve(addr)
+	return target.Password == "" && target.KeyFile == "" && !target.UseAgent
+}
+
+// authMethods returns the configured authentication methods along with a
+// function that releases the SSH agent connection, to be called once the
+// handshake is done.
+func (t sshTarget) authMethods() ([]ssh.AuthMethod, func(), error) {
+	var methods []ssh.AuthMethod
+	closer := func() {}
+
+	if t.KeyFile != "" {
+		raw, err := os.ReadFile(t.KeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not read SSH key %s: %w", t.KeyFile, err)
+		}
+		signer, err := ssh.ParsePrivateKey(raw)
+		if err != nil {
+			var passErr *ssh.PassphraseMissingError
+			if errors.As(err, &passErr) {
+				return nil, nil, fmt.Errorf("SSH key %s is protected by a passphrase, load it into ssh-agent and use the agent instead", t.KeyFile)
+			}
+			return nil, nil, fmt.Errorf("could not parse SSH key %s: %w", t.KeyFile, err)
+		}
+		methods = append(methods, ssh.PublicKeys(signer))
+	}
+
+	if t.UseAgent {
+		socket := os.Getenv("SSH_AUTH_SOCK")
+		if socket == "" {
+			return nil, nil, fmt.Errorf("SSH agent requested but SSH_AUTH_SOCK is not set")
+		}
+		conn, err := net.Dial("unix", socket)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not connect to SSH agent: %w", err)
+		}
+		closer = func() { conn.Close() }
+		methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+	}
+
+	if t.Password != "" {
+		methods = append(methods, ssh.Password(t.Password))
+	}
+
+	if len(methods) == 0 {
+		return nil, nil, fmt.Errorf("no SSH authentication configured for %s, provide a password, key file or agent", t.Host)
+	}
+
+	return methods, closer, nil
+}
+
 // newSSHClientConfig returns an ssh.ClientConfig with host key verification via ~/.ssh/known_hosts
-// and interactive first-time host acceptance.
-func newSSHClientConfig(password string) (*ssh.ClientConfig, error) {
+// and interactive first-time host acceptance. The returned function releases
+// resources held by the authentication methods and must be called once the
+// connection is established.
+func newSSHClientConfig(target sshTarget) (*ssh.ClientConfig, func(), error) {
 	userHome, err := os.UserHomeDir()
 	if err != nil {
-		return nil, fmt.Errorf("could not determine user home directory: %w", err)
+		return nil, nil, fmt.Errorf("could not determine user home directory: %w", err)
 	}
 	knownHostsPath := path.Join(userHome, ".ssh", "known_hosts")
 	if err := ensureKnownHostsFile(knownHostsPath); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	baseCallback, err := knownhosts.New(knownHostsPath)
 	if err != nil {
-		return nil, fmt.Errorf("could not load SSH known_hosts from %s: %w", knownHostsPath, err)
+		return nil, nil, fmt.Errorf("could not load SSH known_hosts from %s: %w", knownHostsPath, err)
 	}
 
 	callback := func(hostname string, remote net.Addr, key ssh.PublicKey) error {
@@ -75,45 +228,44 @@ func newSSHClientConfig(password string) (*ssh.ClientConfig, error) {
 			if len(keyErr.Want) > 0 {
 				return err
 			}
-			// Unknown host (first-time connection): prompt and optionally add to known_hosts
-			fingerprint := ssh.FingerprintSHA256(key)
-			addrStr := hostname
-			if remote != nil {
-				addrStr = remote.String()
-			}
-			fmt.Fprintf(os.Stderr, "The authenticity of host %q can't be established.\n%s key fingerprint is %s.\nAre you sure you want to continue connecting (yes/no)? ", addrStr, key.Type(), fingerprint)
-			if !promptYesNo() {
-				return err
-			}
-			line := knownhosts.Line([]string{knownhosts.Normalize(addrStr)}, key) + "\n"
-			f, appendErr := os.OpenFile(knownHostsPath, os.O_WRONLY|os.O_APPEND, 0600)
-			if appendErr != nil {
-				return fmt.Errorf("could not append to known_hosts: %w", appendErr)
-			}
-			_, _ = f.WriteString(line)
-			_ = f.Close()
-			return nil
+			return confirmHostKey(knownHostsPath, hostname, remote, key, err)
 		}
 		return err
 	}
 
+	auth, closeAuth, err := target.authMethods()
+	if err != nil {
+		return nil, nil, err
+	}
+
 	return &ssh.ClientConfig{
-		User:            "sailpoint",
+		User:            target.User,
 		HostKeyCallback: callback,
-		Auth:            []ssh.AuthMethod{ssh.Password(password)},
-	}, nil
+		Auth:            auth,
+	}, closeAuth, nil
 }
 
-func RunVACmd(addr string, password string, cmd string) (string, error) {
-	config, err := newSSHClientConfig(password)
+// Dial opens an SSH connection to the VA at addr
+func Dial(addr string, opts SSHOptions) (*ssh.Client, error) {
+	target := opts.resolve(addr)
+
+	config, closeAuth, err := newSSHClientConfig(target)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	// The agent is only used during the handshake
+	defer closeAuth()
+
+	return ssh.Dial("tcp", net.JoinHostPort(target.Host, strconv.Itoa(target.Port)), config)
+}
+
+func RunVACmd(addr string, opts SSHOptions, cmd string) (string, error) {
 	// Connect
-	client, dialErr := ssh.Dial("tcp", net.JoinHostPort(addr, "22"), config)
+	client, dialErr := Dial(addr, opts)
 	if dialErr != nil {
 		return "", dialErr
 	}
+	defer client.Close()
 
 	// Create a session. It is one session per command.
 	session, sessionErr := client.NewSession()
@@ -137,128 +289,96 @@ func RunVACmd(addr string, password string, cmd string) (string, error) {
 	return b.String(), nil
 }
 
-func RunVACmdLive(addr string, password string, cmd string) error {
-	config, err := newSSHClientConfig(password)
+// RunClientCmd runs cmd over an established connection and returns its
+// combined stdout and stderr, so several commands can share one connection
+func RunClientCmd(client *ssh.Client, cmd string) (string, error) {
+	session, err := client.NewSession()
 	if err != nil {
-		return err
-	}
-	// Connect
-	client, dialErr := ssh.Dial("tcp", net.JoinHostPort(addr, "22"), config)
-	if dialErr != nil {
-		return dialErr
-	}
-
-	// Create a session. It is one session per command.
-	session, sessionErr := client.NewSession()
-	if sessionErr != nil {
-		return sessionErr
+		return "", err
 	}
 	defer session.Close()
 
-	// get output
-	session.Stdout = os.Stdout
-
-	// Finally, run the command
-	runErr := session.Run(cmd)
-	if runErr != nil {
-		return runErr
-	}
-
-	// Return the output
-	return nil
+	out, err := session.CombinedOutput(cmd)
+	return string(out), err
 }
 
-func CollectVAFiles(endpoint string, password string, output string, files []string, p *mpb.Progress) error {
-	log.Info("Starting File Collection", "VA", endpoint)
-
-	config, err := newSSHClientConfig(password)
+// StreamClientCmd runs cmd over an established connection and calls fn with
+// each line of its stdout, until the command exits or ctx is done. A command
+// stopped through ctx is not an error.
+func StreamClientCmd(ctx context.Context, client *ssh.Client, cmd string, fn func(line []byte) error) error {
+	session, err := client.NewSession()
 	if err != nil {
 		return err
 	}
+	defer session.Close()
 
-	outputFolder := path.Join(output, endpoint)
-
-	// Connect
-	client, err := ssh.Dial("tcp", net.JoinHostPort(endpoint, "22"), config)
+	stdout, err := session.StdoutPipe()
 	if err != nil {
-		return fmt.Errorf("failed to dial: %v", err)
+		return err
 	}
-	defer client.Close()
-
-	sftp, err := sftp.NewClient(client)
-	if err != nil {
-		return fmt.Errorf("failed to create SFTP client: %v", err)
+	if err := session.Start(cmd); err != nil {
+		return err
 	}
-	defer sftp.Close()
 
-	var wg sync.WaitGroup
-	for _, filePath := range files {
-		wg.Add(1)
-		go func(filePath string) {
-			defer wg.Done()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			// Closing the session ends the stream, the remote command gets
+			// SIGPIPE on its next write
+			session.Close()
+		case <-done:
+		}
+	}()
 
-			err := collectFile(sftp, filePath, outputFolder, endpoint, p)
-			if err != nil {
-				log.Warn("Skipping file", "file", filePath, "VA", endpoint)
-				log.Debug("Error collecting file", "file", filePath, "VA", endpoint, "err", err)
-			}
-		}(filePath)
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if err := fn(scanner.Bytes()); err != nil {
+			return err
+		}
 	}
 
-	wg.Wait()
-
-	return nil
+	err = session.Wait()
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err == nil {
+		err = scanner.Err()
+	}
+	return err
 }
 
-func collectFile(sftp *sftp.Client, filePath, outputFolder, endpoint string, p *mpb.Progress) error {
-	_, base := path.Split(filePath)
-
-	outputFile := path.Join(outputFolder, base)
+// ShellQuote quotes s for use as a single POSIX shell word
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
 
-	if _, err := os.Stat(outputFolder); errors.Is(err, os.ErrNotExist) {
-		err := os.MkdirAll(outputFolder, 0700)
-		if err != nil {
-			return fmt.Errorf("failed to create output folder: %v", err)
-		}
-	}
-	remoteFileStats, statErr := sftp.Stat(filePath)
-	if statErr != nil {
-		return fmt.Errorf("failed to stat remote file: %v", statErr)
-	}
-
-	name := fmt.Sprintf("%v - %v", endpoint, base)
-	bar := p.AddBar(remoteFileStats.Size(),
-		mpb.BarFillerClearOnComplete(),
-		mpb.PrependDecorators(
-			decor.Name(name, decor.WCSyncWidthR),
-			decor.Name(" : ", decor.WCSyncWidthR),
-			// decor.OnComplete(decor.CountersKiloByte("% .2f / % .2f", decor.WCSyncSpaceR), "Complete"),
-			decor.TotalKiloByte("% .2f", decor.WCSyncSpaceR),
-		),
-		mpb.AppendDecorators(
-			decor.OnComplete(decor.CountersKibiByte("% .2f / % .2f", decor.WCSyncWidth), "Complete")),
-	)
-
-	srcFile, err := sftp.Open(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to open remote file: %v", err)
+func RunVACmdLive(addr string, opts SSHOptions, cmd string) error {
+	// Connect
+	client, dialErr := Dial(addr, opts)
+	if dialErr != nil {
+		return dialErr
 	}
-	defer srcFile.Close()
+	defer client.Close()
 
-	dstFile, err := os.Create(outputFile)
-	if err != nil {
-		return fmt.Errorf("failed to create local file: %v", err)
+	// Create a session. It is one session per command.
+	session, sessionErr := client.NewSession()
+	if sessionErr != nil {
+		return sessionErr
 	}
-	defer dstFile.Close()
+	defer session.Close()
 
-	writer := io.Writer(dstFile)
-	proxyWriter := bar.ProxyWriter(writer)
-	defer proxyWriter.Close()
+	// get output
+	session.Stdout = os.Stdout
 
-	_, err = io.Copy(proxyWriter, srcFile)
-	if err != nil {
-		return fmt.Errorf("failed to copy file contents: %v", err)
+	// Finally, run the command
+	runErr := session.Run(cmd)
+	if runErr != nil {
+		return runErr
 	}
 
+	// Return the output
 	return nil
 }
@@ -0,0 +1,109 @@
+package va
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestAuthMethodsClosesAgent(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	defer listener.Close()
+	t.Setenv("SSH_AUTH_SOCK", socket)
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+
+	target := sshTarget{Host: "va", SSHOptions: SSHOptions{UseAgent: true}}
+	methods, closer, err := target.authMethods()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(methods) != 1 {
+		t.Fatalf("expected one auth method, got %d", len(methods))
+	}
+
+	var conn net.Conn
+	select {
+	case conn = <-accepted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("agent connection was not opened")
+	}
+	defer conn.Close()
+
+	closer()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("expected agent connection to be closed, got %v", err)
+	}
+}
+
+func TestAuthMethodsWithoutAgent(t *testing.T) {
+	target := sshTarget{Host: "va", SSHOptions: SSHOptions{Password: "secret"}}
+	methods, closer, err := target.authMethods()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(methods) != 1 {
+		t.Fatalf("expected one auth method, got %d", len(methods))
+	}
+	// Nothing to release, but callers always call the closer
+	closer()
+}
+
+func TestHostKeyCallback(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	config, closer, err := newSSHClientConfig(sshTarget{Host: "va", SSHOptions: SSHOptions{Password: "secret"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closer()
+
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := &net.TCPAddr{IP: net.ParseIP("10.0.0.5"), Port: 22}
+
+	// Tests do not run on a terminal, so unknown hosts fail instead of prompting
+	err = config.HostKeyCallback("10.0.0.5:22", remote, key)
+	if err == nil || !strings.Contains(err.Error(), "without a terminal") {
+		t.Fatalf("expected unknown host to fail without a terminal, got %v", err)
+	}
+
+	// A host added by another connection after the config was loaded is
+	// accepted without asking again
+	knownHostsPath := filepath.Join(home, ".ssh", "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(remote.String())}, key) + "\n"
+	if err := os.WriteFile(knownHostsPath, []byte(line), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.HostKeyCallback("10.0.0.5:22", remote, key); err != nil {
+		t.Errorf("expected host added to known_hosts to be accepted, got %v", err)
+	}
+}